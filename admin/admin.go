@@ -0,0 +1,169 @@
+/*
+Package admin exposes registered models as generic JSON API for internal admin backends:
+
+	registry := crud.NewRegistry(db, crud.Config{})
+	crud.Register[User](registry)
+	a := admin.New(func(r *http.Request, model string, action admin.Action) error {
+		if r.Header.Get("X-Role") != "admin" {
+			return errors.New("admins only")
+		}
+		return nil
+	})
+	_ = admin.Register[User](a, registry)
+	http.Handle("/admin/", http.StripPrefix("/admin", a))
+
+Routes:
+
+	GET    /                  schemas of models, keyed by table name
+	GET    /{table}           list with crud.GenericCRUD.ParseQuery filtering; sets X-Total-Count
+	GET    /{table}/{id}      get
+	PUT    /{table}/{id}      update non-zero fields; PATCH is the same
+	DELETE /{table}/{id}      delete
+*/
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nullc4t/gorm-cruder/crud"
+	"github.com/nullc4t/gorm-cruder/crudhttp"
+)
+
+// errNoAuthorizer denies requests of Admin without Authorizer
+var errNoAuthorizer = errors.New("admin: no authorizer")
+
+// Action of admin request
+type Action string
+
+const (
+	ActionList   Action = "list"
+	ActionGet    Action = "get"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+type (
+	// Authorizer permits action on model (table name) for request; non-nil error responds with 403 Forbidden
+	Authorizer func(r *http.Request, model string, action Action) error
+
+	// Admin routes requests to models by table name
+	Admin struct {
+		authorize Authorizer
+		mu        sync.RWMutex
+		models    map[string]model
+	}
+
+	model struct {
+		schema  crud.ModelSchema
+		handler http.Handler
+	}
+)
+
+// New is a constructor; nil authorize denies every request but schemas listing, which is then empty
+func New(authorize Authorizer) *Admin {
+	return &Admin{authorize: authorize, models: make(map[string]model)}
+}
+
+// Register exposes T registered in registry; allowed columns are used for filtering and sorting
+func Register[T crud.GORMModel](a *Admin, registry *crud.Registry, allowed ...string) error {
+	c, ok := crud.Get[T](registry)
+	if !ok {
+		return fmt.Errorf("admin: %T is not registered", *new(T))
+	}
+	return Add(a, c, allowed...)
+}
+
+// Add exposes c; allowed columns are used for filtering and sorting. Adding table again replaces it
+func Add[T crud.GORMModel](a *Admin, c crud.GenericCRUD[T], allowed ...string) error {
+	s, err := c.Schema()
+	if err != nil {
+		return err
+	}
+	h := crudhttp.New(c, allowed...)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.models[s.Table] = model{schema: s, handler: http.StripPrefix("/"+s.Table, h)}
+	return nil
+}
+
+// ServeHTTP routes request to model by first path segment
+func (a *Admin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	table, id, _ := strings.Cut(strings.Trim(r.URL.Path, "/"), "/")
+	if table == "" {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		crudhttp.JSON(w, http.StatusOK, a.schemas(r))
+		return
+	}
+	a.mu.RLock()
+	m, ok := a.models[table]
+	a.mu.RUnlock()
+	if !ok {
+		crudhttp.JSON(w, http.StatusNotFound, map[string]string{"error": "unknown model " + table})
+		return
+	}
+	action, ok := actionOf(r.Method, id != "")
+	if !ok {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := a.authorized(r, table, action); err != nil {
+		crudhttp.JSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		return
+	}
+	m.handler.ServeHTTP(w, r)
+}
+
+// schemas returns schemas of models listing is authorized for
+func (a *Admin) schemas(r *http.Request) map[string]crud.ModelSchema {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	res := make(map[string]crud.ModelSchema, len(a.models))
+	for table, m := range a.models {
+		if a.authorized(r, table, ActionList) == nil {
+			res[table] = m.schema
+		}
+	}
+	return res
+}
+
+// Models returns table names of exposed models, sorted
+func (a *Admin) Models() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	res := make([]string, 0, len(a.models))
+	for table := range a.models {
+		res = append(res, table)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func (a *Admin) authorized(r *http.Request, table string, action Action) error {
+	if a.authorize == nil {
+		return errNoAuthorizer
+	}
+	return a.authorize(r, table, action)
+}
+
+// actionOf maps method to Action; creating is not exposed
+func actionOf(method string, hasID bool) (Action, bool) {
+	switch {
+	case !hasID && method == http.MethodGet:
+		return ActionList, true
+	case hasID && method == http.MethodGet:
+		return ActionGet, true
+	case hasID && (method == http.MethodPut || method == http.MethodPatch):
+		return ActionUpdate, true
+	case hasID && method == http.MethodDelete:
+		return ActionDelete, true
+	}
+	return "", false
+}
@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/nullc4t/gorm-cruder/crud"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type user struct {
+	crud.Model
+	Name string
+}
+
+func (u user) PrimaryKey() any {
+	return u.ID
+}
+
+func TestAdmin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:admin?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&user{}))
+	registry := crud.NewRegistry(db, crud.Config{})
+	users := crud.Register[user](registry)
+	_, err = users.Create(context.TODO(), user{Name: "alice"})
+	require.NoError(t, err)
+
+	a := New(func(r *http.Request, model string, action Action) error {
+		if action == ActionDelete {
+			return errors.New("read only")
+		}
+		return nil
+	})
+	require.NoError(t, Register[user](a, registry))
+	require.Error(t, Register[struct{ user }](a, registry))
+	require.Equal(t, []string{"users"}, a.Models())
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+	w := serve(http.MethodGet, "/", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var schemas map[string]crud.ModelSchema
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &schemas))
+	require.Equal(t, []string{"id"}, schemas["users"].PrimaryKey)
+
+	w = serve(http.MethodGet, "/users?name=alice", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "1", w.Header().Get("X-Total-Count"))
+
+	w = serve(http.MethodPut, "/users/1", `{"Name":"bob"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"Name":"bob"`)
+
+	require.Equal(t, http.StatusForbidden, serve(http.MethodDelete, "/users/1", "").Code)
+	require.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPost, "/users", "{}").Code)
+	require.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/pets", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/users/2", "").Code)
+
+	a = New(nil)
+	require.NoError(t, Register[user](a, registry))
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		require.Equal(t, http.StatusForbidden, serve(method, "/users/1", `{"Name":"eve"}`).Code, method)
+	}
+	w = serve(http.MethodGet, "/", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{}`, w.Body.String())
+}
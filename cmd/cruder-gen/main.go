@@ -0,0 +1,302 @@
+/*
+Cruder-gen generates typed repositories on top of crud.GenericCRUD.
+
+For every model struct it emits <Model>Repo embedding crud.GenericCRUD[Model]
+with finders for tagged fields:
+
+	crud:"unique"  GetBy<Field>(ctx, value) (*Model, error)
+	crud:"index"   ListBy<Field>(ctx, value) ([]*Model, error)
+
+and <Model>Columns with column name of every field, e.g. UserColumns.Name, so call sites of
+Query maps and UpdateField don't repeat raw strings; <Model>Repo.CheckColumns validates them
+against schema, e.g. in a test.
+
+Usage:
+
+	//go:generate cruder-gen -type User,Order -output repo_gen.go
+
+Without -type all structs having crud tags are processed.
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gorm.io/gorm/schema"
+)
+
+type (
+	model struct {
+		Name    string
+		Finders []finder
+		Columns []columnName
+	}
+
+	columnName struct {
+		Field, Column string
+	}
+
+	finder struct {
+		Field, Column, Type string
+		Unique              bool
+	}
+)
+
+func main() {
+	var (
+		typeNames = flag.String("type", "", "comma-separated list of model names; all tagged structs if empty")
+		output    = flag.String("output", "repo_gen.go", "output file name")
+	)
+	flag.Parse()
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	var names []string
+	if *typeNames != "" {
+		names = strings.Split(*typeNames, ",")
+	}
+	pkg, models, err := parseDir(dir, names)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(pkg, models)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = os.WriteFile(*output, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseDir parses package in dir and returns its name and models
+func parseDir(dir string, names []string) (string, []model, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(pkgs) != 1 {
+		return "", nil, fmt.Errorf("expected one package in %s, got %d", dir, len(pkgs))
+	}
+	for name, pkg := range pkgs {
+		var files []*ast.File
+		for _, f := range pkg.Files {
+			files = append(files, f)
+		}
+		models, err := parseFiles(files, names)
+		return name, models, err
+	}
+	return "", nil, nil
+}
+
+// parseFiles collects models from files; names selects models, tagged structs if empty
+func parseFiles(files []*ast.File, names []string) ([]model, error) {
+	wanted := make(map[string]bool, len(names))
+	for _, n := range names {
+		wanted[n] = true
+	}
+	structs := make(map[string]*ast.StructType)
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if spec, ok := n.(*ast.TypeSpec); ok {
+				if st, ok := spec.Type.(*ast.StructType); ok {
+					structs[spec.Name.Name] = st
+				}
+			}
+			return true
+		})
+	}
+	var res []model
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok || len(wanted) > 0 && !wanted[spec.Name.Name] {
+				return false
+			}
+			m := model{Name: spec.Name.Name}
+			for _, field := range st.Fields.List {
+				if field.Tag == nil || len(field.Names) == 0 {
+					continue
+				}
+				tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+				opts := strings.Split(tag.Get("crud"), ",")
+				for _, name := range field.Names {
+					for _, o := range opts {
+						if o != "unique" && o != "index" {
+							continue
+						}
+						m.Finders = append(m.Finders, finder{
+							Field:  name.Name,
+							Column: column(name.Name, tag.Get("gorm")),
+							Type:   types.ExprString(field.Type),
+							Unique: o == "unique",
+						})
+					}
+				}
+			}
+			if len(m.Finders) > 0 || wanted[m.Name] {
+				m.Columns = columns(st, structs)
+				res = append(res, m)
+				delete(wanted, m.Name)
+			}
+			return false
+		})
+	}
+	for n := range wanted {
+		return nil, fmt.Errorf("model %s not found", n)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// baseModels are embeddable models of gorm and crud
+var baseModels = map[string]bool{"gorm.Model": true, "crud.Model": true}
+
+// columns returns column names of fields of st, including embedded structs of structs; ignored fields
+// (gorm:"-") and associations (slices and structs of package, by type name) are skipped
+func columns(st *ast.StructType, structs map[string]*ast.StructType) []columnName {
+	var res []columnName
+	for _, field := range st.Fields.List {
+		typ := types.ExprString(field.Type)
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+		}
+		gormTag := tag.Get("gorm")
+		if gormTag == "-" || strings.HasPrefix(gormTag, "-;") {
+			continue
+		}
+		prefix, _ := tagValue(gormTag, "embeddedPrefix")
+		if len(field.Names) == 0 {
+			if baseModels[strings.TrimPrefix(typ, "*")] {
+				for _, name := range []string{"ID", "CreatedAt", "UpdatedAt", "DeletedAt"} {
+					res = append(res, columnName{Field: name, Column: prefix + column(name, "")})
+				}
+			} else if embedded, ok := structs[strings.TrimPrefix(typ, "*")]; ok {
+				for _, c := range columns(embedded, structs) {
+					res = append(res, columnName{Field: c.Field, Column: prefix + c.Column})
+				}
+			}
+			continue
+		}
+		// named embedded struct: its columns are prefixed, fields are qualified by name, e.g. AddressCity
+		if _, ok := tagValue(gormTag, "embedded"); ok {
+			if embedded, ok := structs[strings.TrimPrefix(typ, "*")]; ok {
+				for _, name := range field.Names {
+					if !name.IsExported() {
+						continue
+					}
+					for _, c := range columns(embedded, structs) {
+						res = append(res, columnName{Field: name.Name + c.Field, Column: prefix + c.Column})
+					}
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(typ, "[]") && typ != "[]byte" || structs[strings.TrimPrefix(typ, "*")] != nil {
+			continue
+		}
+		for _, name := range field.Names {
+			if name.IsExported() {
+				res = append(res, columnName{Field: name.Name, Column: column(name.Name, gormTag)})
+			}
+		}
+	}
+	return res
+}
+
+// column returns column name respecting gorm:"column:..." tag
+func column(field, gormTag string) string {
+	if v, ok := tagValue(gormTag, "column"); ok && v != "" {
+		return v
+	}
+	return schema.NamingStrategy{}.ColumnName("", field)
+}
+
+// tagValue returns value of gorm tag option key, matched case-insensitively; ok is false if option is absent
+func tagValue(gormTag, key string) (string, bool) {
+	for _, s := range strings.Split(gormTag, ";") {
+		k, v, _ := strings.Cut(s, ":")
+		if strings.EqualFold(strings.TrimSpace(k), key) {
+			return strings.TrimSpace(v), true
+		}
+	}
+	return "", false
+}
+
+var tmpl = template.Must(template.New("repo").Parse(`// Code generated by cruder-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+
+	"github.com/nullc4t/gorm-cruder/crud"
+	"gorm.io/gorm"
+)
+{{range .Models}}{{$m := .Name}}
+// {{$m}}Repo is typed repository for {{$m}}
+type {{$m}}Repo struct {
+	crud.GenericCRUD[{{$m}}]
+}
+
+// New{{$m}}Repo is a constructor
+func New{{$m}}Repo(db *gorm.DB, omit ...string) {{$m}}Repo {
+	return {{$m}}Repo{GenericCRUD: crud.New[{{$m}}](db, omit...)}
+}
+
+// {{$m}}Columns are column names of {{$m}} fields
+var {{$m}}Columns = struct {
+{{range .Columns}}	{{.Field}} string
+{{end}}}{
+{{range .Columns}}	{{.Field}}: "{{.Column}}",
+{{end}}}
+
+// CheckColumns returns error if any of {{$m}}Columns is not a column of {{$m}}, e.g. after renaming field
+func (r {{$m}}Repo) CheckColumns() error {
+	return r.GenericCRUD.CheckColumns({{$m}}Columns)
+}
+{{range .Finders}}{{if .Unique}}
+// GetBy{{.Field}} returns {{$m}} with {{.Column}} equal to v
+func (r {{$m}}Repo) GetBy{{.Field}}(ctx context.Context, v {{.Type}}) (*{{$m}}, error) {
+	return r.SmartQueryOne(ctx, crud.Query{Equal: map[string]any{"{{.Column}}": v}})
+}
+{{else}}
+// ListBy{{.Field}} returns {{$m}} rows with {{.Column}} equal to v
+func (r {{$m}}Repo) ListBy{{.Field}}(ctx context.Context, v {{.Type}}) ([]*{{$m}}, error) {
+	return r.SmartQuery(ctx, crud.Query{Equal: map[string]any{"{{.Column}}": v}})
+}
+{{end}}{{end}}{{end}}`))
+
+// generate renders repositories source
+func generate(pkg string, models []model) ([]byte, error) {
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, struct {
+		Package string
+		Models  []model
+	}{pkg, models})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const src = `package models
+
+type User struct {
+	crud.Model
+	Email  string ` + "`crud:\"unique\"`" + `
+	Status string ` + "`crud:\"index\" gorm:\"column:state\"`" + `
+	Name   string
+	Home   Address ` + "`gorm:\"embedded;embeddedPrefix:home_\"`" + `
+	Tmp    string ` + "`gorm:\"-\"`" + `
+	Pets   []Pet
+	Plain  *Plain
+	secret string
+}
+
+type Plain struct {
+	Name string
+}
+
+type Address struct {
+	City string
+	Zip  string ` + "`gorm:\"column:postcode\"`" + `
+}
+`
+
+func TestGenerate(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "models.go", src, 0)
+	require.NoError(t, err)
+
+	models, err := parseFiles([]*ast.File{f}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []model{{
+		Name: "User",
+		Finders: []finder{
+			{Field: "Email", Column: "email", Type: "string", Unique: true},
+			{Field: "Status", Column: "state", Type: "string"},
+		},
+		Columns: []columnName{
+			{"ID", "id"}, {"CreatedAt", "created_at"}, {"UpdatedAt", "updated_at"}, {"DeletedAt", "deleted_at"},
+			{"Email", "email"}, {"Status", "state"}, {"Name", "name"}, {"HomeCity", "home_city"}, {"HomeZip", "home_postcode"},
+		},
+	}}, models)
+
+	out, err := generate("models", models)
+	require.NoError(t, err)
+	require.Contains(t, string(out), "func (r UserRepo) GetByEmail(ctx context.Context, v string) (*User, error) {")
+	require.Contains(t, string(out), `return r.SmartQuery(ctx, crud.Query{Equal: map[string]any{"state": v}})`)
+	require.Contains(t, string(out), "\tStatus    string\n")
+	require.Contains(t, string(out), "\tStatus:    \"state\",\n")
+	require.Contains(t, string(out), "return r.GenericCRUD.CheckColumns(UserColumns)")
+
+	_, err = parseFiles([]*ast.File{f}, []string{"Missing"})
+	require.Error(t, err)
+}
@@ -0,0 +1,84 @@
+package crud
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// AfterFindHook post-processes model loaded by GenericCRUD, e.g. decrypts fields or computes derived values
+type AfterFindHook[T GORMModel] func(ctx context.Context, v *T) error
+
+const afterFindKey = "crud:after_find"
+
+// WithAfterFind returns copy of g running hooks, in order, on every model returned by its queries
+// (GetByID, Query, SmartQuery, First etc.) after associations are preloaded; error of hook fails the query
+func (g GenericCRUD[T]) WithAfterFind(hooks ...AfterFindHook[T]) GenericCRUD[T] {
+	g.afterFind = append(append([]AfterFindHook[T](nil), g.afterFind...), hooks...)
+	return g
+}
+
+// withAfterFind marks db with hooks of g for callback running them
+func (g GenericCRUD[T]) withAfterFind(db *gorm.DB) *gorm.DB {
+	hooks := g.afterFind
+	return db.Set(afterFindKey, func(db *gorm.DB) error {
+		return eachModel(db.Statement.ReflectValue, func(v *T) error {
+			for _, h := range hooks {
+				if err := h(db.Statement.Context, v); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// registerAfterFind adds callback running hooks to db; it runs after preloading. See Setup
+func registerAfterFind(db *gorm.DB) {
+	const name = "crud:after_find"
+	if db.Callback().Query().Get(name) != nil {
+		return
+	}
+	_ = db.Callback().Query().After("gorm:preload").Register(name, runAfterFind)
+}
+
+// runAfterFind runs hooks set by withAfterFind on loaded models
+func runAfterFind(db *gorm.DB) {
+	fn, ok := db.Get(afterFindKey)
+	if !ok || db.Error != nil || db.DryRun {
+		return
+	}
+	if err := fn.(func(*gorm.DB) error)(db); err != nil {
+		_ = db.AddError(err)
+	}
+}
+
+// eachModel calls fn with every T of rv: T, *T or slice of either; values of other types are skipped
+func eachModel[T any](rv reflect.Value, fn func(*T) error) error {
+	visit := func(v reflect.Value) error {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		if !v.CanAddr() {
+			return nil
+		}
+		m, ok := v.Addr().Interface().(*T)
+		if !ok {
+			return nil
+		}
+		return fn(m)
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return visit(rv)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := visit(rv.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
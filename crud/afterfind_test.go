@@ -0,0 +1,41 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAfterFind(t *testing.T) {
+	db := benchDB(t, "afterfind")
+	require.NoError(t, db.AutoMigrate(&Owner{}, &Pet{}))
+	owners := New[Owner](db).WithAfterFind(func(ctx context.Context, o *Owner) error {
+		o.Name = fmt.Sprintf("%s (%d pets)", o.Name, len(o.Pets))
+		return nil
+	})
+	ctx := context.TODO()
+	o, err := owners.Create(ctx, Owner{Name: "alice", Pets: []Pet{{Name: "rex"}}})
+	require.NoError(t, err)
+
+	res, err := owners.SmartQuery(ctx, Query{Preload: []string{"Pets"}})
+	require.NoError(t, err)
+	require.Equal(t, "alice (1 pets)", res[0].Name)
+	got, err := owners.GetByID(ctx, Owner{Model: o.Model})
+	require.NoError(t, err)
+	require.Equal(t, "alice (0 pets)", got.Name)
+	got, err = owners.First(ctx, Query{})
+	require.NoError(t, err)
+	require.Equal(t, "alice (0 pets)", got.Name)
+	plain, err := New[Owner](db).GetByID(ctx, *o)
+	require.NoError(t, err)
+	require.Equal(t, "alice", plain.Name)
+
+	failing := owners.WithAfterFind(func(context.Context, *Owner) error {
+		return errors.New("decrypt failed")
+	})
+	_, err = failing.Query(ctx, Owner{})
+	require.EqualError(t, err, "decrypt failed")
+}
@@ -0,0 +1,34 @@
+package crud
+
+import "fmt"
+
+// AmbiguousResultError is returned by QueryOne, QueryMapOne and SmartQueryOne when more than 1 row matches;
+// errors.Is(err, MultipleResultsError) holds for it
+type AmbiguousResultError struct {
+	// Count of matching rows; at least 2 if counting failed
+	Count int
+	// IDs are primary keys of first 2 matching rows
+	IDs []any
+}
+
+// Error implements error
+func (e AmbiguousResultError) Error() string {
+	return fmt.Sprintf("%s: %d rows, ids %v", MultipleResultsError, e.Count, e.IDs)
+}
+
+// Is makes error match MultipleResultsError
+func (e AmbiguousResultError) Is(target error) bool {
+	return target == MultipleResultsError
+}
+
+// ambiguous returns AmbiguousResultError for first rows res of query; count is run for total number of rows
+func ambiguous[T GORMModel](res []*T, count func() (int64, error)) error {
+	e := AmbiguousResultError{Count: len(res)}
+	if n, err := count(); err == nil && int(n) > e.Count {
+		e.Count = int(n)
+	}
+	for _, v := range res {
+		e.IDs = append(e.IDs, (*v).PrimaryKey())
+	}
+	return e
+}
@@ -0,0 +1,86 @@
+package crud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// AnonymizeHook is implemented by models that keep PII elsewhere, e.g. in audit entries;
+// it is called within Anonymize transaction
+type AnonymizeHook interface {
+	AfterAnonymize(ctx context.Context, tx *gorm.DB) error
+}
+
+// Anonymize overwrites PII columns of v (soft-deleted included) with fields values instead of deleting the row;
+// with WithHistory versions of v in history table are overwritten too. v MUST have non-zero primary key
+func (g GenericCRUD[T]) Anonymize(ctx context.Context, v T, fields map[string]any) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	s, err := g.schema()
+	if err != nil {
+		return err
+	}
+	for k := range fields {
+		if s.LookUpField(k) == nil {
+			return fmt.Errorf("%w: unknown column %q", InvalidFilterError, k)
+		}
+	}
+	return g.RunInTransaction(ctx, func(ctx context.Context, tx GenericCRUD[T]) error {
+		res := tx.sessionOf(ctx, v).Unscoped().Model(&v).Where(s.PrioritizedPrimaryField.DBName+" = ?", v.PrimaryKey()).Updates(fields)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if tx.history {
+			if err := tx.anonymizeHistory(ctx, s, v, fields); err != nil {
+				return fmt.Errorf("history: %w", err)
+			}
+		}
+		if hook, ok := any(&v).(AnonymizeHook); ok {
+			return hook.AfterAnonymize(ctx, tx.conn(ctx).WithContext(ctx))
+		}
+		return nil
+	})
+}
+
+// anonymizeHistory overwrites fields in versions of v saved to history table
+func (g GenericCRUD[T]) anonymizeHistory(ctx context.Context, s *schema.Schema, v T, fields map[string]any) error {
+	table, err := g.HistoryTable()
+	if err != nil {
+		return err
+	}
+	var records []HistoryRecord
+	err = g.connSession(ctx).Table(table).Where("entity_id = ?", fmt.Sprint(v.PrimaryKey())).Find(&records).Error
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		version, err := historyVersion[T](r)
+		if err != nil {
+			return err
+		}
+		rv := reflect.ValueOf(&version.Value).Elem()
+		for k, value := range fields {
+			if err := s.LookUpField(k).Set(ctx, rv, value); err != nil {
+				return fmt.Errorf("history record %d: %w", r.ID, err)
+			}
+		}
+		data, err := json.Marshal(version.Value)
+		if err != nil {
+			return err
+		}
+		err = g.connSession(ctx).Table(table).Where("id = ?", r.ID).Update("data", string(data)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package crud
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var arrayEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// arrayLiteral returns Postgres array literal of values, e.g. {"a","b"}; sent as text it is parsed by the
+// server as the array type of the column compared with
+func arrayLiteral(values []any) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if v == nil {
+			b.WriteString("NULL")
+			continue
+		}
+		b.WriteByte('"')
+		b.WriteString(arrayEscaper.Replace(fmt.Sprint(v)))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// arrayWhere adds Postgres array conditions of q to stmt
+func (g GenericCRUD[T]) arrayWhere(stmt *gorm.DB, q Query) (*gorm.DB, error) {
+	if len(q.ArrayContains)+len(q.ArrayContainedBy)+len(q.ArrayOverlaps)+len(q.ArrayAny) == 0 {
+		return stmt, nil
+	}
+	if d := g.Dialect(); d != Postgres {
+		return nil, fmt.Errorf("array operators are not supported for %q", d)
+	}
+	for _, k := range sortedKeys(q.ArrayContains) {
+		v := q.ArrayContains[k]
+		stmt = stmt.Where(k+" @> ?", arrayLiteral(v))
+	}
+	for _, k := range sortedKeys(q.ArrayContainedBy) {
+		v := q.ArrayContainedBy[k]
+		stmt = stmt.Where(k+" <@ ?", arrayLiteral(v))
+	}
+	for _, k := range sortedKeys(q.ArrayOverlaps) {
+		v := q.ArrayOverlaps[k]
+		stmt = stmt.Where(k+" && ?", arrayLiteral(v))
+	}
+	for _, k := range sortedKeys(q.ArrayAny) {
+		v := q.ArrayAny[k]
+		stmt = stmt.Where("? = ANY("+k+")", v)
+	}
+	return stmt, nil
+}
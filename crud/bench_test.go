@@ -0,0 +1,37 @@
+package crud
+
+import (
+	"context"
+	"testing"
+)
+
+func BenchmarkCreate(b *testing.B) {
+	g := New[User](benchDB(b, "bench_create"))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.Create(context.TODO(), User{Name: "bench"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkQuery(b *testing.B) {
+	g := New[User](benchDB(b, "bench_query"))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.Query(context.TODO(), User{Name: "bench"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSmartQuery(b *testing.B) {
+	g := New[User](benchDB(b, "bench_smart_query"))
+	q := Query{Equal: map[string]any{"name": "bench"}, OrderBy: []OrderClause{{Column: "id", Direction: DESC}}, Limit: 10}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.SmartQuery(context.TODO(), q); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
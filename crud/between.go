@@ -0,0 +1,39 @@
+package crud
+
+import "time"
+
+// now is the time source of relative Between constructors
+var now = time.Now
+
+// BetweenTimes returns Between for [from, to)
+func BetweenTimes(from, to time.Time) Between {
+	// BETWEEN is inclusive; database timestamps have microsecond precision
+	return Between{From: from, To: to.Add(-time.Microsecond)}
+}
+
+// Today returns Between covering current day in loc; nil loc means time.Local
+func Today(loc *time.Location) Between {
+	start := startOfDay(now(), loc)
+	return BetweenTimes(start, start.AddDate(0, 0, 1))
+}
+
+// LastNDays returns Between covering n days up to the end of current day in loc, today included
+func LastNDays(n int, loc *time.Location) Between {
+	end := startOfDay(now(), loc).AddDate(0, 0, 1)
+	return BetweenTimes(end.AddDate(0, 0, -n), end)
+}
+
+// ThisMonth returns Between covering current calendar month in loc
+func ThisMonth(loc *time.Location) Between {
+	t := startOfDay(now(), loc)
+	start := t.AddDate(0, 0, 1-t.Day())
+	return BetweenTimes(start, start.AddDate(0, 1, 0))
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
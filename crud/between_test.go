@@ -0,0 +1,23 @@
+package crud
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBetweenRelative(t *testing.T) {
+	kyiv, err := time.LoadLocation("Europe/Kiev")
+	require.NoError(t, err)
+	now = func() time.Time { return time.Date(2023, 3, 15, 22, 30, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	end := time.Date(2023, 3, 17, 0, 0, 0, 0, kyiv).Add(-time.Microsecond)
+	require.Equal(t, Between{From: time.Date(2023, 3, 16, 0, 0, 0, 0, kyiv), To: end}, Today(kyiv))
+	require.Equal(t, Between{From: time.Date(2023, 3, 10, 0, 0, 0, 0, kyiv), To: end}, LastNDays(7, kyiv))
+	require.Equal(t, Between{
+		From: time.Date(2023, 3, 1, 0, 0, 0, 0, kyiv),
+		To:   time.Date(2023, 4, 1, 0, 0, 0, 0, kyiv).Add(-time.Microsecond),
+	}, ThisMonth(kyiv))
+}
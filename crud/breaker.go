@@ -0,0 +1,179 @@
+package crud
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CircuitOpenError is returned instead of running statement while circuit breaker is open
+var CircuitOpenError = errors.New("circuit breaker is open")
+
+// CircuitBreaker decides whether statement may run; done reports its outcome.
+// Implemented by Breaker and by *gobreaker.TwoStepCircuitBreaker of sony/gobreaker
+type CircuitBreaker interface {
+	Allow() (done func(success bool), err error)
+}
+
+const (
+	circuitBreakerKey = "crud:circuit_breaker"
+	breakerFailureKey = "crud:breaker_failure"
+)
+
+// WithCircuitBreaker returns copy of g checking cb before every statement, so calls fail fast
+// with CircuitOpenError while database is failing. Only errors reported by Config.BreakerFailure count as failures,
+// by default ConnectionFailure: constraint violations, validation and hook errors don't open the breaker
+func (g GenericCRUD[T]) WithCircuitBreaker(cb CircuitBreaker) GenericCRUD[T] {
+	g.cfg.CircuitBreaker = cb
+	return g
+}
+
+// withCircuitBreaker marks db with breaker and failure classifier for callbacks consulting them
+func (g GenericCRUD[T]) withCircuitBreaker(db *gorm.DB) *gorm.DB {
+	failure := g.cfg.BreakerFailure
+	if failure == nil {
+		failure = ConnectionFailure
+	}
+	return db.Set(circuitBreakerKey, g.cfg.CircuitBreaker).Set(breakerFailureKey, failure)
+}
+
+// ConnectionFailure reports whether err is failure of database rather than of statement: broken or refused
+// connection, timeout or server unavailable; recognized by driver errors and messages of supported dialects
+func ConnectionFailure(err error) bool {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return false
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone), errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE), errors.As(err, &netErr):
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range connectionFailures {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// connectionFailures are messages of connection errors of Postgres, MySQL and SQLite
+var connectionFailures = []string{
+	"connection refused", "connection reset", "broken pipe", "bad connection", "i/o timeout",
+	"failed to connect", "server closed", "conn closed", "too many connections", "too many clients",
+	"the database system is", "terminating connection", "invalid connection",
+}
+
+// registerCircuitBreaker adds callbacks consulting breaker to db; they run first and last, around default transaction and other features.
+// See Setup
+func registerCircuitBreaker(db *gorm.DB) {
+	const allow, done = "crud:circuit_breaker_allow", "crud:circuit_breaker_done"
+	if db.Callback().Query().Get(allow) != nil {
+		return
+	}
+	cb := db.Callback()
+	_ = cb.Query().Before("gorm:query").Register(allow, allowStatement)
+	_ = cb.Query().After("gorm:after_query").Register(done, statementDone)
+	_ = cb.Create().Before(firstCallback(db, "create")).Register(allow, allowStatement)
+	_ = cb.Create().After("gorm:after_create").Register(done, statementDone)
+	_ = cb.Update().Before(firstCallback(db, "update")).Register(allow, allowStatement)
+	_ = cb.Update().After("gorm:after_update").Register(done, statementDone)
+	_ = cb.Delete().Before(firstCallback(db, "delete")).Register(allow, allowStatement)
+	_ = cb.Delete().After("gorm:after_delete").Register(done, statementDone)
+	_ = cb.Row().Before("gorm:row").Register(allow, allowStatement)
+	_ = cb.Row().After("gorm:row").Register(done, statementDone)
+	_ = cb.Raw().Before("gorm:raw").Register(allow, allowStatement)
+	_ = cb.Raw().After("gorm:raw").Register(done, statementDone)
+}
+
+// allowStatement fails statement with CircuitOpenError if breaker rejects it
+func allowStatement(db *gorm.DB) {
+	v, ok := db.Get(circuitBreakerKey)
+	if !ok || db.Error != nil {
+		return
+	}
+	done, err := v.(CircuitBreaker).Allow()
+	if err != nil {
+		if !errors.Is(err, CircuitOpenError) {
+			err = fmt.Errorf("%w: %v", CircuitOpenError, err)
+		}
+		_ = db.AddError(err)
+		return
+	}
+	db.InstanceSet(circuitBreakerKey, done)
+}
+
+// statementDone reports outcome of statement allowed by allowStatement
+func statementDone(db *gorm.DB) {
+	v, ok := db.InstanceGet(circuitBreakerKey)
+	if !ok {
+		return
+	}
+	failure := ConnectionFailure
+	if f, ok := db.Get(breakerFailureKey); ok {
+		failure = f.(func(error) bool)
+	}
+	v.(func(bool))(db.Error == nil || !failure(db.Error))
+}
+
+// Breaker is simple CircuitBreaker: it opens after Failures consecutive failures, rejects statements
+// for Cooldown, then lets one probe through; success of probe closes it, failure opens it again
+type Breaker struct {
+	Failures int
+	Cooldown time.Duration
+
+	mu        sync.Mutex
+	failed    int
+	openUntil time.Time
+	probing   bool
+}
+
+// NewBreaker is a constructor
+func NewBreaker(failures int, cooldown time.Duration) *Breaker {
+	return &Breaker{Failures: failures, Cooldown: cooldown}
+}
+
+// Allow implements CircuitBreaker
+func (b *Breaker) Allow() (func(success bool), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failed >= b.Failures {
+		if b.probing || now().Before(b.openUntil) {
+			return nil, CircuitOpenError
+		}
+		b.probing = true
+	}
+	return b.done, nil
+}
+
+// Open reports whether breaker rejects statements
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failed >= b.Failures && (b.probing || now().Before(b.openUntil))
+}
+
+func (b *Breaker) done(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if success {
+		b.failed = 0
+		return
+	}
+	b.failed++
+	if b.failed >= b.Failures {
+		b.openUntil = now().Add(b.Cooldown)
+	}
+}
@@ -0,0 +1,78 @@
+package crud
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var log []string
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: recordingPool{&log}}), &gorm.Config{
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+	start := time.Now()
+	now = func() time.Time { return start }
+	t.Cleanup(func() { now = time.Now })
+
+	b := NewBreaker(2, time.Minute)
+	g := NewWithConfig[User](db, Config{CircuitBreaker: b, BreakerFailure: func(err error) bool { return errors.Is(err, errRecorded) }})
+	for i := 0; i < 2; i++ {
+		_, err = g.SmartQuery(context.TODO(), Query{})
+		require.ErrorIs(t, err, errRecorded)
+	}
+	require.True(t, b.Open())
+
+	log = nil
+	_, err = g.SmartQuery(context.TODO(), Query{})
+	require.ErrorIs(t, err, CircuitOpenError)
+	require.ErrorIs(t, g.UpdateField(context.TODO(), User{Model: gorm.Model{ID: 1}}, "name", "x"), CircuitOpenError)
+	require.Empty(t, log)
+
+	now = func() time.Time { return start.Add(time.Minute) }
+	require.NoError(t, g.UpdateField(context.TODO(), User{Model: gorm.Model{ID: 1}}, "name", "x"))
+	require.False(t, b.Open())
+	require.Len(t, log, 3)
+
+	_, err = New[User](db).SmartQuery(context.TODO(), Query{})
+	require.ErrorIs(t, err, errRecorded)
+}
+
+func TestBreakerFailure(t *testing.T) {
+	ctx := context.TODO()
+	b := NewBreaker(1, time.Minute)
+	g := New[User](benchDB(t, "breaker_failure")).WithCircuitBreaker(b)
+	ann, err := g.Create(ctx, User{Name: "ann"})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = g.Create(ctx, User{Model: gorm.Model{ID: ann.ID}, Name: "dup"})
+		require.Error(t, err)
+		_, err = g.GetByID(ctx, User{Model: gorm.Model{ID: 100}})
+		require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	}
+	require.False(t, b.Open())
+	_, err = g.GetByID(ctx, *ann)
+	require.NoError(t, err)
+
+	for err, failure := range map[error]bool{
+		fmt.Errorf("query: %w", driver.ErrBadConn):                         true,
+		errors.New("dial tcp 127.0.0.1:5432: connect: connection refused"): true,
+		errors.New("FATAL: the database system is shutting down"):          true,
+		context.DeadlineExceeded:                                           true,
+		context.Canceled:                                                   false,
+		gorm.ErrRecordNotFound:                                             false,
+		errors.New("UNIQUE constraint failed: users.id"):                   false,
+		fmt.Errorf("create: %w", UniqueConflictError{Constraint: "email"}): false,
+		fmt.Errorf("%w: age", ForbiddenFieldError):                         false,
+	} {
+		require.Equal(t, failure, ConnectionFailure(err), err.Error())
+	}
+}
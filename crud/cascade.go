@@ -0,0 +1,95 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// CascadeReport is number of rows deleted (or matched in dry run) per table
+type CascadeReport map[string]int64
+
+// DeleteCascade deletes rows of has-one, has-many and many2many associations of v, then v itself, within transaction.
+// Models with gorm.DeletedAt are soft-deleted; many2many join rows are always deleted. v MUST have non-zero primary key
+func (g GenericCRUD[T]) DeleteCascade(ctx context.Context, v T, associations ...string) (CascadeReport, error) {
+	return g.cascade(ctx, v, associations, false)
+}
+
+// DeleteCascadeDryRun reports rows DeleteCascade would delete without deleting them
+func (g GenericCRUD[T]) DeleteCascadeDryRun(ctx context.Context, v T, associations ...string) (CascadeReport, error) {
+	return g.cascade(ctx, v, associations, true)
+}
+
+func (g GenericCRUD[T]) cascade(ctx context.Context, v T, associations []string, dryRun bool) (CascadeReport, error) {
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	rels := make([]*schema.Relationship, len(associations))
+	for i, name := range associations {
+		rel, ok := s.Relationships.Relations[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown association %q", InvalidFilterError, name)
+		}
+		if rel.Type == schema.BelongsTo {
+			return nil, fmt.Errorf("%w: %q is not a child association", InvalidFilterError, name)
+		}
+		rels[i] = rel
+	}
+	report := make(CascadeReport, len(rels)+1)
+	err = g.RunInTransaction(ctx, func(ctx context.Context, tx GenericCRUD[T]) error {
+		db := tx.conn(ctx).WithContext(ctx)
+		rv := reflect.ValueOf(&v).Elem()
+		for _, rel := range rels {
+			model, table, conds := childConds(ctx, rel, rv)
+			n, err := cascadeStep(db.Where(conds), model, dryRun)
+			if err != nil {
+				return fmt.Errorf("%s: %w", rel.Name, err)
+			}
+			report[table] += n
+		}
+		n, err := cascadeStep(tx.sessionOf(ctx, v).Where(s.PrioritizedPrimaryField.DBName+" = ?", v.PrimaryKey()), &v, dryRun)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		report[s.Table] += n
+		return nil
+	})
+	return report, err
+}
+
+// childConds returns model, table and conditions matching rows of rel owned by parent
+func childConds(ctx context.Context, rel *schema.Relationship, parent reflect.Value) (any, string, map[string]any) {
+	conds := make(map[string]any, len(rel.References))
+	for _, ref := range rel.References {
+		if ref.PrimaryKey == nil {
+			// polymorphic type column
+			conds[ref.ForeignKey.DBName] = ref.PrimaryValue
+			continue
+		}
+		if ref.OwnPrimaryKey {
+			conds[ref.ForeignKey.DBName], _ = ref.PrimaryKey.ValueOf(ctx, parent)
+		}
+	}
+	if rel.JoinTable != nil {
+		return reflect.New(rel.JoinTable.ModelType).Interface(), rel.JoinTable.Table, conds
+	}
+	return reflect.New(rel.FieldSchema.ModelType).Interface(), rel.FieldSchema.Table, conds
+}
+
+// cascadeStep deletes or counts rows of model matched by stmt
+func cascadeStep(stmt *gorm.DB, model any, dryRun bool) (int64, error) {
+	if dryRun {
+		var n int64
+		err := stmt.Model(model).Count(&n).Error
+		return n, err
+	}
+	res := stmt.Delete(model)
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,39 @@
+package crud
+
+import "time"
+
+type (
+	// Clock is time source of timestamps set by GenericCRUD; see WithClock
+	Clock interface {
+		Now() time.Time
+	}
+
+	// ClockFunc implements Clock with func, e.g. ClockFunc(time.Now)
+	ClockFunc func() time.Time
+)
+
+// Now implements Clock
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// FixedClock returns Clock always returning t
+func FixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time { return t })
+}
+
+// WithClock returns copy of g taking current time from clock: CreatedAt, UpdatedAt and DeletedAt set by gorm,
+// timestamps of COPY, history records and Purge cutoff, e.g. to make tests deterministic.
+// Nil clock restores time source of gorm
+func (g GenericCRUD[T]) WithClock(clock Clock) GenericCRUD[T] {
+	g.cfg.Clock = clock
+	return g
+}
+
+// now returns current time of Config.Clock
+func (g GenericCRUD[T]) now() time.Time {
+	if g.cfg.Clock == nil {
+		return now()
+	}
+	return g.cfg.Clock.Now()
+}
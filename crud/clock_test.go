@@ -0,0 +1,38 @@
+package crud
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithClock(t *testing.T) {
+	ctx := context.TODO()
+	db := benchDB(t, "clock")
+	current := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	g := New[User](db).WithClock(ClockFunc(func() time.Time { return current }))
+
+	u, err := g.Create(ctx, User{Name: "ann"})
+	require.NoError(t, err)
+	current = current.Add(time.Hour)
+	require.NoError(t, g.UpdateField(ctx, *u, "name", "ann2"))
+	current = current.Add(time.Hour)
+	require.NoError(t, g.Delete(ctx, *u))
+
+	var got User
+	require.NoError(t, db.Unscoped().First(&got, u.ID).Error)
+	require.True(t, got.CreatedAt.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)), got.CreatedAt)
+	require.True(t, got.UpdatedAt.Equal(time.Date(2024, 1, 2, 4, 4, 5, 0, time.UTC)), got.UpdatedAt)
+	require.True(t, got.DeletedAt.Time.Equal(current), got.DeletedAt.Time)
+
+	purged, err := g.Purge(ctx, 24*time.Hour)
+	require.NoError(t, err)
+	require.Zero(t, purged)
+	purged, err = g.WithClock(FixedClock(current.AddDate(0, 0, 2))).Purge(ctx, 24*time.Hour)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, purged)
+
+	require.WithinDuration(t, time.Now(), g.WithClock(nil).now(), time.Minute)
+}
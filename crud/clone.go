@@ -0,0 +1,103 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// Clone copies row of v (by primary key) with fresh primary key and timestamps, applying overrides keyed by column
+// or field name. Has-one and has-many associations listed in withAssociations are copied too,
+// many2many associations are linked to the same rows
+func (g GenericCRUD[T]) Clone(ctx context.Context, v T, overrides map[string]any, withAssociations ...string) (*T, error) {
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range withAssociations {
+		if _, ok := s.Relationships.Relations[name]; !ok {
+			return nil, fmt.Errorf("%w: unknown association %q", InvalidFilterError, name)
+		}
+	}
+	stmt := g.sessionOf(ctx, v).Omit(g.omits(ctx)...)
+	for _, name := range withAssociations {
+		stmt = stmt.Preload(name)
+	}
+	if err = stmt.Take(&v, v.PrimaryKey()).Error; err != nil {
+		return nil, err
+	}
+
+	rv := reflect.ValueOf(&v).Elem()
+	if err = resetRow(ctx, s, rv); err != nil {
+		return nil, err
+	}
+	for _, name := range withAssociations {
+		rel := s.Relationships.Relations[name]
+		if rel.Type != schema.HasOne && rel.Type != schema.HasMany {
+			continue
+		}
+		if err = resetAssociation(ctx, rel, rel.Field.ReflectValueOf(ctx, rv)); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	for k, value := range overrides {
+		field := s.LookUpField(k)
+		if field == nil {
+			return nil, fmt.Errorf("%w: unknown column %q", InvalidFilterError, k)
+		}
+		if g.hidden(ctx, field.DBName) {
+			return nil, fmt.Errorf("%w: %s", ForbiddenFieldError, field.DBName)
+		}
+		if err = field.Set(ctx, rv, value); err != nil {
+			return nil, fmt.Errorf("column %s: %w", k, err)
+		}
+	}
+	return g.Create(ctx, v)
+}
+
+// resetAssociation resets rows of has-one or has-many association value rv, which may be struct, pointer or slice
+func resetAssociation(ctx context.Context, rel *schema.Relationship, rv reflect.Value) error {
+	rv = reflect.Indirect(rv)
+	if rv.Kind() != reflect.Slice {
+		if !rv.IsValid() {
+			return nil
+		}
+		return resetChild(ctx, rel, rv)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := resetChild(ctx, rel, reflect.Indirect(rv.Index(i))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resetChild resets row and foreign keys of rel, set from parent on create
+func resetChild(ctx context.Context, rel *schema.Relationship, rv reflect.Value) error {
+	if err := resetRow(ctx, rel.FieldSchema, rv); err != nil {
+		return err
+	}
+	for _, ref := range rel.References {
+		if ref.OwnPrimaryKey {
+			if err := ref.ForeignKey.Set(ctx, rv, reflect.Zero(ref.ForeignKey.FieldType).Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resetRow zeroes primary keys, auto timestamps and soft delete field of rv
+func resetRow(ctx context.Context, s *schema.Schema, rv reflect.Value) error {
+	deletedAt := softDeleteField(s)
+	for _, f := range s.Fields {
+		if f.PrimaryKey || f.AutoCreateTime > 0 || f.AutoUpdateTime > 0 || f == deletedAt {
+			if err := f.Set(ctx, rv, reflect.Zero(f.FieldType).Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package crud
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CheckColumns returns InvalidFilterError if value of any string field of struct columns, e.g. UserColumns
+// generated by cruder-gen, is not a column of T
+func (g GenericCRUD[T]) CheckColumns(columns any) error {
+	rv := reflect.Indirect(reflect.ValueOf(columns))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: columns must be struct, got %T", InvalidFilterError, columns)
+	}
+	s, err := g.schema()
+	if err != nil {
+		return err
+	}
+	var unknown []string
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Field(i)
+		if f.Kind() != reflect.String {
+			continue
+		}
+		if _, ok := s.FieldsByDBName[f.String()]; !ok {
+			unknown = append(unknown, fmt.Sprintf("%s=%q", rv.Type().Field(i).Name, f.String()))
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: unknown columns of %s: %s", InvalidFilterError, s.Name, strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package crud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckColumns(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	columns := struct {
+		ID, Name string
+		Age      string
+	}{ID: "id", Name: "name", Age: "age"}
+	require.NoError(t, g.CheckColumns(columns))
+	require.NoError(t, g.CheckColumns(&columns))
+	columns.Name = "full_name"
+	require.EqualError(t, g.CheckColumns(columns), `invalid filter: unknown columns of User: Name="full_name"`)
+	require.ErrorIs(t, g.CheckColumns("name"), InvalidFilterError)
+}
@@ -0,0 +1,115 @@
+package crud
+
+import (
+	"reflect"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+Computed is implemented by models with computed columns: read-only fields filled by SQL expressions
+whenever the model is queried. Fields should be tagged `gorm:"->;-:migration"`, so they are neither
+written nor migrated:
+
+	type Person struct {
+		crud.Model
+		FirstName, LastName string
+		FullName            string `gorm:"->;-:migration"`
+	}
+
+	func (Person) ComputedColumns() map[string]string {
+		return map[string]string{"full_name": "first_name || ' ' || last_name"}
+	}
+
+Expressions are raw SQL. Computed columns may be ordered by and selected by name, but not filtered
+*/
+type Computed interface {
+	ComputedColumns() map[string]string
+}
+
+const computedKey = "crud:computed"
+
+// computedColumns returns computed columns of T, if any
+func (g GenericCRUD[T]) computedColumns() map[string]string {
+	var v T
+	if c, ok := any(v).(Computed); ok {
+		return c.ComputedColumns()
+	}
+	return nil
+}
+
+// withComputed marks db with computed columns of T for callback selecting them
+func (g GenericCRUD[T]) withComputed(db *gorm.DB, columns map[string]string) *gorm.DB {
+	return db.Set(computedKey, computed{model: reflect.TypeOf((*T)(nil)).Elem(), columns: columns})
+}
+
+type computed struct {
+	model   reflect.Type
+	columns map[string]string
+}
+
+// registerComputed adds callback selecting computed columns to db; it runs before query is built. See Setup
+func registerComputed(db *gorm.DB) {
+	const name = "crud:computed"
+	if db.Callback().Query().Get(name) != nil {
+		return
+	}
+	_ = db.Callback().Query().Before("gorm:query").Register(name, selectComputed)
+}
+
+// selectComputed replaces computed columns of select list with their expressions; without select list
+// all columns but omitted ones are selected. Statements with own SELECT clause, e.g. Count, are kept
+func selectComputed(db *gorm.DB) {
+	v, ok := db.Get(computedKey)
+	s := db.Statement
+	if !ok || db.Error != nil || s.Schema == nil || s.SQL.Len() > 0 {
+		return
+	}
+	c := v.(computed)
+	if s.Schema.ModelType != c.model {
+		return
+	}
+	if _, ok := s.Clauses["SELECT"]; ok {
+		return
+	}
+	if len(s.Selects) > 0 {
+		// Selects may share backing array with Query.Select of caller
+		selects := make([]string, len(s.Selects))
+		for i, name := range s.Selects {
+			selects[i] = name
+			if expr, ok := c.columns[name]; ok {
+				selects[i] = c.expr(s, name, expr)
+			}
+		}
+		s.Selects = selects
+		return
+	}
+	selected, _ := s.SelectAndOmitColumns(false, false)
+	sel := clause.Select{Distinct: s.Distinct}
+	for _, name := range s.Schema.DBNames {
+		if _, ok := c.columns[name]; ok {
+			continue
+		}
+		if v, ok := selected[name]; !ok || v {
+			sel.Columns = append(sel.Columns, clause.Column{Table: s.Table, Name: name})
+		}
+	}
+	names := make([]string, 0, len(c.columns))
+	for name := range c.columns {
+		if v, ok := selected[name]; !ok || v {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sel.Columns = append(sel.Columns, clause.Column{Name: c.expr(s, name, c.columns[name]), Raw: true})
+	}
+	s.AddClause(sel)
+}
+
+// expr returns select item of computed column
+func (c computed) expr(s *gorm.Statement, name, expr string) string {
+	return "(" + expr + ") AS " + s.Quote(name)
+}
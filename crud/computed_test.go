@@ -0,0 +1,60 @@
+package crud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type person struct {
+	gorm.Model
+	FirstName string
+	LastName  string
+	FullName  string `gorm:"->;-:migration"`
+}
+
+func (p person) PrimaryKey() any { return p.ID }
+
+func (person) ComputedColumns() map[string]string {
+	return map[string]string{"full_name": "first_name || ' ' || last_name"}
+}
+
+func TestComputed(t *testing.T) {
+	db := benchDB(t, "computed")
+	require.NoError(t, db.AutoMigrate(&person{}))
+	require.False(t, db.Migrator().HasColumn(&person{}, "full_name"))
+	people := New[person](db)
+	ctx := context.TODO()
+	p, err := people.Create(ctx, person{FirstName: "Ada", LastName: "Lovelace"})
+	require.NoError(t, err)
+	_, err = people.Create(ctx, person{FirstName: "Alan", LastName: "Turing"})
+	require.NoError(t, err)
+
+	res, err := people.SmartQuery(ctx, Query{OrderBy: []OrderClause{{Column: "full_name", Direction: DESC}}})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, "Alan Turing", res[0].FullName)
+	require.Equal(t, "Ada Lovelace", res[1].FullName)
+	got, err := people.GetByID(ctx, person{Model: p.Model})
+	require.NoError(t, err)
+	require.Equal(t, "Ada Lovelace", got.FullName)
+
+	res, err = people.SmartQuery(ctx, Query{Omit: []string{"full_name"}})
+	require.NoError(t, err)
+	require.Empty(t, res[0].FullName)
+	require.Equal(t, "Ada", res[0].FirstName)
+	selects := []string{"id", "full_name"}
+	for i := 0; i < 2; i++ {
+		res, err = people.SmartQuery(ctx, Query{Select: selects})
+		require.NoError(t, err)
+		require.Equal(t, "Ada Lovelace", res[0].FullName)
+		require.Empty(t, res[0].FirstName)
+	}
+	require.Equal(t, []string{"id", "full_name"}, selects)
+
+	n, err := people.Count(ctx, Query{})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, n)
+}
@@ -0,0 +1,201 @@
+package crud
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// ConflictingFilterError is returned for conflicting conditions with ConflictsError mode; it wraps InvalidFilterError
+var ConflictingFilterError = fmt.Errorf("%w: conflicting conditions", InvalidFilterError)
+
+// ConflictMode configures handling of conflicting conditions of Query; see Query.Conflicts
+type ConflictMode uint8
+
+const (
+	// ConflictsIgnore builds every condition, the default
+	ConflictsIgnore ConflictMode = iota
+	// ConflictsWarn logs conflicts to Config.Logger (standard logger if nil) and builds every condition
+	ConflictsWarn
+	// ConflictsError fails query with ConflictingFilterError
+	ConflictsError
+	// ConflictsLastWins drops conditions conflicting with later ones of the same column, in order conditions
+	// are built: like, ilike, pattern, between, equal, not_equal, not_like, not_between, gt, gte, lt, lte, in
+	ConflictsLastWins
+)
+
+// Conflict is column with conflicting or duplicate conditions, listed by operator in order they are built
+type Conflict struct {
+	Column    string
+	Operators []string
+}
+
+// String implements fmt.Stringer
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s: %s", c.Column, strings.Join(c.Operators, ", "))
+}
+
+// WithConflicts returns copy of g handling conflicting conditions of SmartQuery, Count and other Query methods by mode
+func (g GenericCRUD[T]) WithConflicts(mode ConflictMode) GenericCRUD[T] {
+	g.cfg.Conflicts = mode
+	return g
+}
+
+// operator is condition map of Query
+type operator struct {
+	name   string
+	keys   func(q Query) []string
+	delete func(q *Query, column string)
+}
+
+func op[V any](name string, m func(q *Query) *map[string]V) operator {
+	return operator{
+		name: name,
+		keys: func(q Query) []string { return appendKeys(nil, *m(&q)) },
+		delete: func(q *Query, column string) {
+			// copy map, it is shared with caller
+			p := m(q)
+			res := make(map[string]V, len(*p))
+			for k, v := range *p {
+				if k != column {
+					res[k] = v
+				}
+			}
+			*p = res
+		},
+	}
+}
+
+// conditionOps in order of where
+var conditionOps = []operator{
+	op("like", func(q *Query) *map[string]string { return &q.Like }),
+	op("ilike", func(q *Query) *map[string]string { return &q.ILike }),
+	op("pattern", func(q *Query) *map[string]Pattern { return &q.Pattern }),
+	op("between", func(q *Query) *map[string]Between { return &q.Between }),
+	op("equal", func(q *Query) *map[string]any { return &q.Equal }),
+	op("not_equal", func(q *Query) *map[string]any { return &q.NotEqual }),
+	op("not_like", func(q *Query) *map[string]string { return &q.NotLike }),
+	op("not_between", func(q *Query) *map[string]Between { return &q.NotBetween }),
+	op("gt", func(q *Query) *map[string]any { return &q.Gt }),
+	op("gte", func(q *Query) *map[string]any { return &q.Gte }),
+	op("lt", func(q *Query) *map[string]any { return &q.Lt }),
+	op("lte", func(q *Query) *map[string]any { return &q.Lte }),
+	op("in", func(q *Query) *map[string][]any { return &q.In }),
+}
+
+// conflictGroups are operators allowed at most once per column: matches and lower and upper bounds
+var conflictGroups = [][]string{
+	{"equal", "in", "like", "ilike", "pattern", "between"},
+	{"gt", "gte", "between"},
+	{"lt", "lte", "between"},
+}
+
+/*
+Conflicts returns columns with conditions that contradict or duplicate each other, sorted by column:
+equal with any other condition, more than one of equal, in, like, ilike, pattern and between, or more than
+one lower (gt, gte, between) or upper (lt, lte, between) bound. E.g. Like name=test and Equal name=test2
+*/
+func (q Query) Conflicts() []Conflict {
+	byColumn := make(map[string][]string)
+	for _, o := range conditionOps {
+		for _, k := range o.keys(q) {
+			byColumn[k] = append(byColumn[k], o.name)
+		}
+	}
+	var res []Conflict
+	for column, ops := range byColumn {
+		if conflicting(ops) {
+			res = append(res, Conflict{Column: column, Operators: ops})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Column < res[j].Column })
+	return res
+}
+
+func conflicting(ops []string) bool {
+	for i, a := range ops {
+		for _, b := range ops[i+1:] {
+			if conflicts(a, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// conflicts reports whether operators a and b conflict on the same column
+func conflicts(a, b string) bool {
+	if a == "equal" || b == "equal" {
+		return true
+	}
+	for _, group := range conflictGroups {
+		if contains(group, a) && contains(group, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastWins returns operators of ops kept by ConflictsLastWins: ones not conflicting with any later kept operator
+func lastWins(ops []string) []string {
+	var kept []string
+	for i := len(ops) - 1; i >= 0; i-- {
+		ok := true
+		for _, k := range kept {
+			ok = ok && !conflicts(ops[i], k)
+		}
+		if ok {
+			kept = append(kept, ops[i])
+		}
+	}
+	return kept
+}
+
+// resolveConflicts handles conflicts of q according to Config.Conflicts
+func (g GenericCRUD[T]) resolveConflicts(q Query) (Query, error) {
+	if g.cfg.Conflicts == ConflictsIgnore {
+		return q, nil
+	}
+	conflicts := q.Conflicts()
+	if len(conflicts) == 0 {
+		return q, nil
+	}
+	switch g.cfg.Conflicts {
+	case ConflictsWarn:
+		l := g.cfg.Logger
+		if l == nil {
+			l = log.Default()
+		}
+		for _, c := range conflicts {
+			l.Printf("crud: conflicting conditions of %s", c)
+		}
+	case ConflictsError:
+		errs := make([]string, len(conflicts))
+		for i, c := range conflicts {
+			errs[i] = c.String()
+		}
+		return q, fmt.Errorf("%w: %s", ConflictingFilterError, strings.Join(errs, "; "))
+	case ConflictsLastWins:
+		for _, c := range conflicts {
+			kept := lastWins(c.Operators)
+			for _, o := range conditionOps {
+				if contains(c.Operators, o.name) && !contains(kept, o.name) {
+					o.delete(&q, c.Column)
+				}
+			}
+		}
+	default:
+		return q, fmt.Errorf("%w: unknown conflict mode %d", InvalidFilterError, g.cfg.Conflicts)
+	}
+	return q, nil
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package crud
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	"gorm.io/gorm/schema"
+)
+
+// errNoCopy means COPY is not available on connection and INSERT should be used instead
+var errNoCopy = errors.New("copy is not supported by connection")
+
+// useCopy reports whether n rows should be inserted with COPY
+func (g GenericCRUD[T]) useCopy(ctx context.Context, n int) bool {
+	if g.cfg.CopyThreshold <= 0 || n < g.cfg.CopyThreshold || g.Dialect() != Postgres || g.cfg.DryRun != nil {
+		return false
+	}
+	_, ok := g.conn(ctx).Statement.ConnPool.(*sql.DB)
+	return ok
+}
+
+// copyFrom inserts vs with COPY FROM STDIN; falls back to batched INSERT when driver connection is not pgx
+func (g GenericCRUD[T]) copyFrom(ctx context.Context, vs []T, omit ...string) error {
+	s, err := g.schema()
+	if err != nil {
+		return err
+	}
+	var zero T
+	table, err := g.tableName(ctx, zero)
+	if err != nil {
+		return err
+	}
+	fields := copyFields(s, vs, g.omits(ctx, g.cfg.Omit, omit))
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.DBName
+	}
+	ts := g.now()
+	rows := make([][]any, len(vs))
+	for i := range vs {
+		rv := reflect.ValueOf(&vs[i]).Elem()
+		row := make([]any, len(fields))
+		for j, f := range fields {
+			value, zero := f.ValueOf(ctx, rv)
+			if zero && (f.AutoCreateTime > 0 || f.AutoUpdateTime > 0) {
+				if err = f.Set(ctx, rv, ts); err != nil {
+					return err
+				}
+				value, _ = f.ValueOf(ctx, rv)
+			}
+			row[j] = value
+		}
+		rows[i] = row
+	}
+
+	sqlDB := g.conn(ctx).Statement.ConnPool.(*sql.DB)
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errNoCopy
+		}
+		_, err := c.Conn().CopyFrom(ctx, pgx.Identifier(strings.Split(table, ".")), columns, pgx.CopyFromRows(rows))
+		return err
+	})
+	if errors.Is(err, errNoCopy) {
+		return g.session(ctx).Omit(g.omits(ctx, g.cfg.Omit, omit)...).CreateInBatches(&vs, 1000).Error
+	}
+	if err == nil {
+		// COPY bypasses callbacks clearing not-found cache
+		notFound.clear(notFoundTable{g.db.Callback(), unqualified(table)})
+	}
+	return err
+}
+
+// copyFields returns columns to copy: primary keys are skipped when zero in every row so database generates them
+func copyFields[T any](s *schema.Schema, vs []T, omit []string) []*schema.Field {
+	omitted := make(map[string]bool, len(omit))
+	for _, o := range omit {
+		omitted[o] = true
+	}
+	var fields []*schema.Field
+	for _, f := range s.Fields {
+		if f.DBName == "" || !f.Creatable || omitted[f.DBName] || omitted[f.Name] {
+			continue
+		}
+		if f.PrimaryKey && f.AutoIncrement && allZero(f, vs) {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func allZero[T any](f *schema.Field, vs []T) bool {
+	for i := range vs {
+		if _, zero := f.ValueOf(context.Background(), reflect.ValueOf(&vs[i]).Elem()); !zero {
+			return false
+		}
+	}
+	return true
+}
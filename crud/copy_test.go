@@ -0,0 +1,33 @@
+package crud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCopyFields(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	s, err := g.schema()
+	require.NoError(t, err)
+
+	columns := func(vs []User, omit ...string) []string {
+		var res []string
+		for _, f := range copyFields(s, vs, omit) {
+			res = append(res, f.DBName)
+		}
+		return res
+	}
+	require.Equal(t, []string{"created_at", "updated_at", "deleted_at", "name", "age"}, columns([]User{{Name: "a"}}))
+	require.Equal(t, []string{"id", "created_at", "updated_at", "deleted_at", "name"},
+		columns([]User{{Name: "a"}, {Model: gorm.Model{ID: 2}}}, "age"))
+}
+
+func TestUseCopy(t *testing.T) {
+	g := NewWithConfig[User](dryRunDB(t), Config{CopyThreshold: 10})
+	require.True(t, g.useCopy(context.TODO(), 10))
+	require.False(t, g.useCopy(context.TODO(), 9))
+	require.False(t, New[User](dryRunDB(t)).useCopy(context.TODO(), 100))
+}
@@ -0,0 +1,155 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type (
+	// CounterOptions configures CounterBuffer
+	CounterOptions struct {
+		// FlushInterval of Run; 1 second if zero
+		FlushInterval time.Duration
+		// MaxKeys pending (row, column) pairs triggering early flush in Run; 1000 if zero
+		MaxKeys int
+	}
+
+	// CounterBuffer accumulates column increments in memory and writes them in batches,
+	// one UPDATE per column and delta, trading staleness for fewer statements. Safe for concurrent use
+	CounterBuffer[T GORMModel] struct {
+		crud    GenericCRUD[T]
+		opts    CounterOptions
+		mu      sync.Mutex
+		pending map[string]map[any]int64
+		keys    int
+		full    chan struct{}
+	}
+)
+
+// NewCounterBuffer is a constructor
+func NewCounterBuffer[T GORMModel](crud GenericCRUD[T], opts CounterOptions) *CounterBuffer[T] {
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.MaxKeys <= 0 {
+		opts.MaxKeys = 1000
+	}
+	return &CounterBuffer[T]{
+		crud:    crud,
+		opts:    opts,
+		pending: make(map[string]map[any]int64),
+		full:    make(chan struct{}, 1),
+	}
+}
+
+// Add delta to column of v (by primary key)
+func (b *CounterBuffer[T]) Add(ctx context.Context, v T, column string, delta int64) error {
+	if b.crud.hidden(ctx, column) {
+		return fmt.Errorf("%w: %s", ForbiddenFieldError, column)
+	}
+	s, err := b.crud.schema()
+	if err != nil {
+		return err
+	}
+	f := s.LookUpField(column)
+	if f == nil || f.DBName == "" {
+		return fmt.Errorf("%w: unknown column %q", InvalidFilterError, column)
+	}
+	b.add(f.DBName, map[any]int64{v.PrimaryKey(): delta})
+	return nil
+}
+
+func (b *CounterBuffer[T]) add(column string, deltas map[any]int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m, ok := b.pending[column]
+	if !ok {
+		m = make(map[any]int64)
+		b.pending[column] = m
+	}
+	for id, delta := range deltas {
+		if _, ok := m[id]; !ok {
+			b.keys++
+		}
+		m[id] += delta
+	}
+	if b.keys >= b.opts.MaxKeys {
+		select {
+		case b.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Pending returns number of (row, column) pairs not flushed yet
+func (b *CounterBuffer[T]) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.keys
+}
+
+// Flush writes pending increments; increments of failed statements are kept for next flush and first error is returned
+func (b *CounterBuffer[T]) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	pending := b.pending
+	b.pending, b.keys = make(map[string]map[any]int64), 0
+	b.mu.Unlock()
+
+	s, err := b.crud.schema()
+	if err != nil {
+		return err
+	}
+	pk := s.PrioritizedPrimaryField.DBName
+	var first error
+	for column, deltas := range pending {
+		// group rows by delta to update them with one statement
+		byDelta := make(map[int64][]any)
+		for id, delta := range deltas {
+			if delta != 0 {
+				byDelta[delta] = append(byDelta[delta], id)
+			}
+		}
+		for delta, ids := range byDelta {
+			err := b.crud.session(ctx).Model(new(T)).Where(pk+" IN ?", ids).
+				UpdateColumn(column, gorm.Expr(column+" + ?", delta)).Error
+			if err != nil {
+				failed := make(map[any]int64, len(ids))
+				for _, id := range ids {
+					failed[id] = delta
+				}
+				b.add(column, failed)
+				if first == nil {
+					first = fmt.Errorf("%s: %w", column, err)
+				}
+			}
+		}
+	}
+	return first
+}
+
+// Run flushes every FlushInterval or when MaxKeys is reached, until ctx is done; then flushes remaining increments
+// with context.Background. Errors are passed to onError if not nil
+func (b *CounterBuffer[T]) Run(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(b.opts.FlushInterval)
+	defer ticker.Stop()
+	flush := func(ctx context.Context) {
+		if err := b.Flush(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			flush(context.Background())
+			return
+		case <-ticker.C:
+			flush(ctx)
+		case <-b.full:
+			flush(ctx)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package crud
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCounterBuffer(t *testing.T) {
+	db := dryRunDB(t)
+	stmts := captureSQL(t, db)
+	b := NewCounterBuffer(New[User](db), CounterOptions{MaxKeys: 2})
+
+	require.ErrorIs(t, b.Add(context.TODO(), User{}, "unknown", 1), InvalidFilterError)
+	for _, id := range []uint{1, 2, 1, 3} {
+		require.NoError(t, b.Add(context.TODO(), User{Model: gorm.Model{ID: id}}, "Age", 1))
+	}
+	require.Equal(t, 3, b.Pending())
+	require.Len(t, b.full, 1)
+
+	require.NoError(t, b.Flush(context.TODO()))
+	require.Zero(t, b.Pending())
+	sort.Strings(*stmts)
+	require.Equal(t, []string{
+		`UPDATE "users" SET "age"=age + $1 WHERE id IN ($2) AND "users"."deleted_at" IS NULL`,
+		`UPDATE "users" SET "age"=age + $1 WHERE id IN ($2,$3) AND "users"."deleted_at" IS NULL`,
+	}, *stmts)
+}
+
+func TestIncrement(t *testing.T) {
+	db := dryRunDB(t)
+	stmts := captureSQL(t, db)
+	g := New[User](db)
+
+	require.NoError(t, g.Increment(context.TODO(), User{Model: gorm.Model{ID: 1}}, "Age", -2))
+	require.Contains(t, (*stmts)[0], `UPDATE "users" SET "age"="age" + $1,"updated_at"=$2 WHERE "users"."deleted_at" IS NULL AND "id" = $3`)
+	require.ErrorIs(t, g.Increment(context.TODO(), User{}, "age; --", 1), InvalidFilterError)
+
+	require.NoError(t, g.UpdateExpr(context.TODO(), User{Model: gorm.Model{ID: 1}}, "name", gorm.Expr("upper(name)")))
+	require.Contains(t, (*stmts)[1], `SET "name"=upper(name)`)
+}
+
+func TestUpdateFieldsMap(t *testing.T) {
+	db := dryRunDB(t)
+	stmts := captureSQL(t, db)
+	g := New[User](db)
+	user := User{Model: gorm.Model{ID: 1}}
+
+	require.NoError(t, g.UpdateFieldsMap(context.TODO(), user, map[string]any{"Name": "", "age": gorm.Expr("age + ?", 1)}))
+	require.Len(t, *stmts, 1)
+	require.Contains(t, (*stmts)[0], `UPDATE "users" SET "age"=age + $1,"name"=$2,"updated_at"=$3 WHERE "users"."deleted_at" IS NULL AND "id" = $4`)
+	require.ErrorIs(t, g.UpdateFieldsMap(context.TODO(), user, map[string]any{"name": "x", "nickname": "x"}), InvalidFilterError)
+	require.ErrorIs(t, g.UpdateFieldsMap(context.TODO(), user, nil), InvalidFilterError)
+	require.Len(t, *stmts, 1)
+}
@@ -5,7 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
 	"log"
+	"reflect"
+	"sort"
+	"time"
 )
 
 type (
@@ -16,23 +22,134 @@ type (
 
 	// GenericCRUD is generic struct for model's CRUD operations
 	GenericCRUD[T GORMModel] struct {
-		logger *log.Logger
-		db     *gorm.DB
-		omit   []string
+		db    *gorm.DB
+		cfg   Config
+		table TableResolver[T]
+		call  callOptions
+		// history enables writing versions to history table
+		history bool
+		// enums are allowed values per column
+		enums map[string][]any
+		// afterFind hooks run on loaded models
+		afterFind []AfterFindHook[T]
+		// queries are named queries registered by RegisterQuery
+		queries map[string]Query
+	}
+
+	// Config is model-independent configuration of GenericCRUD; may be shared via Registry
+	Config struct {
+		Logger *log.Logger
+		// Omit columns on create, update and query
+		Omit []string
+		// FieldPolicy hides columns per caller
+		FieldPolicy FieldPolicy
+		// CopyThreshold makes CreateMany and Import use COPY on Postgres for at least this many rows;
+		// disabled if zero. COPY skips hooks and does not return generated primary keys
+		CopyThreshold int
+		// SlowQueryThreshold and OnSlowQuery report statements running longer than threshold
+		SlowQueryThreshold time.Duration
+		OnSlowQuery        func(SlowQueryInfo)
+		// MaxRows caps Query, QueryMap and SmartQuery results; disabled if zero.
+		// Results are truncated unless MaxRowsError is set
+		MaxRows      int
+		MaxRowsError bool
+		// SessionSettings are applied with SET LOCAL around every statement; see WithSessionSettings
+		SessionSettings SessionSettings
+		// QueryTags are appended to every statement as SQL comment; see WithQueryTags
+		QueryTags QueryTags
+		// DefaultOrder of list queries; see WithDefaultOrder
+		DefaultOrder string
+		// Collation of text ordering unless Query.Collation is set; see WithCollation
+		Collation string
+		// Coalesce shares round trips of concurrent identical reads; see WithCoalescing
+		Coalesce bool
+		// NotFoundTTL caches primary keys not found by GetByID; see WithNotFoundCache
+		NotFoundTTL time.Duration
+		// Debug logs every statement
+		Debug bool
+		// DryRun builds statements without executing them, passing each to the func; see WithDryRun
+		DryRun func(Statement)
+		// PrepareStmt caches prepared statements; see WithPrepareStmt
+		PrepareStmt bool
+		// DisableTieBreaker stops appending primary key to ORDER BY of paginated SmartQuery,
+		// which keeps pages stable when ordering by non-unique columns
+		DisableTieBreaker bool
+		// CircuitBreaker fails statements fast while database is failing; see WithCircuitBreaker
+		CircuitBreaker CircuitBreaker
+		// BreakerFailure reports whether statement error is failure of CircuitBreaker; ConnectionFailure if nil
+		BreakerFailure func(err error) bool
+		// Conflicts configures handling of conflicting conditions of Query; see WithConflicts
+		Conflicts ConflictMode
+		// Clock is time source of timestamps, gorm's NowFunc if nil; see WithClock
+		Clock Clock
+		// NPlusOneThreshold and OnNPlusOne report repeated single-row queries; see WithNPlusOneDetection
+		NPlusOneThreshold int
+		OnNPlusOne        func(NPlusOne)
 	}
 
 	OrderBy uint
 	Between struct {
-		From, To string
+		From any `json:"from"`
+		To   any `json:"to"`
 	}
 
 	Query struct {
-		Omit    []string
-		Preload []string
-		OrderBy map[string]OrderBy
-		Equal   map[string]string
-		Like    map[string]string
-		Between map[string]Between
+		// Select columns or raw expressions; not accepted from JSON as it is not validated
+		Select  []string `json:"-"`
+		Omit    []string `json:"omit,omitempty"`
+		Preload []string `json:"preload,omitempty"`
+		// OrderBy columns of T in priority order
+		OrderBy []OrderClause `json:"order_by,omitempty"`
+		// Collation of text columns in OrderBy, e.g. "uk-UA-x-icu" on Postgres, utf8mb4_unicode_ci on MySQL
+		Collation string            `json:"collation,omitempty"`
+		Equal     map[string]any    `json:"equal,omitempty"`
+		Like      map[string]string `json:"like,omitempty"`
+		// ILike is case-insensitive Like
+		ILike map[string]string `json:"ilike,omitempty"`
+		// Pattern is LIKE with configurable wildcard placement and case sensitivity
+		Pattern map[string]Pattern `json:"pattern,omitempty"`
+		Between map[string]Between `json:"between,omitempty"`
+		// NotEqual, NotLike and NotBetween are negated Equal, Like and Between
+		NotEqual   map[string]any     `json:"not_equal,omitempty"`
+		NotLike    map[string]string  `json:"not_like,omitempty"`
+		NotBetween map[string]Between `json:"not_between,omitempty"`
+		// Gt, Gte, Lt and Lte are comparisons: column > value etc.
+		Gt  map[string]any `json:"gt,omitempty"`
+		Gte map[string]any `json:"gte,omitempty"`
+		Lt  map[string]any `json:"lt,omitempty"`
+		Lte map[string]any `json:"lte,omitempty"`
+		// In matches any of values
+		In map[string][]any `json:"in,omitempty"`
+		// ArrayContains, ArrayContainedBy and ArrayOverlaps compare Postgres array columns with values
+		// (@>, <@ and &&); ArrayAny matches arrays having element equal to value
+		ArrayContains    map[string][]any `json:"array_contains,omitempty"`
+		ArrayContainedBy map[string][]any `json:"array_contained_by,omitempty"`
+		ArrayOverlaps    map[string][]any `json:"array_overlaps,omitempty"`
+		ArrayAny         map[string]any   `json:"array_any,omitempty"`
+		// InQuery matches any of values selected by subquery
+		InQuery map[string]Subquery `json:"-"`
+		// Limit and Offset paginate results; zero Limit means no limit
+		Limit  int `json:"limit,omitempty"`
+		Offset int `json:"offset,omitempty"`
+		// FullText search; results may be ordered by rank
+		FullText *FullText `json:"full_text,omitempty"`
+		// GroupBy columns; use with ScanQuery for aggregates
+		GroupBy []string `json:"group_by,omitempty"`
+		// Having conditions on groups; raw SQL, not accepted from JSON
+		Having []Expr `json:"-"`
+		// Window ranks rows within partitions, e.g. to pick latest row per user
+		Window *Window `json:"window,omitempty"`
+		// Radius and BBox are geospatial conditions; see WithinRadius and WithinBBox
+		Radius *Radius `json:"radius,omitempty"`
+		BBox   *BBox   `json:"bbox,omitempty"`
+		// Owner matches children of polymorphic association; see OwnedBy
+		Owner *OwnerFilter `json:"owner,omitempty"`
+	}
+
+	// Expr is raw SQL expression with bound vars
+	Expr struct {
+		SQL  string
+		Vars []any
 	}
 )
 
@@ -52,48 +169,184 @@ func (ob OrderBy) String() string {
 }
 
 var (
-	// MultipleResultsError is returned when GenericCRUD.QueryOne finds more than 1 row
+	// MultipleResultsError is returned when GenericCRUD.QueryOne finds more than 1 row; see AmbiguousResultError
 	MultipleResultsError = errors.New("multiple results found")
+	// ForbiddenFieldError is returned when writing a column hidden by FieldPolicy
+	ForbiddenFieldError = errors.New("forbidden field")
+	// TooManyRowsError is returned when list query exceeds Config.MaxRows with Config.MaxRowsError set
+	TooManyRowsError = errors.New("too many rows")
 )
 
 // New is a constructor
 func New[T GORMModel](db *gorm.DB, omit ...string) GenericCRUD[T] {
+	return NewWithConfig[T](db, Config{Omit: omit})
+}
+
+// NewWithConfig is a constructor; see Setup
+func NewWithConfig[T GORMModel](db *gorm.DB, cfg Config) GenericCRUD[T] {
+	Setup(db)
 	return GenericCRUD[T]{
-		logger: nil,
-		db:     db,
-		omit:   omit,
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// WithDB returns copy of g using db, e.g. transaction or another shard
+func (g GenericCRUD[T]) WithDB(db *gorm.DB) GenericCRUD[T] {
+	Setup(db)
+	g.db = db
+	return g
+}
+
+// Config returns configuration of g
+func (g GenericCRUD[T]) Config() Config {
+	return g.cfg
+}
+
+// session returns db handle for a single operation
+func (g GenericCRUD[T]) session(ctx context.Context) *gorm.DB {
+	var v T
+	return g.sessionOf(ctx, v)
+}
+
+// WithDebug returns copy of g logging every statement at info level
+func (g GenericCRUD[T]) WithDebug() GenericCRUD[T] {
+	g.cfg.Debug = true
+	return g
+}
+
+// sessionOf returns db handle for a single operation on v
+func (g GenericCRUD[T]) sessionOf(ctx context.Context, v T) *gorm.DB {
+	db := g.connSession(ctx)
+	if len(g.afterFind) > 0 {
+		db = g.withAfterFind(db)
+	}
+	if c := statsOf(ctx); c != nil && g.cfg.NPlusOneThreshold > 0 {
+		db = g.withNPlusOne(db, c)
+	}
+	if columns := g.computedColumns(); len(columns) > 0 {
+		db = g.withComputed(db, columns)
+	}
+	if s, err := g.schema(); err == nil {
+		if constraints := softUniques(s); len(constraints) > 0 {
+			db = g.withSoftUnique(db, constraints, deletedColumn(s) != "" && !partialIndexes(g.Dialect()))
+		}
+	}
+	if g.table != nil {
+		if t := g.table(ctx, v); t != "" {
+			db = db.Table(t)
+		}
+	}
+	return db
+}
+
+// connSession returns db handle for a single operation on any table, e.g. history or join table: hooks of T are
+// not applied, but dry run, logging, statistics, circuit breaker, session settings and query tags are
+func (g GenericCRUD[T]) connSession(ctx context.Context) *gorm.DB {
+	db := g.conn(ctx)
+	// single session for context, logger and prepared statements
+	session := gorm.Session{Context: ctx, PrepareStmt: g.cfg.PrepareStmt}
+	if g.cfg.Debug {
+		session.Logger = db.Logger.LogMode(logger.Info)
+	}
+	if g.cfg.OnSlowQuery != nil {
+		if session.Logger == nil {
+			session.Logger = db.Logger
+		}
+		session.Logger = g.slowLogger(session.Logger)
+	}
+	if c := statsOf(ctx); c != nil {
+		if session.Logger == nil {
+			session.Logger = db.Logger
+		}
+		session.Logger = statsLogger{Interface: session.Logger, collector: c}
+	}
+	if g.cfg.DryRun != nil {
+		g.withDryRun(db, &session)
+	}
+	if g.cfg.Clock != nil {
+		session.NowFunc = g.cfg.Clock.Now
+	}
+	db = g.withTimeout(db.Session(&session))
+	if g.cfg.CircuitBreaker != nil {
+		db = g.withCircuitBreaker(db)
 	}
+	if g.cfg.SessionSettings != nil {
+		db = g.withSessionSettings(ctx, db)
+	}
+	if g.cfg.QueryTags != nil {
+		db = g.withQueryTags(ctx, db)
+	}
+	return db
 }
 
 // Create Model
 func (g GenericCRUD[T]) Create(ctx context.Context, v T, omit ...string) (*T, error) {
-	err := g.db.Debug().WithContext(ctx).Omit(append(g.omit, omit...)...).Create(&v).Error
+	if err := g.checkEnums(v); err != nil {
+		return nil, err
+	}
+	err := g.sessionOf(ctx, v).Omit(g.omits(ctx, g.cfg.Omit, omit)...).Create(&v).Error
 	return &v, err
 }
 
+// CreateMany Models in batches of batchSize rows; all rows if batchSize <= 0
+func (g GenericCRUD[T]) CreateMany(ctx context.Context, vs []T, batchSize int, omit ...string) error {
+	if len(vs) == 0 {
+		return nil
+	}
+	for _, v := range vs {
+		if err := g.checkEnums(v); err != nil {
+			return err
+		}
+	}
+	if g.useCopy(ctx, len(vs)) {
+		return g.copyFrom(ctx, vs, omit...)
+	}
+	if batchSize <= 0 {
+		batchSize = len(vs)
+	}
+	return g.session(ctx).Omit(g.omits(ctx, g.cfg.Omit, omit)...).CreateInBatches(&vs, batchSize).Error
+}
+
 // GetOrCreate Model
 func (g GenericCRUD[T]) GetOrCreate(ctx context.Context, v T, omit ...string) (*T, error) {
-	err := g.db.Debug().WithContext(ctx).Omit(append(g.omit, omit...)...).Where(&v).FirstOrCreate(&v).Error
+	if err := g.checkEnums(v); err != nil {
+		return nil, err
+	}
+	err := g.sessionOf(ctx, v).Omit(g.omits(ctx, g.cfg.Omit, omit)...).Where(&v).FirstOrCreate(&v).Error
 	return &v, err
 }
 
 // GetByID get Model by primary key; v MUST have non-zero primary key
 func (g GenericCRUD[T]) GetByID(ctx context.Context, v T) (*T, error) {
-	err := g.db.Debug().WithContext(ctx).Take(&v, v.PrimaryKey()).Error
-	return &v, err
+	return g.coalesce(ctx, g.flightKey(ctx, "GetByID", v, nil), func() (*T, error) {
+		return g.cachedNotFound(ctx, v, func() (*T, error) {
+			err := g.sessionOf(ctx, v).Omit(g.readOmits(ctx)...).Take(&v, v.PrimaryKey()).Error
+			return &v, err
+		})
+	})
 }
 
 // Query by non-zero fields of v; returns slice of Model's
 func (g GenericCRUD[T]) Query(ctx context.Context, v T, omit ...string) ([]*T, error) {
 	var res []*T
-	err := g.db.Debug().WithContext(ctx).Omit(append(g.omit, omit...)...).Where(&v).Find(&res).Error
+	err := g.ordered(g.limit(g.sessionOf(ctx, v))).Omit(g.readOmits(ctx, g.cfg.Omit, omit)...).Where(&v).Find(&res).Error
+	if err == nil {
+		err = g.checkRows(len(res), 0)
+	}
 	return res, err
 }
 
 // QueryOne by non-zero fields of v; returns exactly one Model or error
 func (g GenericCRUD[T]) QueryOne(ctx context.Context, v T, omit ...string) (*T, error) {
+	return g.coalesce(ctx, g.flightKey(ctx, "QueryOne", v, omit), func() (*T, error) {
+		return g.queryOne(ctx, v, omit)
+	})
+}
+
+func (g GenericCRUD[T]) queryOne(ctx context.Context, v T, omit []string) (*T, error) {
 	var res []*T
-	err := g.db.Debug().WithContext(ctx).Omit(append(g.omit, omit...)...).Where(&v).Find(&res).Error
+	err := g.sessionOf(ctx, v).Omit(g.readOmits(ctx, g.cfg.Omit, omit)...).Where(&v).Limit(2).Find(&res).Error
 	if err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			err = fmt.Errorf("db error: %w", err)
@@ -104,7 +357,9 @@ func (g GenericCRUD[T]) QueryOne(ctx context.Context, v T, omit ...string) (*T,
 		return nil, gorm.ErrRecordNotFound
 	}
 	if len(res) > 1 {
-		return nil, MultipleResultsError
+		return nil, ambiguous(res, func() (n int64, err error) {
+			return n, g.sessionOf(ctx, v).Model(new(T)).Where(&v).Count(&n).Error
+		})
 	}
 	return res[0], nil
 }
@@ -112,13 +367,17 @@ func (g GenericCRUD[T]) QueryOne(ctx context.Context, v T, omit ...string) (*T,
 // QueryMap by non-zero fields of v; returns slice of Model's
 func (g GenericCRUD[T]) QueryMap(ctx context.Context, q map[string]any, omit ...string) ([]*T, error) {
 	var res []*T
-	err := g.db.Debug().WithContext(ctx).Omit(omit...).Find(&res, q).Error
+	err := g.ordered(g.limit(g.session(ctx))).Omit(g.readOmits(ctx, omit)...).Find(&res, q).Error
+	if err == nil {
+		err = g.checkRows(len(res), 0)
+	}
 	return res, err
 }
 
 // QueryMapOne by non-zero fields of v; returns exactly one Model or error
 func (g GenericCRUD[T]) QueryMapOne(ctx context.Context, q map[string]any, omit ...string) (*T, error) {
-	res, err := g.QueryMap(ctx, q, omit...)
+	var res []*T
+	err := g.session(ctx).Omit(g.readOmits(ctx, omit)...).Limit(2).Find(&res, q).Error
 	if err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			err = fmt.Errorf("db error: %w", err)
@@ -129,40 +388,215 @@ func (g GenericCRUD[T]) QueryMapOne(ctx context.Context, q map[string]any, omit
 		return nil, gorm.ErrRecordNotFound
 	}
 	if len(res) > 1 {
-		return nil, MultipleResultsError
+		return nil, ambiguous(res, func() (n int64, err error) {
+			return n, g.session(ctx).Model(new(T)).Where(q).Count(&n).Error
+		})
 	}
 	return res[0], nil
 }
 
 // SmartQuery by non-zero fields of v; returns slice of Model's
 func (g GenericCRUD[T]) SmartQuery(ctx context.Context, q Query) ([]*T, error) {
-	var (
-		res  []*T
-		err  error
-		stmt = g.db.Debug().WithContext(ctx).Omit(q.Omit...)
-	)
+	var res []*T
+	stmt, err := g.smartStmt(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if err = stmt.Find(&res).Error; err == nil {
+		err = g.checkRows(len(res), q.Limit)
+	}
+	return res, err
+}
+
+// smartStmt builds SmartQuery statement without executing it
+func (g GenericCRUD[T]) smartStmt(ctx context.Context, q Query) (*gorm.DB, error) {
+	stmt, order, err := g.where(g.session(ctx), q)
+	if err != nil {
+		return nil, err
+	}
+	if q.Window != nil {
+		if stmt, err = g.window(ctx, stmt, *q.Window); err != nil {
+			return nil, err
+		}
+	}
+	stmt = stmt.Omit(g.readOmits(ctx, q.Omit)...)
+	if len(q.Select) > 0 {
+		stmt = stmt.Select(q.Select)
+	}
 	for _, s := range q.Preload {
 		stmt = stmt.Preload(s)
 	}
-	for k, v := range q.OrderBy {
-		stmt = stmt.Order(k + " " + v.String())
+	for _, o := range q.OrderBy {
+		expr, err := g.orderExpr(q, o)
+		if err != nil {
+			return nil, err
+		}
+		order = append(order, expr)
+	}
+	if len(order) == 0 && len(q.GroupBy) == 0 && g.cfg.DefaultOrder != "" {
+		order = append(order, clause.Expr{SQL: g.cfg.DefaultOrder})
+	}
+	order = g.tieBreaker(q, order)
+	if len(order) > 0 {
+		stmt = stmt.Clauses(clause.OrderBy{Expression: joinExprs(order, ", ")})
+	}
+	for _, c := range q.GroupBy {
+		stmt = stmt.Group(c)
+	}
+	for _, h := range q.Having {
+		stmt = stmt.Having(h.SQL, h.Vars...)
+	}
+	if limit, _ := g.rowCap(q.Limit); limit > 0 {
+		stmt = stmt.Limit(limit)
+	}
+	if q.Offset > 0 {
+		stmt = stmt.Offset(q.Offset)
+	}
+	return stmt, nil
+}
+
+// where adds conditions of q to stmt; returns full-text rank and distance ordering if requested
+func (g GenericCRUD[T]) where(stmt *gorm.DB, q Query) (*gorm.DB, []clause.Expr, error) {
+	var order []clause.Expr
+	q, err := g.resolveConflicts(q)
+	if err != nil {
+		return nil, nil, err
+	}
+	if q.FullText != nil {
+		cond, rank, err := q.FullText.build(g.Dialect())
+		if err != nil {
+			return nil, nil, err
+		}
+		stmt = stmt.Where(cond)
+		if q.FullText.Rank {
+			order = append(order, rank)
+		}
+	}
+	if q.Radius != nil {
+		cond, distance, err := q.Radius.build(g.Dialect())
+		if err != nil {
+			return nil, nil, err
+		}
+		stmt = stmt.Where(cond)
+		if q.Radius.OrderByDistance {
+			order = append(order, distance)
+		}
+	}
+	if q.BBox != nil {
+		cond, err := q.BBox.build(g.Dialect())
+		if err != nil {
+			return nil, nil, err
+		}
+		stmt = stmt.Where(cond)
+	}
+	if q.Owner != nil {
+		cond, err := q.Owner.build()
+		if err != nil {
+			return nil, nil, err
+		}
+		stmt = stmt.Where(cond)
 	}
-	for k, v := range q.Like {
+	for _, k := range sortedKeys(q.Like) {
+		v := q.Like[k]
 		stmt = stmt.Where(k+" LIKE ?", fmt.Sprintf("%%%s%%", v))
 	}
-	for k, v := range q.Between {
+	for _, k := range sortedKeys(q.ILike) {
+		v := q.ILike[k]
+		stmt = stmt.Where(g.Dialect().Like(k, fmt.Sprintf("%%%s%%", v), true, false))
+	}
+	for _, k := range sortedKeys(q.Pattern) {
+		v := q.Pattern[k]
+		stmt = stmt.Where(g.Dialect().Like(k, v.pattern(), v.Insensitive, true))
+	}
+	for _, k := range sortedKeys(q.Between) {
+		v := q.Between[k]
 		stmt = stmt.Where(k+" BETWEEN ? AND ?", v.From, v.To)
 	}
-	for k, v := range q.Equal {
+	for _, k := range sortedKeys(q.Equal) {
+		v := q.Equal[k]
 		stmt = stmt.Where(k+" = ?", v)
 	}
-	err = stmt.Find(&res).Error
-	return res, err
+	for _, k := range sortedKeys(q.NotEqual) {
+		v := q.NotEqual[k]
+		stmt = stmt.Where(k+" <> ?", v)
+	}
+	for _, k := range sortedKeys(q.NotLike) {
+		v := q.NotLike[k]
+		stmt = stmt.Where(k+" NOT LIKE ?", fmt.Sprintf("%%%s%%", v))
+	}
+	for _, k := range sortedKeys(q.NotBetween) {
+		v := q.NotBetween[k]
+		stmt = stmt.Where(k+" NOT BETWEEN ? AND ?", v.From, v.To)
+	}
+	for _, k := range sortedKeys(q.Gt) {
+		v := q.Gt[k]
+		stmt = stmt.Where(k+" > ?", v)
+	}
+	for _, k := range sortedKeys(q.Gte) {
+		v := q.Gte[k]
+		stmt = stmt.Where(k+" >= ?", v)
+	}
+	for _, k := range sortedKeys(q.Lt) {
+		v := q.Lt[k]
+		stmt = stmt.Where(k+" < ?", v)
+	}
+	for _, k := range sortedKeys(q.Lte) {
+		v := q.Lte[k]
+		stmt = stmt.Where(k+" <= ?", v)
+	}
+	for _, k := range sortedKeys(q.In) {
+		v := q.In[k]
+		stmt = stmt.Where(k+" IN ?", v)
+	}
+	for _, k := range sortedKeys(q.InQuery) {
+		v := q.InQuery[k]
+		sub, err := v.build(stmt.Statement.Context)
+		if err != nil {
+			return nil, nil, err
+		}
+		stmt = stmt.Where(k+" IN (?)", sub)
+	}
+	stmt, err = g.arrayWhere(stmt, q)
+	return stmt, order, err
+}
+
+// sortedKeys of m, so conditions built from it are in stable order
+func sortedKeys[V any](m map[string]V) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// ScanQuery runs SmartQuery conditions on T's table and scans rows into dest,
+// e.g. pointer to slice of projection structs or to []map[string]any
+func (g GenericCRUD[T]) ScanQuery(ctx context.Context, q Query, dest any) error {
+	stmt, err := g.smartStmt(ctx, q)
+	if err != nil {
+		return err
+	}
+	return stmt.Model(new(T)).Find(dest).Error
+}
+
+// Count rows matching q; Limit, Offset and OrderBy are ignored
+func (g GenericCRUD[T]) Count(ctx context.Context, q Query) (int64, error) {
+	var count int64
+	q.Limit, q.Offset, q.Preload = 0, 0, nil
+	stmt, err := g.uncapped().smartStmt(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	err = stmt.Model(new(T)).Count(&count).Error
+	return count, err
 }
 
 // SmartQueryOne by non-zero fields of v; returns exactly one Model or error
 func (g GenericCRUD[T]) SmartQueryOne(ctx context.Context, q Query) (*T, error) {
-	res, err := g.SmartQuery(ctx, q)
+	limited := q
+	limited.Limit = 2
+	res, err := g.SmartQuery(ctx, limited)
 	if err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			err = fmt.Errorf("db error: %w", err)
@@ -173,27 +607,197 @@ func (g GenericCRUD[T]) SmartQueryOne(ctx context.Context, q Query) (*T, error)
 		return nil, gorm.ErrRecordNotFound
 	}
 	if len(res) > 1 {
-		return nil, MultipleResultsError
+		return nil, ambiguous(res, func() (int64, error) {
+			return g.Count(ctx, q)
+		})
+	}
+	return res[0], nil
+}
+
+// First returns first Model matching q by q.OrderBy (primary key if empty); unlike SmartQueryOne
+// multiple matches are not an error
+func (g GenericCRUD[T]) First(ctx context.Context, q Query) (*T, error) {
+	return g.first(ctx, q, false)
+}
+
+// Last returns last Model matching q by q.OrderBy (primary key if empty)
+func (g GenericCRUD[T]) Last(ctx context.Context, q Query) (*T, error) {
+	return g.first(ctx, q, true)
+}
+
+func (g GenericCRUD[T]) first(ctx context.Context, q Query, reverse bool) (*T, error) {
+	order := q.OrderBy
+	if len(order) == 0 {
+		s, err := g.schema()
+		if err != nil {
+			return nil, err
+		}
+		order = []OrderClause{{Column: s.PrioritizedPrimaryField.DBName, Direction: ASC}}
+	}
+	q.OrderBy = make([]OrderClause, len(order))
+	for i, o := range order {
+		if reverse {
+			o = o.reverse()
+		}
+		q.OrderBy[i] = o
+	}
+	q.Limit = 1
+	res, err := g.SmartQuery(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, gorm.ErrRecordNotFound
 	}
 	return res[0], nil
 }
 
-// UpdateField of Model; if v has non-zero primary key - filter by primary key
+// schema returns parsed gorm schema of T
+func (g GenericCRUD[T]) schema() (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: g.db}
+	err := stmt.Parse(new(T))
+	return stmt.Schema, err
+}
+
+// PrimaryKeyColumn returns column name of primary key of T
+func (g GenericCRUD[T]) PrimaryKeyColumn() (string, error) {
+	s, err := g.schema()
+	if err != nil {
+		return "", err
+	}
+	if s.PrioritizedPrimaryField == nil {
+		return "", fmt.Errorf("%s has no primary key", s.Name)
+	}
+	return s.PrioritizedPrimaryField.DBName, nil
+}
+
+// FromID returns T with primary key set to id; id may be a string representation
+func (g GenericCRUD[T]) FromID(id any) (T, error) {
+	var v T
+	return g.SetID(v, id)
+}
+
+// SetID returns copy of v with primary key set to id
+func (g GenericCRUD[T]) SetID(v T, id any) (T, error) {
+	s, err := g.schema()
+	if err != nil {
+		return v, err
+	}
+	if s.PrioritizedPrimaryField == nil {
+		return v, fmt.Errorf("%s has no primary key", s.Name)
+	}
+	err = s.PrioritizedPrimaryField.Set(context.Background(), reflect.ValueOf(&v).Elem(), id)
+	return v, err
+}
+
+// Reset returns copy of v with primary keys, auto timestamps and soft delete field zeroed, e.g. to create row
+// from client input with database generated values
+func (g GenericCRUD[T]) Reset(v T) (T, error) {
+	s, err := g.schema()
+	if err != nil {
+		return v, err
+	}
+	err = resetRow(context.Background(), s, reflect.ValueOf(&v).Elem())
+	return v, err
+}
+
+// FromMap returns T with fields set from m keyed by column or field name
+func (g GenericCRUD[T]) FromMap(m map[string]any) (T, error) {
+	var v T
+	s, err := g.schema()
+	if err != nil {
+		return v, err
+	}
+	rv := reflect.ValueOf(&v).Elem()
+	for k, value := range m {
+		field := s.LookUpField(k)
+		if field == nil {
+			return v, fmt.Errorf("%w: unknown column %q", InvalidFilterError, k)
+		}
+		if err = field.Set(context.Background(), rv, value); err != nil {
+			return v, fmt.Errorf("column %s: %w", k, err)
+		}
+	}
+	return v, nil
+}
+
+// UpdateField of Model; if v has non-zero primary key - filter by primary key. See UpdateFieldsMap
 func (g GenericCRUD[T]) UpdateField(ctx context.Context, v T, column string, value any) error {
-	return g.db.Debug().WithContext(ctx).Omit(g.omit...).Model(&v).Update(column, value).Error
+	return g.UpdateFieldsMap(ctx, v, map[string]any{column: value})
+}
+
+// UpdateFieldsMap sets columns of Model to values (zero values included) in a single UPDATE statement;
+// if v has non-zero primary key - filter by primary key. Keys are Go field or column names of T,
+// values may be SQL expressions; unknown columns fail with InvalidFilterError, hidden ones with ForbiddenFieldError
+func (g GenericCRUD[T]) UpdateFieldsMap(ctx context.Context, v T, fields map[string]any) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("%w: no fields to update", InvalidFilterError)
+	}
+	s, err := g.schema()
+	if err != nil {
+		return err
+	}
+	values := make(map[string]any, len(fields))
+	for column, value := range fields {
+		f := s.LookUpField(column)
+		if f == nil || f.DBName == "" {
+			return fmt.Errorf("%w: unknown column %q", InvalidFilterError, column)
+		}
+		if g.hidden(ctx, column) || g.hidden(ctx, f.DBName) {
+			return fmt.Errorf("%w: %s", ForbiddenFieldError, column)
+		}
+		values[f.DBName] = value
+	}
+	if err = g.checkEnumMap(values); err != nil {
+		return err
+	}
+	return g.versioned(ctx, v, HistoryUpdate, func(ctx context.Context, g GenericCRUD[T]) error {
+		return g.sessionOf(ctx, v).Omit(g.omits(ctx, g.cfg.Omit)...).Model(&v).Updates(values).Error
+	})
+}
+
+// UpdateExpr sets column of Model to SQL expression, e.g. gorm.Expr("balance - ?", 10);
+// if v has non-zero primary key - filter by primary key
+func (g GenericCRUD[T]) UpdateExpr(ctx context.Context, v T, column string, expr clause.Expr) error {
+	return g.UpdateField(ctx, v, column, expr)
+}
+
+// Increment column of Model by delta atomically (column = column + delta); delta may be negative
+func (g GenericCRUD[T]) Increment(ctx context.Context, v T, column string, delta int64) error {
+	s, err := g.schema()
+	if err != nil {
+		return err
+	}
+	f := s.LookUpField(column)
+	if f == nil || f.DBName == "" {
+		return fmt.Errorf("%w: unknown column %q", InvalidFilterError, column)
+	}
+	return g.UpdateExpr(ctx, v, f.DBName, gorm.Expr("? + ?", clause.Column{Name: f.DBName}, delta))
 }
 
 // Update if v has non-zero primary key - filter by primary key
 func (g GenericCRUD[T]) Update(ctx context.Context, v T, omit ...string) (err error) {
-	return g.db.Debug().WithContext(ctx).Omit(append(g.omit, omit...)...).Updates(&v).Error
+	if err = g.checkEnums(v); err != nil {
+		return err
+	}
+	return g.versioned(ctx, v, HistoryUpdate, func(ctx context.Context, g GenericCRUD[T]) error {
+		return g.sessionOf(ctx, v).Omit(g.omits(ctx, g.cfg.Omit, omit)...).Updates(&v).Error
+	})
 }
 
 // UpdateMap if v has non-zero primary key - filter by primary key
 func (g GenericCRUD[T]) UpdateMap(ctx context.Context, v T, q map[string]any) error {
-	return g.db.Debug().WithContext(ctx).Model(&v).Updates(q).Error
+	if err := g.checkEnumMap(q); err != nil {
+		return err
+	}
+	return g.versioned(ctx, v, HistoryUpdate, func(ctx context.Context, g GenericCRUD[T]) error {
+		return g.sessionOf(ctx, v).Omit(g.omits(ctx)...).Model(&v).Updates(q).Error
+	})
 }
 
 // Delete if v has non-zero primary key - filter by primary key
 func (g GenericCRUD[T]) Delete(ctx context.Context, v T) error {
-	return g.db.Debug().WithContext(ctx).Delete(&v, v.PrimaryKey()).Error
+	return g.versioned(ctx, v, HistoryDelete, func(ctx context.Context, g GenericCRUD[T]) error {
+		return g.sessionOf(ctx, v).Delete(&v, v.PrimaryKey()).Error
+	})
 }
@@ -1,12 +1,18 @@
 package crud
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/glebarez/sqlite"
 	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -21,16 +27,26 @@ func TestCRUD(t *testing.T) {
 	suite.Run(t, new(testSuite))
 }
 
+// SetupSuite connects to database selected by TEST_DIALECT: postgres (default), mysql or sqlite
 func (s *testSuite) SetupSuite() {
-	db, err := gorm.Open(postgres.Open(fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=%s",
-		"localhost",
-		"postgres",
-		"password",
-		"postgres",
-		5432,
-		"Europe/Kiev",
-	)))
+	var dialector gorm.Dialector
+	switch os.Getenv("TEST_DIALECT") {
+	case "sqlite":
+		dialector = sqlite.Open("file::memory:?cache=shared")
+	case "mysql":
+		dialector = mysql.Open("root:password@tcp(localhost:3306)/test?parseTime=true&loc=Local")
+	default:
+		dialector = postgres.Open(fmt.Sprintf(
+			"host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=%s",
+			"localhost",
+			"postgres",
+			"password",
+			"postgres",
+			5432,
+			"Europe/Kiev",
+		))
+	}
+	db, err := gorm.Open(dialector)
 	s.Require().NoError(err)
 	s.db = db
 	s.crud = New[User](db)
@@ -41,7 +57,7 @@ func (s *testSuite) SetupSuite() {
 		for _, table := range tables {
 			s.Require().NoError(s.db.Debug().Migrator().DropTable(table))
 		}
-		s.Require().NoError(s.db.Debug().AutoMigrate(&User{}))
+		s.Require().NoError(s.db.Debug().AutoMigrate(&User{}, &IdempotencyKey{}, &Owner{}, &Pet{}, &Tag{}, &Category{}))
 	})
 }
 
@@ -54,6 +70,113 @@ var (
 
 func (s *testSuite) TestCRUD() {
 	var user User
+	s.Run("migrate dry run", func() {
+		r := NewRegistry(s.db, Config{})
+		Register[User](r)
+		drift, err := r.MigrateAll(context.TODO(), MigrateOptions{DryRun: true})
+		s.Require().NoError(err)
+		s.Require().Empty(drift)
+	})
+	s.Run("nested transaction", func() {
+		s.Require().False(s.crud.InTransaction())
+		s.Require().ErrorIs(s.crud.Savepoint("sp"), NotInTransactionError)
+		err := s.crud.RunInTransaction(context.TODO(), func(ctx context.Context, tx GenericCRUD[User]) error {
+			s.Require().True(tx.InTransaction())
+			_, err := tx.Create(ctx, User{Name: "tx outer"})
+			s.Require().NoError(err)
+			err = tx.RunInTransaction(ctx, func(ctx context.Context, tx GenericCRUD[User]) error {
+				_, err := tx.Create(ctx, User{Name: "tx inner"})
+				s.Require().NoError(err)
+				return errors.New("rollback inner")
+			})
+			s.Require().Error(err)
+			s.Require().NoError(tx.Savepoint("sp"))
+			_, err = tx.Create(ctx, User{Name: "tx savepoint"})
+			s.Require().NoError(err)
+			return tx.RollbackTo("sp")
+		})
+		s.Require().NoError(err)
+		for name, found := range map[string]bool{"tx outer": true, "tx inner": false, "tx savepoint": false} {
+			v, err := s.crud.Query(context.TODO(), User{Name: name})
+			s.Require().NoError(err)
+			s.Require().Equal(found, len(v) == 1, name)
+		}
+		s.Require().NoError(s.db.Unscoped().Where("name LIKE ?", "tx %").Delete(&User{}).Error)
+	})
+	s.Run("context transaction", func() {
+		err := Transaction(context.TODO(), s.db, func(ctx context.Context) error {
+			_, ok := TxFromContext(ctx)
+			s.Require().True(ok)
+			_, err := s.crud.Create(ctx, User{Name: "tx ctx"})
+			s.Require().NoError(err)
+			return errors.New("rollback")
+		})
+		s.Require().Error(err)
+		v, err := s.crud.Query(context.TODO(), User{Name: "tx ctx"})
+		s.Require().NoError(err)
+		s.Require().Empty(v)
+	})
+	s.Run("unit of work", func() {
+		u := NewUnitOfWork(s.db)
+		first, second := User{Name: "uow first"}, User{Name: "uow second"}
+		StageCreate(u, s.crud, &first)
+		StageCreate(u, s.crud, &second)
+		StageUpdate(u, s.crud, User{Model: gorm.Model{ID: 1 << 30}, Name: "uow missing"})
+		s.Require().Equal(3, u.Len())
+		s.Require().NoError(u.Flush(context.TODO()))
+		s.Require().NotZero(first.ID)
+		s.Require().Zero(u.Len())
+
+		StageDelete(u, s.crud, first)
+		StageCreate(u, s.crud, &second)
+		s.Require().Error(u.Flush(context.TODO()), "duplicate primary key")
+		s.Require().Equal(2, u.Len())
+		_, err := s.crud.GetByID(context.TODO(), first)
+		s.Require().NoError(err)
+		u.Discard()
+		s.Require().Zero(u.Len())
+		s.Require().NoError(s.db.Unscoped().Where("name LIKE ?", "uow %").Delete(&User{}).Error)
+	})
+	s.Run("create idempotent", func() {
+		v1, err := s.crud.CreateIdempotent(context.TODO(), User{Name: "idempotent"}, "key-1")
+		s.Require().NoError(err)
+		v2, err := s.crud.CreateIdempotent(context.TODO(), User{Name: "idempotent retry"}, "key-1")
+		s.Require().NoError(err)
+		s.Require().Equal(v1.ID, v2.ID)
+		s.Require().Equal("idempotent", v2.Name)
+		v3, err := s.crud.CreateIdempotent(context.TODO(), User{Name: "idempotent"}, "key-2")
+		s.Require().NoError(err)
+		s.Require().NotEqual(v1.ID, v3.ID)
+		s.Require().NoError(s.db.Unscoped().Where("name LIKE ?", "idempotent%").Delete(&User{}).Error)
+	})
+	s.Run("purge", func() {
+		v, err := s.crud.Create(context.TODO(), User{Name: "purge"})
+		s.Require().NoError(err)
+		s.Require().NoError(s.crud.Delete(context.TODO(), *v))
+		n, err := s.crud.Purge(context.TODO(), time.Hour)
+		s.Require().NoError(err)
+		s.Require().Zero(n)
+		s.Require().NoError(s.db.Unscoped().Model(v).Update("deleted_at", time.Now().Add(-2*time.Hour)).Error)
+		n, err = s.crud.Purge(context.TODO(), time.Hour)
+		s.Require().NoError(err)
+		s.Require().Equal(int64(1), n)
+		s.Require().ErrorIs(s.db.Unscoped().Take(&User{}, v.ID).Error, gorm.ErrRecordNotFound)
+	})
+	s.Run("anonymize", func() {
+		v, err := s.crud.Create(context.TODO(), User{Name: "pii", Age: a1})
+		s.Require().NoError(err)
+		s.Require().NoError(s.crud.Delete(context.TODO(), *v))
+		s.Require().NoError(s.crud.Anonymize(context.TODO(), *v, map[string]any{"name": "anonymous", "age": nil}))
+		var u User
+		s.Require().NoError(s.db.Unscoped().Take(&u, v.ID).Error)
+		s.Require().Equal("anonymous", u.Name)
+		s.Require().False(u.Age.Valid)
+		s.Require().ErrorIs(s.crud.Anonymize(context.TODO(), *v, map[string]any{"ssn": ""}), InvalidFilterError)
+	})
+	s.Run("health", func() {
+		s.Require().NoError(s.crud.Health(context.TODO()))
+		s.T().Logf("%+v", s.crud.Stats())
+	})
 	s.Run("create", func() {
 		v, err := s.crud.Create(context.TODO(), User{Name: "test", Age: a1})
 		s.NoError(err)
@@ -127,6 +250,16 @@ func (s *testSuite) TestCRUD() {
 			})
 		}
 	})
+	s.Run("ambiguous result", func() {
+		_, err := s.crud.QueryOne(context.TODO(), User{})
+		s.Require().ErrorIs(err, MultipleResultsError)
+		var ambiguous AmbiguousResultError
+		s.Require().ErrorAs(err, &ambiguous)
+		count, err := s.crud.Count(context.TODO(), Query{})
+		s.Require().NoError(err)
+		s.EqualValues(count, ambiguous.Count)
+		s.Len(ambiguous.IDs, 2)
+	})
 	s.Run("update field", func() {
 		err := s.crud.UpdateField(context.TODO(), user, "name", "test!")
 		s.Require().NoError(err)
@@ -135,6 +268,10 @@ func (s *testSuite) TestCRUD() {
 		err := s.crud.UpdateField(context.TODO(), user, "age", 111)
 		s.Require().NoError(err)
 	})
+	s.Run("update fields map", func() {
+		err := s.crud.UpdateFieldsMap(context.TODO(), user, map[string]any{"name": "test!", "Age": 111})
+		s.Require().NoError(err)
+	})
 	s.Run("query", func() {
 		_, err := s.crud.QueryOne(context.TODO(), user)
 		s.Require().ErrorIs(err, gorm.ErrRecordNotFound)
@@ -179,29 +316,34 @@ func (s *testSuite) TestCRUD() {
 		s.Require().Equal(a4, v.Age)
 		s.Require().Equal("test!!", v.Name)
 	})
+	s.Run("smart query equal", func() {
+		v, err := s.crud.SmartQueryOne(context.TODO(), Query{Equal: map[string]any{"id": user.ID, "age": a4}})
+		s.Require().NoError(err)
+		s.Require().Equal("test!!", v.Name)
+	})
 	s.Run("smart query", func() {
-		v, err := s.crud.SmartQuery(context.TODO(), Query{OrderBy: map[string]OrderBy{"id": ASC}})
+		v, err := s.crud.SmartQuery(context.TODO(), Query{OrderBy: []OrderClause{{Column: "id", Direction: ASC}}})
 		s.Require().NoError(err)
 		for i, u := range v {
 			s.T().Log(i, u)
 		}
 	})
 	s.Run("smart query", func() {
-		v, err := s.crud.SmartQuery(context.TODO(), Query{OrderBy: map[string]OrderBy{"id": DESC}})
+		v, err := s.crud.SmartQuery(context.TODO(), Query{OrderBy: []OrderClause{{Column: "id", Direction: DESC}}})
 		s.Require().NoError(err)
 		for i, u := range v {
 			s.T().Log(i, u)
 		}
 	})
 	s.Run("smart query", func() {
-		v, err := s.crud.SmartQuery(context.TODO(), Query{OrderBy: map[string]OrderBy{"created_at": ASC}})
+		v, err := s.crud.SmartQuery(context.TODO(), Query{OrderBy: []OrderClause{{Column: "created_at", Direction: ASC}}})
 		s.Require().NoError(err)
 		for i, u := range v {
 			s.T().Log(i, u)
 		}
 	})
 	s.Run("smart query", func() {
-		v, err := s.crud.SmartQuery(context.TODO(), Query{OrderBy: map[string]OrderBy{"created_at": DESC}})
+		v, err := s.crud.SmartQuery(context.TODO(), Query{OrderBy: []OrderClause{{Column: "created_at", Direction: DESC}}})
 		s.Require().NoError(err)
 		for i, u := range v {
 			s.T().Log(i, u)
@@ -209,7 +351,7 @@ func (s *testSuite) TestCRUD() {
 	})
 	s.Run("smart query", func() {
 		v, err := s.crud.SmartQuery(context.TODO(), Query{
-			OrderBy: map[string]OrderBy{"created_at": DESC},
+			OrderBy: []OrderClause{{Column: "created_at", Direction: DESC}},
 			Like:    map[string]string{"name": "test"},
 		})
 		s.Require().NoError(err)
@@ -219,7 +361,7 @@ func (s *testSuite) TestCRUD() {
 	})
 	s.Run("smart query", func() {
 		v, err := s.crud.SmartQuery(context.TODO(), Query{
-			OrderBy: map[string]OrderBy{"created_at": DESC},
+			OrderBy: []OrderClause{{Column: "created_at", Direction: DESC}},
 			Like:    map[string]string{"name": "test"},
 			Equal:   map[string]any{"name": "test2"},
 		})
@@ -230,10 +372,10 @@ func (s *testSuite) TestCRUD() {
 	})
 	s.Run("smart query", func() {
 		v, err := s.crud.SmartQuery(context.TODO(), Query{
-			OrderBy: map[string]OrderBy{"created_at": DESC},
+			OrderBy: []OrderClause{{Column: "created_at", Direction: DESC}},
 			Like:    map[string]string{"name": "test"},
 			Equal:   map[string]any{"name": "test2"},
-			Between: map[string]struct{ From, To any }{"created_at": {
+			Between: map[string]Between{"created_at": {
 				From: time.Date(2023, 1, 23, 0, 0, 0, 0, time.Local),
 				To:   time.Date(2023, 1, 24, 0, 0, 0, 0, time.Local),
 			}},
@@ -243,6 +385,336 @@ func (s *testSuite) TestCRUD() {
 			s.T().Log(i, u)
 		}
 	})
+	s.Run("export", func() {
+		q := Query{Equal: map[string]any{"name": "test2"}}
+		var buf bytes.Buffer
+		s.Require().NoError(s.crud.Export(context.TODO(), q, ExportFormat{Type: CSV, Columns: []string{"name", "age"}}, &buf))
+		s.Require().Equal("name,age\ntest2,12\n", buf.String())
+		buf.Reset()
+		s.Require().NoError(s.crud.Export(context.TODO(), q, ExportFormat{Type: JSONL, Columns: []string{"name"}}, &buf))
+		s.Require().Equal(`{"name":"test2"}`+"\n", buf.String())
+	})
+	s.Run("create many copy", func() {
+		c := NewWithConfig[User](s.db, Config{CopyThreshold: 2})
+		err := c.CreateMany(context.TODO(), []User{{Name: "copy1"}, {Name: "copy2"}, {Name: "copy3"}}, 0)
+		s.Require().NoError(err)
+		n, err := c.Count(context.TODO(), Query{Like: map[string]string{"name": "copy%"}})
+		s.Require().NoError(err)
+		s.Require().Equal(int64(3), n)
+	})
+	s.Run("explain", func() {
+		plan, err := s.crud.Explain(context.TODO(), Query{Equal: map[string]any{"name": "explain"}})
+		s.Require().NoError(err)
+		s.Require().NotEmpty(plan)
+		plan, err = s.crud.ExplainAnalyze(context.TODO(), Query{Equal: map[string]any{"name": "explain"}})
+		s.Require().NoError(err)
+		s.Require().NotEmpty(plan)
+	})
+	s.Run("clone", func() {
+		owners := New[Owner](s.db)
+		owner, err := owners.Create(context.TODO(), Owner{
+			Name: "template",
+			Pets: []Pet{{Name: "a"}, {Name: "b"}},
+			Tags: []Tag{{Name: "x"}},
+		})
+		s.Require().NoError(err)
+
+		clone, err := owners.Clone(context.TODO(), Owner{Model: gorm.Model{ID: owner.ID}}, map[string]any{"name": "copy"}, "Pets", "Tags")
+		s.Require().NoError(err)
+		s.Require().NotEqual(owner.ID, clone.ID)
+		s.Require().Equal("copy", clone.Name)
+
+		loaded := Owner{Model: gorm.Model{ID: clone.ID}}
+		s.Require().NoError(s.db.Preload("Pets").Preload("Tags").Take(&loaded).Error)
+		s.Require().Len(loaded.Pets, 2)
+		s.Require().NotEqual(owner.Pets[0].ID, loaded.Pets[0].ID)
+		s.Require().Len(loaded.Tags, 1)
+		s.Require().Equal(owner.Tags[0].ID, loaded.Tags[0].ID)
+
+		_, err = owners.Clone(context.TODO(), *owner, map[string]any{"unknown": 1})
+		s.Require().ErrorIs(err, InvalidFilterError)
+	})
+	s.Run("delete cascade", func() {
+		owners := New[Owner](s.db)
+		owner, err := owners.Create(context.TODO(), Owner{
+			Name: "cascade",
+			Pets: []Pet{{Name: "a"}, {Name: "b"}},
+			Tags: []Tag{{Name: "x"}},
+		})
+		s.Require().NoError(err)
+
+		_, err = owners.DeleteCascade(context.TODO(), *owner, "Unknown")
+		s.Require().ErrorIs(err, InvalidFilterError)
+
+		report, err := owners.DeleteCascadeDryRun(context.TODO(), *owner, "Pets", "Tags")
+		s.Require().NoError(err)
+		s.Require().Equal(CascadeReport{"owners": 1, "pets": 2, "owner_tags": 1}, report)
+
+		report, err = owners.DeleteCascade(context.TODO(), *owner, "Pets", "Tags")
+		s.Require().NoError(err)
+		s.Require().Equal(CascadeReport{"owners": 1, "pets": 2, "owner_tags": 1}, report)
+		pets, err := New[Pet](s.db).Query(context.TODO(), Pet{OwnerID: owner.ID})
+		s.Require().NoError(err)
+		s.Require().Empty(pets)
+		_, err = owners.GetByID(context.TODO(), *owner)
+		s.Require().ErrorIs(err, gorm.ErrRecordNotFound)
+	})
+	s.Run("apply json patch", func() {
+		u, err := s.crud.Create(context.TODO(), User{Name: "patch", Age: sql.NullInt16{Int16: 1, Valid: true}})
+		s.Require().NoError(err)
+
+		res, err := s.crud.ApplyJSONPatch(context.TODO(), u.ID, []byte(`[{"op":"replace","path":"/name","value":"patched"},{"op":"replace","path":"/age","value":5}]`), JSONPatch)
+		s.Require().NoError(err)
+		s.Require().Equal("patched", res.Name)
+		s.Require().Equal(sql.NullInt16{Int16: 5, Valid: true}, res.Age)
+
+		res, err = s.crud.ApplyJSONPatch(context.TODO(), u.ID, []byte(`{"age":null}`), MergePatch)
+		s.Require().NoError(err)
+		s.Require().False(res.Age.Valid)
+		s.Require().Equal("patched", res.Name)
+
+		_, err = s.crud.ApplyJSONPatch(context.TODO(), u.ID, []byte(`{"id":100}`), MergePatch)
+		s.Require().ErrorIs(err, ForbiddenFieldError)
+		_, err = s.crud.ApplyJSONPatch(context.TODO(), u.ID, []byte(`[{"op":"test","path":"/name","value":"x"}]`), JSONPatch)
+		s.Require().ErrorIs(err, PatchError)
+	})
+	s.Run("history", func() {
+		users := s.crud.WithHistory()
+		s.Require().NoError(users.MigrateHistory(context.TODO()))
+		u, err := users.Create(context.TODO(), User{Name: "v1"})
+		s.Require().NoError(err)
+
+		defer func() { now = time.Now }()
+		t1 := time.Now().Add(time.Hour)
+		now = func() time.Time { return t1 }
+		s.Require().NoError(users.UpdateField(context.TODO(), *u, "name", "v2"))
+		now = func() time.Time { return t1.Add(time.Hour) }
+		s.Require().NoError(users.Delete(context.TODO(), *u))
+
+		versions, err := users.History(context.TODO(), u.ID)
+		s.Require().NoError(err)
+		s.Require().Len(versions, 2)
+		s.Require().Equal("v1", versions[0].Value.Name)
+		s.Require().Equal(HistoryUpdate, versions[0].Operation)
+		s.Require().Equal("v2", versions[1].Value.Name)
+		s.Require().Equal(HistoryDelete, versions[1].Operation)
+		s.Require().True(versions[1].ValidFrom.Equal(versions[0].ValidTo))
+
+		v, err := users.AsOf(context.TODO(), u.ID, t1.Add(-time.Minute))
+		s.Require().NoError(err)
+		s.Require().Equal("v1", v.Name)
+		v, err = users.AsOf(context.TODO(), u.ID, t1.Add(time.Minute))
+		s.Require().NoError(err)
+		s.Require().Equal("v2", v.Name)
+		_, err = users.AsOf(context.TODO(), u.ID, t1.Add(2*time.Hour))
+		s.Require().ErrorIs(err, gorm.ErrRecordNotFound)
+		_, err = users.AsOf(context.TODO(), u.ID, u.CreatedAt.Add(-time.Minute))
+		s.Require().ErrorIs(err, gorm.ErrRecordNotFound)
+
+		s.Require().NoError(users.Anonymize(context.TODO(), *u, map[string]any{"name": "anonymous", "age": nil}))
+		versions, err = users.History(context.TODO(), u.ID)
+		s.Require().NoError(err)
+		s.Require().Len(versions, 2)
+		for _, version := range versions {
+			s.Require().Equal("anonymous", version.Value.Name)
+		}
+		table, err := users.HistoryTable()
+		s.Require().NoError(err)
+		var n int64
+		s.Require().NoError(s.db.Table(table).Where("entity_id = ? AND data LIKE ?", fmt.Sprint(u.ID), "%v1%").Count(&n).Error)
+		s.Require().Zero(n)
+	})
+	s.Run("increment", func() {
+		u, err := s.crud.Create(context.TODO(), User{Name: "increment", Age: sql.NullInt16{Int16: 10, Valid: true}})
+		s.Require().NoError(err)
+		s.Require().NoError(s.crud.Increment(context.TODO(), *u, "age", 5))
+		s.Require().NoError(s.crud.Increment(context.TODO(), *u, "age", -3))
+		u, err = s.crud.GetByID(context.TODO(), *u)
+		s.Require().NoError(err)
+		s.Require().Equal(int16(12), u.Age.Int16)
+	})
+	s.Run("update if", func() {
+		u, err := s.crud.Create(context.TODO(), User{Name: "pending"})
+		s.Require().NoError(err)
+		n, err := s.crud.UpdateIf(context.TODO(), *u, map[string]any{"name": "done"}, Query{Equal: map[string]any{"name": "pending"}})
+		s.Require().NoError(err)
+		s.Require().Equal(int64(1), n)
+		n, err = s.crud.UpdateIf(context.TODO(), *u, map[string]any{"name": "done"}, Query{Equal: map[string]any{"name": "pending"}})
+		s.Require().NoError(err)
+		s.Require().Zero(n)
+	})
+	s.Run("state machine", func() {
+		m := NewStateMachine(s.crud, "name", map[string][]string{
+			"new":  {"paid", "cancelled"},
+			"paid": {"shipped"},
+		})
+		u, err := s.crud.Create(context.TODO(), User{Name: "new"})
+		s.Require().NoError(err)
+
+		var invalid InvalidTransitionError[string]
+		s.Require().ErrorAs(m.Transition(context.TODO(), *u, "new", "shipped", nil), &invalid)
+		s.Require().Equal("shipped", invalid.To)
+		s.Require().NoError(m.Transition(context.TODO(), *u, "new", "paid", map[string]any{"age": 1}))
+		s.Require().ErrorIs(m.Transition(context.TODO(), *u, "new", "cancelled", nil), StateConflictError)
+
+		u, err = s.crud.GetByID(context.TODO(), *u)
+		s.Require().NoError(err)
+		s.Require().Equal("paid", u.Name)
+		s.Require().Equal(int16(1), u.Age.Int16)
+		s.Require().ElementsMatch([]string{"paid", "cancelled"}, m.Next("new"))
+	})
+	s.Run("tree", func() {
+		categories := New[Category](s.db)
+		tree := NewTree(categories, "parent_id")
+		create := func(name string, parent *Category) *Category {
+			c := Category{Name: name}
+			if parent != nil {
+				c.ParentID = &parent.ID
+			}
+			res, err := categories.Create(context.TODO(), c)
+			s.Require().NoError(err)
+			return res
+		}
+		names := func(res []*Category, err error) []string {
+			s.Require().NoError(err)
+			var names []string
+			for _, c := range res {
+				names = append(names, c.Name)
+			}
+			return names
+		}
+		root := create("root", nil)
+		a := create("a", root)
+		b := create("b", root)
+		a1 := create("a1", a)
+		a11 := create("a11", a1)
+
+		s.Require().Equal([]string{"root"}, names(tree.Roots(context.TODO())))
+		s.Require().ElementsMatch([]string{"a", "b"}, names(tree.Children(context.TODO(), root.ID)))
+		s.Require().Equal([]string{"a", "b", "a1", "a11"}, names(tree.Descendants(context.TODO(), root.ID, 0)))
+		s.Require().Equal([]string{"a", "b", "a1"}, names(tree.Descendants(context.TODO(), root.ID, 2)))
+		s.Require().Equal([]string{"a1", "a", "root"}, names(tree.Ancestors(context.TODO(), a11.ID)))
+
+		s.Require().ErrorIs(tree.MoveSubtree(context.TODO(), a.ID, a11.ID), TreeCycleError)
+		s.Require().ErrorIs(tree.MoveSubtree(context.TODO(), a.ID, a.ID), TreeCycleError)
+		s.Require().NoError(tree.MoveSubtree(context.TODO(), a1.ID, b.ID))
+		s.Require().Equal([]string{"a1", "a11"}, names(tree.Descendants(context.TODO(), b.ID, 0)))
+		s.Require().NoError(tree.MoveSubtree(context.TODO(), b.ID, nil))
+		s.Require().ElementsMatch([]string{"root", "b"}, names(tree.Roots(context.TODO())))
+	})
+	s.Run("path tree", func() {
+		categories := New[Category](s.db)
+		tree := NewPathTree(categories, "path")
+		create := func(name string, parent *Category) *Category {
+			var parentID any
+			if parent != nil {
+				parentID = parent.ID
+			}
+			res, err := tree.Create(context.TODO(), Category{Name: name}, parentID)
+			s.Require().NoError(err)
+			return res
+		}
+		names := func(res []*Category, err error) []string {
+			s.Require().NoError(err)
+			var names []string
+			for _, c := range res {
+				names = append(names, c.Name)
+			}
+			return names
+		}
+		root := create("proot", nil)
+		a := create("pa", root)
+		b := create("pb", root)
+		a1 := create("pa1", a)
+		s.Require().Equal(fmt.Sprintf("/%d/%d/%d/", root.ID, a.ID, a1.ID), a1.Path)
+
+		s.Require().Equal([]string{"pa", "pa1", "pb"}, names(tree.Descendants(context.TODO(), root.ID)))
+		s.Require().Equal([]string{"proot", "pa"}, names(tree.Ancestors(context.TODO(), a1.ID)))
+		s.Require().Equal([]string{"pa", "pa1"}, names(categories.SmartQuery(context.TODO(), tree.Within(Query{
+			OrderBy: []OrderClause{{Column: "path", Direction: ASC}},
+		}, a.Path))))
+
+		s.Require().ErrorIs(tree.Move(context.TODO(), a.ID, a1.ID), TreeCycleError)
+		s.Require().NoError(tree.Move(context.TODO(), a.ID, b.ID))
+		path, err := tree.Path(context.TODO(), a1.ID)
+		s.Require().NoError(err)
+		s.Require().Equal(fmt.Sprintf("/%d/%d/%d/%d/", root.ID, b.ID, a.ID, a1.ID), path)
+	})
+	s.Run("get by ids ordered", func() {
+		a, err := s.crud.Create(context.TODO(), User{Name: "ordered a"})
+		s.Require().NoError(err)
+		b, err := s.crud.Create(context.TODO(), User{Name: "ordered b"})
+		s.Require().NoError(err)
+		ids := []any{b.ID, uint(1 << 30), fmt.Sprint(a.ID)}
+		for i := 0; i < 2000; i++ {
+			ids = append(ids, uint(1<<30+1+i))
+		}
+		res, err := s.crud.GetByIDsOrdered(context.TODO(), ids)
+		s.Require().ErrorIs(err, gorm.ErrRecordNotFound)
+		var missing MissingIDsError
+		s.Require().ErrorAs(err, &missing)
+		s.Len(missing.IDs, 2001)
+		s.Require().Len(res, len(ids))
+		s.Equal("ordered b", res[0].Name)
+		s.Nil(res[1])
+		s.Equal("ordered a", res[2].Name)
+	})
+	s.Run("join crud", func() {
+		owners, tags := New[Owner](s.db), New[Tag](s.db)
+		owner, err := owners.Create(context.TODO(), Owner{Name: "joined"})
+		s.Require().NoError(err)
+		tag, err := tags.Create(context.TODO(), Tag{Name: "joined"})
+		s.Require().NoError(err)
+		j := NewJoinCRUD(owners, tags, "owner_tags", "owner_id", "tag_id")
+		s.Require().NoError(j.Link(context.TODO(), *owner, *tag, nil))
+		s.Require().NoError(j.Link(context.TODO(), *owner, *tag, nil))
+		linked, err := j.Linked(context.TODO(), *owner, *tag)
+		s.Require().NoError(err)
+		s.True(linked)
+		right, err := j.ListRight(context.TODO(), *owner, Query{})
+		s.Require().NoError(err)
+		s.Require().Len(right, 1)
+		s.Equal(tag.ID, right[0].ID)
+		left, err := j.ListLeft(context.TODO(), *tag, Query{Equal: map[string]any{"name": "other"}})
+		s.Require().NoError(err)
+		s.Empty(left)
+		removed, err := j.Unlink(context.TODO(), *owner, *tag)
+		s.Require().NoError(err)
+		s.True(removed)
+		removed, err = j.Unlink(context.TODO(), *owner, *tag)
+		s.Require().NoError(err)
+		s.False(removed)
+	})
+	s.Run("import", func() {
+		report, err := s.crud.Import(context.TODO(), strings.NewReader("name,age\nimport1,1\nimport2,x\nimport3,\n"), ImportOptions[User]{
+			Type: CSV,
+			Validate: func(v User) error {
+				if v.Name == "import3" {
+					return errors.New("invalid")
+				}
+				return nil
+			},
+		})
+		s.Require().NoError(err)
+		s.Require().Equal(3, report.Rows)
+		s.Require().Equal(1, report.Imported)
+		s.Require().Len(report.Errors, 2)
+		s.Require().Equal(2, report.Errors[0].Row)
+		s.Require().Equal(3, report.Errors[1].Row)
+
+		v, err := s.crud.QueryOne(context.TODO(), User{Name: "import1"})
+		s.Require().NoError(err)
+		report, err = s.crud.Import(context.TODO(), strings.NewReader(fmt.Sprintf(`{"id":%d,"name":"import1","age":2}`, v.ID)), ImportOptions[User]{
+			Type:          JSONL,
+			Upsert:        true,
+			UpdateColumns: []string{"age"},
+		})
+		s.Require().NoError(err)
+		s.Require().Equal(1, report.Imported, report.Errors)
+		v, err = s.crud.GetByID(context.TODO(), *v)
+		s.Require().NoError(err)
+		s.Require().Equal(int16(2), v.Age.Int16)
+	})
 }
 
 type User struct {
@@ -254,3 +726,44 @@ type User struct {
 func (u User) PrimaryKey() any {
 	return u.Model.ID
 }
+
+type Owner struct {
+	gorm.Model
+	Name string
+	Pets []Pet
+	Tags []Tag `gorm:"many2many:owner_tags"`
+}
+
+func (o Owner) PrimaryKey() any {
+	return o.ID
+}
+
+type Pet struct {
+	gorm.Model
+	OwnerID uint
+	Name    string
+}
+
+func (p Pet) PrimaryKey() any {
+	return p.ID
+}
+
+type Tag struct {
+	gorm.Model
+	Name string
+}
+
+func (t Tag) PrimaryKey() any {
+	return t.ID
+}
+
+type Category struct {
+	gorm.Model
+	Name     string
+	ParentID *uint
+	Path     string `gorm:"index"`
+}
+
+func (c Category) PrimaryKey() any {
+	return c.ID
+}
@@ -0,0 +1,94 @@
+package crud
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Dialect generates SQL fragments that differ between databases
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	MySQL    Dialect = "mysql"
+	SQLite   Dialect = "sqlite"
+)
+
+// DialectOf returns Dialect of db
+func DialectOf(db *gorm.DB) Dialect {
+	return Dialect(db.Dialector.Name())
+}
+
+// Dialect returns Dialect of g's database
+func (g GenericCRUD[T]) Dialect() Dialect {
+	return DialectOf(g.db)
+}
+
+// Like returns LIKE condition for column matching pattern; insensitive match is ILIKE on Postgres
+// and LOWER() comparison elsewhere; escaped declares backslash as escape character of pattern
+func (d Dialect) Like(column, pattern string, insensitive, escaped bool) clause.Expr {
+	var sql string
+	switch {
+	case !insensitive:
+		sql = column + " LIKE ?"
+	case d == Postgres:
+		sql = column + " ILIKE ?"
+	default:
+		sql = "LOWER(" + column + ") LIKE LOWER(?)"
+	}
+	// Postgres and MySQL treat backslash as escape character by default
+	if escaped && d == SQLite {
+		sql += ` ESCAPE '\'`
+	}
+	return clause.Expr{SQL: sql, Vars: []any{pattern}}
+}
+
+// SupportsFullText reports whether FullText queries are supported
+func (d Dialect) SupportsFullText() bool {
+	return d == Postgres || d == MySQL
+}
+
+// Random returns expression ordering rows randomly
+func (d Dialect) Random() string {
+	if d == MySQL {
+		return "RAND()"
+	}
+	return "RANDOM()"
+}
+
+// order returns ordering of validated o, optionally by collation; MySQL lacks NULLS FIRST/LAST
+// and orders by IS NULL first instead
+func (d Dialect) order(o OrderClause, collation string) clause.Expr {
+	c := clause.Column{Name: o.Column}
+	value, vars := "?", []any{c}
+	if o.Func != "" {
+		value = strings.ToUpper(o.Func) + "(?)"
+	}
+	if collation != "" {
+		value += " COLLATE ?"
+		vars = append(vars, clause.Column{Name: collation})
+	}
+	sql := value + " " + o.direction().String()
+	switch {
+	case !o.NullsFirst && !o.NullsLast:
+		return clause.Expr{SQL: sql, Vars: vars}
+	case d == MySQL && o.NullsFirst:
+		return clause.Expr{SQL: "? IS NULL DESC, " + sql, Vars: append([]any{c}, vars...)}
+	case d == MySQL:
+		return clause.Expr{SQL: "? IS NULL, " + sql, Vars: append([]any{c}, vars...)}
+	case o.NullsFirst:
+		return clause.Expr{SQL: sql + " NULLS FIRST", Vars: vars}
+	default:
+		return clause.Expr{SQL: sql + " NULLS LAST", Vars: vars}
+	}
+}
+
+// idsPerQuery returns number of ids bound per query, below parameter limit of dialect
+func (d Dialect) idsPerQuery() int {
+	if d == SQLite {
+		return 900
+	}
+	return 10000
+}
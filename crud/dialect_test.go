@@ -0,0 +1,25 @@
+package crud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialectLike(t *testing.T) {
+	for _, c := range []struct {
+		d                    Dialect
+		insensitive, escaped bool
+		sql                  string
+	}{
+		{Postgres, false, false, "name LIKE ?"},
+		{Postgres, true, true, "name ILIKE ?"},
+		{MySQL, true, true, "LOWER(name) LIKE LOWER(?)"},
+		{SQLite, false, true, `name LIKE ? ESCAPE '\'`},
+		{SQLite, true, false, "LOWER(name) LIKE LOWER(?)"},
+	} {
+		require.Equal(t, c.sql, c.d.Like("name", "%x%", c.insensitive, c.escaped).SQL, c)
+	}
+	require.True(t, Postgres.SupportsFullText())
+	require.False(t, SQLite.SupportsFullText())
+}
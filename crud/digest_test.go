@@ -0,0 +1,44 @@
+package crud
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestDigest(t *testing.T) {
+	ctx := context.TODO()
+	primary, replica := New[User](benchDB(t, "digest_primary")), New[User](benchDB(t, "digest_replica"))
+	for _, g := range []GenericCRUD[User]{replica, primary} {
+		for _, name := range []string{"ann", "bob"} {
+			_, err := g.Create(ctx, User{Name: name, Age: sql.NullInt16{Int16: 30, Valid: name == "bob"}})
+			require.NoError(t, err)
+		}
+	}
+
+	a, err := primary.Digest(ctx, Query{}, "id", "name", "age")
+	require.NoError(t, err)
+	require.Len(t, a, 64)
+	b, err := replica.Digest(ctx, Query{}, "ID", "Name", "Age")
+	require.NoError(t, err)
+	require.Equal(t, a, b)
+	all, err := primary.Digest(ctx, Query{})
+	require.NoError(t, err)
+	require.NotEqual(t, a, all, "timestamps differ")
+
+	require.NoError(t, replica.UpdateField(ctx, User{Model: gorm.Model{ID: 2}}, "age", nil))
+	b, err = replica.Digest(ctx, Query{}, "id", "name", "age")
+	require.NoError(t, err)
+	require.NotEqual(t, a, b)
+	a, err = primary.Digest(ctx, Query{Equal: map[string]any{"name": "ann"}}, "id", "name", "age")
+	require.NoError(t, err)
+	b, err = replica.Digest(ctx, Query{Limit: 1}, "id", "name", "age")
+	require.NoError(t, err)
+	require.Equal(t, a, b)
+
+	_, err = primary.Digest(ctx, Query{}, "email")
+	require.ErrorIs(t, err, InvalidFilterError)
+}
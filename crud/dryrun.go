@@ -0,0 +1,107 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Statement is SQL with bound vars built in dry-run mode
+type Statement struct {
+	SQL  string
+	Vars []any
+
+	explain func(sql string, vars ...any) string
+}
+
+// String returns SQL with vars inlined by dialect; for debugging only, it is not safe to execute
+func (s Statement) String() string {
+	if s.explain == nil {
+		return s.SQL
+	}
+	return s.explain(s.SQL, s.Vars...)
+}
+
+// WithDryRun returns copy of g building statements without executing them; record receives every statement.
+// Reads find nothing and writes affect no rows, including history, idempotency keys and join tables;
+// g doesn't open transactions and doesn't use COPY
+func (g GenericCRUD[T]) WithDryRun(record func(Statement)) GenericCRUD[T] {
+	g.cfg.DryRun = record
+	return g
+}
+
+// DryRun calls fn with copy of g in dry-run mode and returns statements fn would execute, e.g.
+//
+//	stmts, err := users.DryRun(ctx, func(ctx context.Context, users crud.GenericCRUD[User]) error {
+//		_, err := users.SmartQuery(ctx, q)
+//		return err
+//	})
+//
+// gorm.ErrRecordNotFound of reads finding nothing is not returned
+func (g GenericCRUD[T]) DryRun(ctx context.Context, fn func(ctx context.Context, g GenericCRUD[T]) error) ([]Statement, error) {
+	var (
+		mu  sync.Mutex
+		res []Statement
+	)
+	err := fn(ctx, g.WithDryRun(func(s Statement) {
+		mu.Lock()
+		defer mu.Unlock()
+		res = append(res, s)
+	}))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return res, err
+}
+
+// dryRunLogger marks dry-run sessions; subqueries built into statements get discarding logger, so they are not recorded
+type dryRunLogger struct {
+	logger.Interface
+	record func(Statement)
+}
+
+// LogMode keeps dry-run recording on logger with changed level
+func (l dryRunLogger) LogMode(level logger.LogLevel) logger.Interface {
+	l.Interface = l.Interface.LogMode(level)
+	return l
+}
+
+// withDryRun enables dry-run mode in session of db
+func (g GenericCRUD[T]) withDryRun(db *gorm.DB, session *gorm.Session) {
+	session.DryRun, session.SkipDefaultTransaction = true, true
+	if session.Logger == nil {
+		session.Logger = db.Logger
+	}
+	session.Logger = dryRunLogger{Interface: session.Logger, record: g.cfg.DryRun}
+}
+
+// registerDryRun adds callbacks recording dry-run statements to db; see Setup
+func registerDryRun(db *gorm.DB) {
+	const name = "crud:dry_run"
+	if db.Callback().Query().Get(name) != nil {
+		return
+	}
+	cb := db.Callback()
+	_ = cb.Query().After("gorm:after_query").Register(name, recordStatement)
+	_ = cb.Create().After("gorm:after_create").Register(name, recordStatement)
+	_ = cb.Update().After("gorm:after_update").Register(name, recordStatement)
+	_ = cb.Delete().After("gorm:after_delete").Register(name, recordStatement)
+	_ = cb.Row().After("gorm:row").Register(name, recordStatement)
+	_ = cb.Raw().After("gorm:raw").Register(name, recordStatement)
+}
+
+// recordStatement passes statement of dry-run session to its recorder
+func recordStatement(db *gorm.DB) {
+	l, ok := db.Logger.(dryRunLogger)
+	if !ok || !db.DryRun || db.Statement.SQL.Len() == 0 {
+		return
+	}
+	l.record(Statement{
+		SQL:     db.Statement.SQL.String(),
+		Vars:    append([]any(nil), db.Statement.Vars...),
+		explain: db.Dialector.Explain,
+	})
+}
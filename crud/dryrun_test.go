@@ -0,0 +1,74 @@
+package crud
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestDryRun(t *testing.T) {
+	db := benchDB(t, "dryrun")
+	g := New[User](db).WithNotFoundCache(time.Minute)
+	stmts, err := g.DryRun(context.TODO(), func(ctx context.Context, g GenericCRUD[User]) error {
+		if _, err := g.Create(ctx, User{Name: "dry"}); err != nil {
+			return err
+		}
+		if _, err := g.GetByID(ctx, User{Model: gorm.Model{ID: 1}}); err != nil {
+			return err
+		}
+		_, err := g.SmartQuery(ctx, Query{
+			InQuery: map[string]Subquery{"id": g.Subquery(Query{Equal: map[string]any{"name": "dry"}}, "id")},
+		})
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, stmts, 3)
+	require.Contains(t, stmts[0].SQL, "INSERT INTO `users`")
+	require.Contains(t, stmts[1].String(), "WHERE `users`.`id` = 1")
+	require.Contains(t, stmts[2].SQL, "WHERE id IN (SELECT `id` FROM `users` WHERE name = ?")
+	require.Equal(t, []any{"dry"}, stmts[2].Vars)
+
+	n, err := g.Count(context.TODO(), Query{})
+	require.NoError(t, err)
+	require.Zero(t, n)
+	_, err = g.GetByID(context.TODO(), User{Model: gorm.Model{ID: 1}})
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestDryRunSideTables(t *testing.T) {
+	ctx := context.TODO()
+	db := benchDB(t, "dryrun_side")
+	require.NoError(t, db.AutoMigrate(&IdempotencyKey{}))
+	g := New[User](db).WithHistory()
+	require.NoError(t, g.MigrateHistory(ctx))
+	u, err := g.Create(ctx, User{Name: "ann"})
+	require.NoError(t, err)
+
+	stmts, err := g.DryRun(ctx, func(ctx context.Context, g GenericCRUD[User]) error {
+		return g.UpdateField(ctx, *u, "name", "bob")
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, stmts)
+	table, _ := g.HistoryTable()
+	var n int64
+	require.NoError(t, db.Table(table).Count(&n).Error)
+	require.Zero(t, n)
+
+	stmts, err = g.DryRun(ctx, func(ctx context.Context, g GenericCRUD[User]) error {
+		_, err := g.CreateIdempotent(ctx, User{Name: "cid"}, "k1")
+		return err
+	})
+	require.NoError(t, err)
+	require.Contains(t, stmts[len(stmts)-1].SQL, "INSERT INTO `idempotency_keys`")
+	require.NoError(t, db.Model(&IdempotencyKey{}).Count(&n).Error)
+	require.Zero(t, n)
+	created, err := g.CreateIdempotent(ctx, User{Name: "cid"}, "k1")
+	require.NoError(t, err)
+	require.Equal(t, "cid", created.Name)
+	again, err := g.CreateIdempotent(ctx, User{Name: "other"}, "k1")
+	require.NoError(t, err)
+	require.Equal(t, created.ID, again.ID)
+}
@@ -0,0 +1,126 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/clause"
+)
+
+// InvalidEnumValueError is returned when writing or filtering enum column with value not registered by WithEnum
+type InvalidEnumValueError struct {
+	Column  string
+	Value   any
+	Allowed []any
+}
+
+// Error implements error
+func (e InvalidEnumValueError) Error() string {
+	return fmt.Sprintf("invalid value %v of column %s, allowed %v", e.Value, e.Column, e.Allowed)
+}
+
+// WithEnum returns copy of g accepting only values for column on Create, Update* and ValidateQuery.
+// Values are compared by their string representation, so typed string constants match plain strings
+func (g GenericCRUD[T]) WithEnum(column string, values ...any) GenericCRUD[T] {
+	if s, err := g.schema(); err == nil {
+		if f := s.LookUpField(column); f != nil && f.DBName != "" {
+			column = f.DBName
+		}
+	}
+	enums := make(map[string][]any, len(g.enums)+1)
+	for k, v := range g.enums {
+		enums[k] = v
+	}
+	enums[column] = values
+	g.enums = enums
+	return g
+}
+
+// Enum returns values registered for column
+func (g GenericCRUD[T]) Enum(column string) []any {
+	return g.enums[column]
+}
+
+// checkEnum returns InvalidEnumValueError if value is not allowed for column; SQL expressions are not checked
+func (g GenericCRUD[T]) checkEnum(column string, value any) error {
+	allowed, ok := g.enums[column]
+	if !ok {
+		return nil
+	}
+	switch value.(type) {
+	case clause.Expr, *clause.Expr:
+		return nil
+	}
+	for _, a := range allowed {
+		if fmt.Sprint(a) == fmt.Sprint(value) {
+			return nil
+		}
+	}
+	return InvalidEnumValueError{Column: column, Value: value, Allowed: allowed}
+}
+
+// checkEnums checks non-zero enum fields of v
+func (g GenericCRUD[T]) checkEnums(v T) error {
+	if len(g.enums) == 0 {
+		return nil
+	}
+	s, err := g.schema()
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(&v).Elem()
+	for column := range g.enums {
+		f := s.LookUpField(column)
+		if f == nil {
+			continue
+		}
+		if value, zero := f.ValueOf(context.Background(), rv); !zero {
+			if err = g.checkEnum(column, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkEnumMap checks values of m keyed by column or field name
+func (g GenericCRUD[T]) checkEnumMap(m map[string]any) error {
+	if len(g.enums) == 0 {
+		return nil
+	}
+	s, err := g.schema()
+	if err != nil {
+		return err
+	}
+	for k, value := range m {
+		if f := s.LookUpField(k); f != nil {
+			k = f.DBName
+		}
+		if err = g.checkEnum(k, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkEnumQuery checks equality filters of q on enum columns
+func (g GenericCRUD[T]) checkEnumQuery(q Query) error {
+	if len(g.enums) == 0 {
+		return nil
+	}
+	if err := g.checkEnumMap(q.Equal); err != nil {
+		return err
+	}
+	if err := g.checkEnumMap(q.NotEqual); err != nil {
+		return err
+	}
+	for k, values := range q.In {
+		for _, v := range values {
+			if err := g.checkEnum(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package crud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type status string
+
+func TestEnum(t *testing.T) {
+	db := dryRunDB(t)
+	stmts := captureSQL(t, db)
+	g := New[User](db).WithEnum("Name", status("new"), status("done"))
+	require.Equal(t, []any{status("new"), status("done")}, g.Enum("name"))
+
+	var enum InvalidEnumValueError
+	_, err := g.Create(context.TODO(), User{Name: "bad"})
+	require.ErrorAs(t, err, &enum)
+	require.Equal(t, "name", enum.Column)
+	require.Equal(t, "bad", enum.Value)
+	require.ErrorAs(t, g.UpdateField(context.TODO(), User{Model: gorm.Model{ID: 1}}, "name", "bad"), &enum)
+	require.ErrorAs(t, g.UpdateMap(context.TODO(), User{Model: gorm.Model{ID: 1}}, map[string]any{"Name": "bad"}), &enum)
+	require.ErrorAs(t, g.Patch(context.TODO(), 1, map[string]any{"name": "bad"}), &enum)
+	require.ErrorAs(t, g.ValidateQuery(Query{In: map[string][]any{"name": {"new", "bad"}}}), &enum)
+	require.Empty(t, *stmts)
+
+	_, err = g.Create(context.TODO(), User{Name: "new"})
+	require.NoError(t, err)
+	_, err = g.Create(context.TODO(), User{})
+	require.NoError(t, err)
+	require.NoError(t, g.UpdateExpr(context.TODO(), User{Model: gorm.Model{ID: 1}}, "name", gorm.Expr("upper(name)")))
+	require.NoError(t, g.ValidateQuery(Query{Equal: map[string]any{"name": "done"}}))
+}
@@ -0,0 +1,79 @@
+package crud
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Explain returns query plan of SmartQuery(ctx, q) without executing it
+func (g GenericCRUD[T]) Explain(ctx context.Context, q Query) (string, error) {
+	return g.explain(ctx, q, false)
+}
+
+// ExplainAnalyze executes SmartQuery(ctx, q) and returns query plan with actual timings.
+// SQLite has no ANALYZE and returns same plan as Explain
+func (g GenericCRUD[T]) ExplainAnalyze(ctx context.Context, q Query) (string, error) {
+	return g.explain(ctx, q, true)
+}
+
+func (g GenericCRUD[T]) explain(ctx context.Context, q Query, analyze bool) (string, error) {
+	query, vars, err := g.smartSQL(ctx, q)
+	if err != nil {
+		return "", err
+	}
+	prefix := "EXPLAIN "
+	switch {
+	case g.Dialect() == SQLite:
+		prefix = "EXPLAIN QUERY PLAN "
+	case analyze:
+		prefix = "EXPLAIN ANALYZE "
+	}
+	rows, err := g.conn(ctx).Statement.ConnPool.QueryContext(ctx, prefix+query, vars...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	var (
+		plan   strings.Builder
+		values = make([]sql.NullString, len(columns))
+		dest   = make([]any, len(columns))
+	)
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return "", err
+		}
+		for i, v := range values {
+			if i > 0 {
+				plan.WriteByte('\t')
+			}
+			plan.WriteString(v.String)
+		}
+		plan.WriteByte('\n')
+	}
+	return plan.String(), rows.Err()
+}
+
+// smartSQL returns SQL and vars SmartQuery(ctx, q) would execute
+func (g GenericCRUD[T]) smartSQL(ctx context.Context, q Query) (string, []any, error) {
+	stmt, err := g.smartStmt(ctx, q)
+	if err != nil {
+		return "", nil, err
+	}
+	var res []*T
+	stmt = stmt.Session(&gorm.Session{DryRun: true}).Find(&res)
+	if stmt.Error != nil {
+		return "", nil, fmt.Errorf("build query: %w", stmt.Error)
+	}
+	return stmt.Statement.SQL.String(), stmt.Statement.Vars, nil
+}
@@ -0,0 +1,158 @@
+package crud
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+type (
+	// ExportType is output encoding of Export
+	ExportType uint
+
+	// ExportFormat configures Export
+	ExportFormat struct {
+		Type ExportType
+		// Columns to export in order, by column or Go field name; all selected columns if empty.
+		// Unknown columns fail with InvalidFilterError, columns hidden from reads with ForbiddenFieldError
+		Columns []string
+		// NoHeader omits CSV header row
+		NoHeader bool
+		// FlushEvery flushes output every n rows; 1000 if zero
+		FlushEvery int
+	}
+)
+
+const (
+	CSV ExportType = iota
+	JSONL
+)
+
+// Export streams rows matching q to w without loading them all into memory
+func (g GenericCRUD[T]) Export(ctx context.Context, q Query, format ExportFormat, w io.Writer) error {
+	columns, err := g.exportColumns(ctx, q, format.Columns)
+	if err != nil {
+		return err
+	}
+	if len(columns) > 0 {
+		q.Select = columns
+	}
+	q.Preload = nil
+	stmt, err := g.uncapped().smartStmt(ctx, q)
+	if err != nil {
+		return err
+	}
+	rows, err := stmt.Model(new(T)).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if len(columns) == 0 {
+		if columns, err = rows.Columns(); err != nil {
+			return err
+		}
+	}
+	flushEvery := format.FlushEvery
+	if flushEvery <= 0 {
+		flushEvery = 1000
+	}
+
+	var (
+		buf    = bufio.NewWriter(w)
+		csvw   = csv.NewWriter(buf)
+		jsonw  = json.NewEncoder(buf)
+		record = make([]string, len(columns))
+	)
+	write := func(row map[string]any) error {
+		switch format.Type {
+		case CSV:
+			for i, c := range columns {
+				record[i] = csvValue(row[c])
+			}
+			return csvw.Write(record)
+		case JSONL:
+			for k, v := range row {
+				if b, ok := v.([]byte); ok {
+					row[k] = string(b)
+				}
+			}
+			return jsonw.Encode(row)
+		default:
+			return fmt.Errorf("unknown export type %d", format.Type)
+		}
+	}
+	flush := func() error {
+		csvw.Flush()
+		if err := csvw.Error(); err != nil {
+			return err
+		}
+		return buf.Flush()
+	}
+	if format.Type == CSV && !format.NoHeader {
+		if err = csvw.Write(columns); err != nil {
+			return err
+		}
+	}
+	for n := 1; rows.Next(); n++ {
+		row := make(map[string]any, len(columns))
+		if err = stmt.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err = write(row); err != nil {
+			return err
+		}
+		if n%flushEvery == 0 {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// exportColumns resolves columns to names of readable columns of T
+func (g GenericCRUD[T]) exportColumns(ctx context.Context, q Query, columns []string) ([]string, error) {
+	if len(columns) == 0 {
+		return nil, nil
+	}
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	hidden := map[string]bool{}
+	for _, c := range g.readOmits(ctx, g.cfg.Omit, q.Omit) {
+		hidden[c] = true
+	}
+	res := make([]string, len(columns))
+	for i, c := range columns {
+		field := s.LookUpField(c)
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("%w: unknown export column %q", InvalidFilterError, c)
+		}
+		if hidden[field.DBName] {
+			return nil, fmt.Errorf("%w: %s", ForbiddenFieldError, field.DBName)
+		}
+		res[i] = field.DBName
+	}
+	return res, nil
+}
+
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,30 @@
+package crud
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportColumns(t *testing.T) {
+	ctx := context.TODO()
+	g := New[User](benchDB(t, "export_columns"))
+	_, err := g.Create(ctx, User{Name: "ann", Age: sql.NullInt16{Int16: 42, Valid: true}})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, g.Export(ctx, Query{}, ExportFormat{Type: CSV, Columns: []string{"Name", "age"}}, &buf))
+	require.Equal(t, "name,age\nann,42\n", buf.String())
+
+	hidden := g.WithFieldPolicy(func(ctx context.Context) []string { return []string{"age"} })
+	require.ErrorIs(t, hidden.Export(ctx, Query{}, ExportFormat{Type: CSV, Columns: []string{"name", "age"}}, &buf), ForbiddenFieldError)
+	require.ErrorIs(t, g.Export(ctx, Query{Omit: []string{"age"}}, ExportFormat{Type: CSV, Columns: []string{"age"}}, &buf), ForbiddenFieldError)
+	require.ErrorIs(t, g.Export(ctx, Query{}, ExportFormat{Type: CSV, Columns: []string{"(select sqlite_version()) as v"}}, &buf), InvalidFilterError)
+
+	buf.Reset()
+	require.NoError(t, hidden.Export(ctx, Query{}, ExportFormat{Type: JSONL, Columns: []string{"name"}}, &buf))
+	require.Equal(t, "{\"name\":\"ann\"}\n", buf.String())
+}
@@ -0,0 +1,138 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// FeedBatchSize is maximum number of rows returned by single ChangeFeed poll
+var FeedBatchSize = 500
+
+// Feed iterates rows of T changed since high watermark; see GenericCRUD.ChangeFeed
+type Feed[T GORMModel] struct {
+	g        GenericCRUD[T]
+	ctx      context.Context
+	interval time.Duration
+	since    time.Time
+	// seen are primary keys of rows returned with updated time equal to since
+	seen map[any]bool
+}
+
+/*
+ChangeFeed returns Feed polling rows of T updated since given time every interval, a portable alternative to Watch:
+
+	feed := users.ChangeFeed(ctx, time.Now(), 5*time.Second)
+	for {
+		rows, err := feed.Next()
+		if err != nil {
+			return err // ctx.Err() once ctx is done
+		}
+		for _, u := range rows {
+			invalidate(u.ID)
+		}
+	}
+
+Rows are ordered by UpdatedAt and primary key. Polls include rows updated exactly at high watermark, so rows
+committed later with the same timestamp are not missed, and rows already returned at watermark are skipped.
+Soft-deleted rows are returned if deleting updated UpdatedAt; hard deletes are not observed
+*/
+func (g GenericCRUD[T]) ChangeFeed(ctx context.Context, since time.Time, interval time.Duration) *Feed[T] {
+	return &Feed[T]{g: g.uncapped(), ctx: ctx, interval: interval, since: since, seen: map[any]bool{}}
+}
+
+// Since returns high watermark: the latest updated time of returned rows
+func (f *Feed[T]) Since() time.Time {
+	return f.since
+}
+
+// Next blocks until rows changed since high watermark are found and returns them, polling every interval;
+// returns ctx.Err() when ctx is done
+func (f *Feed[T]) Next() ([]T, error) {
+	for {
+		rows, err := f.Poll()
+		if err != nil || len(rows) > 0 {
+			return rows, err
+		}
+		timer := time.NewTimer(f.interval)
+		select {
+		case <-f.ctx.Done():
+			timer.Stop()
+			return nil, f.ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Poll returns rows changed since high watermark without waiting, at most FeedBatchSize; empty if there are none
+func (f *Feed[T]) Poll() ([]T, error) {
+	if err := f.ctx.Err(); err != nil {
+		return nil, err
+	}
+	s, err := f.g.schema()
+	if err != nil {
+		return nil, err
+	}
+	updated := updatedField(s)
+	if updated == nil {
+		return nil, fmt.Errorf("%s has no updated time column", s.Name)
+	}
+	if s.PrioritizedPrimaryField == nil {
+		return nil, fmt.Errorf("%s has no primary key", s.Name)
+	}
+	var rows []T
+	// limit fits rows seen at watermark and a batch of new ones
+	err = f.g.session(f.ctx).Unscoped().
+		Where(clause.Gte{Column: clause.Column{Name: updated.DBName}, Value: f.since}).
+		Clauses(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: updated.DBName}},
+			{Column: clause.Column{Name: s.PrioritizedPrimaryField.DBName}},
+		}}).
+		Limit(len(f.seen) + FeedBatchSize).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return f.advance(rows, updated), nil
+}
+
+// advance drops rows seen at high watermark and moves it to the latest updated time of rows
+func (f *Feed[T]) advance(rows []T, updated *schema.Field) []T {
+	res := rows[:0]
+	for _, v := range rows {
+		at, _ := updated.ValueOf(f.ctx, reflect.ValueOf(&v).Elem())
+		t, ok := at.(time.Time)
+		if p, isPtr := at.(*time.Time); isPtr && p != nil {
+			t, ok = *p, true
+		}
+		if !ok {
+			continue
+		}
+		id := v.PrimaryKey()
+		if t.After(f.since) {
+			f.since, f.seen = t, map[any]bool{}
+		} else if f.seen[id] {
+			continue
+		}
+		f.seen[id] = true
+		res = append(res, v)
+	}
+	return res
+}
+
+// updatedField returns time field UpdatedAt or other time field with autoUpdateTime
+func updatedField(s *schema.Schema) *schema.Field {
+	if f := s.LookUpField("UpdatedAt"); f != nil && f.DBName != "" && f.DataType == schema.Time {
+		return f
+	}
+	for _, f := range s.Fields {
+		if f.AutoUpdateTime > 0 && f.DBName != "" && f.DataType == schema.Time {
+			return f
+		}
+	}
+	return nil
+}
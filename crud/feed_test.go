@@ -0,0 +1,52 @@
+package crud
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeFeed(t *testing.T) {
+	ctx := context.TODO()
+	db := benchDB(t, "feed")
+	g := New[User](db)
+	ann, err := g.Create(ctx, User{Name: "ann"})
+	require.NoError(t, err)
+	_, err = g.Create(ctx, User{Name: "bob"})
+	require.NoError(t, err)
+
+	feed := g.ChangeFeed(ctx, time.Time{}, time.Millisecond)
+	rows, err := feed.Next()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, "ann", rows[0].Name)
+	watermark := feed.Since()
+	require.Equal(t, rows[1].UpdatedAt.UnixNano(), watermark.UnixNano())
+	rows, err = feed.Poll()
+	require.NoError(t, err)
+	require.Empty(t, rows)
+
+	// committed late with timestamp of watermark
+	late, err := g.Create(ctx, User{Name: "cid"})
+	require.NoError(t, err)
+	require.NoError(t, db.Model(late).UpdateColumn("updated_at", watermark).Error)
+	rows, err = feed.Poll()
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, "cid", rows[0].Name)
+
+	require.NoError(t, g.UpdateField(ctx, *ann, "name", "ann2"))
+	require.NoError(t, g.Delete(ctx, *late))
+	rows, err = feed.Next()
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, "ann2", rows[0].Name)
+	require.True(t, feed.Since().After(watermark))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = g.ChangeFeed(cancelled, feed.Since(), time.Millisecond).Next()
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,94 @@
+package crud
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+// Options of crud struct tag; comma-separated, e.g. `crud:"filterable,sortable"`
+const (
+	// TagOmitRead omits column from every read, e.g. password hashes; it is still written
+	TagOmitRead = "omit_read"
+	// TagFilterable allows filtering by column; if no field is filterable, all columns are
+	TagFilterable = "filterable"
+	// TagSortable allows sorting by column; if no field is sortable, all columns are
+	TagSortable = "sortable"
+	// TagSoftUnique makes column unique among rows not soft-deleted; columns tagged soft_unique:<name>
+	// are unique together as constraint <name>. See SoftUniques and EnsureIndexes
+	TagSoftUnique = "soft_unique"
+)
+
+// hasTag reports whether crud tag of field has option
+func hasTag(f *schema.Field, option string) bool {
+	for _, o := range strings.Split(f.Tag.Get("crud"), ",") {
+		if strings.TrimSpace(o) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// tagOption reports whether crud tag of field has option, alone or as option:value, and returns value
+func tagOption(f *schema.Field, option string) (string, bool) {
+	for _, o := range strings.Split(f.Tag.Get("crud"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(o), ":")
+		if name == option {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// taggedCache holds taggedColumns per schema and option
+var taggedCache sync.Map
+
+type taggedKey struct {
+	schema *schema.Schema
+	option string
+}
+
+// taggedColumns returns columns of T having crud tag option
+func (g GenericCRUD[T]) taggedColumns(option string) ([]string, error) {
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	key := taggedKey{s, option}
+	if v, ok := taggedCache.Load(key); ok {
+		return v.([]string), nil
+	}
+	var res []string
+	for _, f := range s.Fields {
+		if f.DBName != "" && hasTag(f, option) {
+			res = append(res, f.DBName)
+		}
+	}
+	taggedCache.Store(key, res)
+	return res, nil
+}
+
+// readOmits returns omits of reads: omits of ctx, lists and omit_read columns
+func (g GenericCRUD[T]) readOmits(ctx context.Context, lists ...[]string) []string {
+	if columns, err := g.taggedColumns(TagOmitRead); err == nil && len(columns) > 0 {
+		lists = append(lists, columns)
+	}
+	return g.omits(ctx, lists...)
+}
+
+// readColumns returns columns of s read in ctx: all but readOmits of configured omits, in schema order
+func (g GenericCRUD[T]) readColumns(ctx context.Context, s *schema.Schema) []string {
+	omitted := map[string]bool{}
+	for _, c := range g.readOmits(ctx, g.cfg.Omit) {
+		omitted[c] = true
+	}
+	res := make([]string, 0, len(s.DBNames))
+	for _, c := range s.DBNames {
+		if !omitted[c] {
+			res = append(res, c)
+		}
+	}
+	return res
+}
@@ -0,0 +1,61 @@
+package crud
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type account struct {
+	Model
+	Email    string `crud:"filterable,sortable,unique"`
+	Name     string `crud:"sortable"`
+	Password string `crud:"omit_read"`
+}
+
+func TestFieldTags(t *testing.T) {
+	db := dryRunDB(t)
+	sql := captureSQL(t, db)
+	g := New[account](db)
+
+	_, err := g.SmartQuery(context.TODO(), Query{})
+	require.NoError(t, err)
+	require.Contains(t, (*sql)[0], `SELECT "accounts"."id","accounts"."created_at","accounts"."updated_at","accounts"."deleted_at","accounts"."email","accounts"."name" FROM`)
+	_, err = g.Create(context.TODO(), account{Email: "a@b.c", Password: "hash"})
+	require.NoError(t, err)
+	require.Contains(t, (*sql)[1], `"password"`)
+
+	q, err := g.ParseQuery(url.Values{"email": {"a@b.c"}, "order_by": {"-name"}})
+	require.NoError(t, err)
+	require.Equal(t, Query{Equal: map[string]any{"email": "a@b.c"}, OrderBy: []OrderClause{{Column: "name", Direction: DESC}}}, q)
+	_, err = g.ParseQuery(url.Values{"name": {"x"}})
+	require.ErrorIs(t, err, InvalidFilterError)
+	_, err = g.ParseQuery(url.Values{"order_by": {"password"}})
+	require.ErrorIs(t, err, InvalidFilterError)
+	_, err = g.ParseQuery(url.Values{"name": {"x"}}, "name")
+	require.NoError(t, err)
+
+	require.NoError(t, g.ValidateQuery(Query{Equal: map[string]any{"email": "x"}, OrderBy: []OrderClause{{Column: "name", Direction: ASC}}, Omit: []string{"password"}}))
+	require.ErrorIs(t, g.ValidateQuery(Query{OrderBy: []OrderClause{{Column: "password", Direction: ASC}}}), InvalidFilterError)
+
+	// untagged columns are allowed by default, but not hidden ones
+	type secret struct {
+		Model
+		Name     string
+		Password string `crud:"omit_read"`
+		Salary   int
+	}
+	secrets := New[secret](db).WithFieldPolicy(func(ctx context.Context) []string { return []string{"salary"} })
+	_, err = secrets.ParseQuery(url.Values{"name": {"x"}, "order_by": {"id"}})
+	require.NoError(t, err)
+	for _, values := range []url.Values{{"password": {"x"}}, {"salary__gt": {"1"}}, {"order_by": {"-salary"}}} {
+		_, err = secrets.ParseQuery(values)
+		require.ErrorIs(t, err, InvalidFilterError, values)
+	}
+	require.ErrorIs(t, secrets.ValidateQuery(Query{Equal: map[string]any{"password": "x"}}), InvalidFilterError)
+	require.NoError(t, secrets.ValidateQuery(Query{Omit: []string{"password"}}))
+	_, err = secrets.ParseQuery(url.Values{"salary": {"1"}}, "salary")
+	require.NoError(t, err)
+}
@@ -0,0 +1,98 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// WithCoalescing returns copy of g sharing one database round trip between concurrent identical
+// GetByID and QueryOne calls, e.g. during cache stampedes. Callers get shallow copies of shared result.
+// Calls in transaction or with SessionSettings are not coalesced
+func (g GenericCRUD[T]) WithCoalescing() GenericCRUD[T] {
+	g.cfg.Coalesce = true
+	return g
+}
+
+type (
+	// flightGroup runs one call per key at a time; callers arriving meanwhile wait for its result
+	flightGroup struct {
+		mu    sync.Mutex
+		calls map[string]*flightCall
+	}
+
+	flightCall struct {
+		done chan struct{}
+		val  any
+		err  error
+	}
+)
+
+var flights flightGroup
+
+// do runs fn once for concurrent callers with same key; waiters stop waiting when their ctx is done.
+// shared reports whether result came from call of another caller
+func (fg *flightGroup) do(ctx context.Context, key string, fn func() (any, error)) (val any, err error, shared bool) {
+	fg.mu.Lock()
+	if c, ok := fg.calls[key]; ok {
+		fg.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.val, c.err, true
+		case <-ctx.Done():
+			return nil, ctx.Err(), true
+		}
+	}
+	if fg.calls == nil {
+		fg.calls = make(map[string]*flightCall)
+	}
+	c := &flightCall{done: make(chan struct{})}
+	fg.calls[key] = c
+	fg.mu.Unlock()
+
+	defer func() {
+		fg.mu.Lock()
+		delete(fg.calls, key)
+		fg.mu.Unlock()
+		close(c.done)
+	}()
+	c.val, c.err = fn()
+	return c.val, c.err, false
+}
+
+// coalesce runs fetch through flights if coalescing applies to ctx, copying shared result.
+// Waiter whose leader was canceled while its own ctx is alive fetches itself
+func (g GenericCRUD[T]) coalesce(ctx context.Context, key func() string, fetch func() (*T, error)) (*T, error) {
+	if !g.cfg.Coalesce || g.cfg.SessionSettings != nil || g.cfg.DryRun != nil {
+		return fetch()
+	}
+	if _, ok := TxFromContext(ctx); ok {
+		return fetch()
+	}
+	val, err, shared := flights.do(ctx, key(), func() (any, error) {
+		return fetch()
+	})
+	if !shared {
+		return val.(*T), err
+	}
+	if err != nil && ctx.Err() == nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return fetch()
+	}
+	res, _ := val.(*T)
+	if res != nil {
+		cp := *res
+		res = &cp
+	}
+	return res, err
+}
+
+// flightKey returns key identifying operation of g on v with omits
+func (g GenericCRUD[T]) flightKey(ctx context.Context, op string, v T, omit []string) func() string {
+	return func() string {
+		table, _ := g.tableName(ctx, v)
+		return fmt.Sprintf("%p|%s|%s|%s|%#v|%s", g.db, reflect.TypeOf(v), table, op, v, strings.Join(g.readOmits(ctx, g.cfg.Omit, omit), ","))
+	}
+}
@@ -0,0 +1,49 @@
+package crud
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCoalescing(t *testing.T) {
+	db := benchDB(t, "coalescing")
+	var queries int32
+	require.NoError(t, db.Callback().Query().Before("gorm:query").Register("test:slow", func(*gorm.DB) {
+		atomic.AddInt32(&queries, 1)
+		time.Sleep(50 * time.Millisecond)
+	}))
+	g := New[User](db).WithCoalescing()
+	created, err := g.Create(context.TODO(), User{Name: "shared"})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	results := make([]*User, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, err := g.GetByID(context.TODO(), *created)
+			require.NoError(t, err)
+			results[i] = res
+		}(i)
+	}
+	wg.Wait()
+	require.EqualValues(t, 1, atomic.LoadInt32(&queries))
+	for _, res := range results[1:] {
+		require.Equal(t, "shared", res.Name)
+		require.NotSame(t, results[0], res)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	go func() { _, _ = g.QueryOne(context.TODO(), User{Name: "shared"}) }()
+	time.Sleep(5 * time.Millisecond)
+	_, err = g.QueryOne(ctx, User{Name: "shared"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,62 @@
+package crud
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// FullText is a full-text search condition for SmartQuery
+type FullText struct {
+	// Columns to search in; concatenated into a single document
+	Columns []string `json:"columns"`
+	// Term is the user's search string
+	Term string `json:"term"`
+	// Config is the Postgres text search configuration; "simple" if empty
+	Config string `json:"config,omitempty"`
+	// Rank orders results by relevance (most relevant first) before Query.OrderBy
+	Rank bool `json:"rank,omitempty"`
+}
+
+// build returns the search condition and the rank expression for dialect
+func (f FullText) build(dialect Dialect) (cond clause.Expr, rank clause.Expr, err error) {
+	if len(f.Columns) == 0 {
+		return cond, rank, fmt.Errorf("full-text search: no columns")
+	}
+	switch dialect {
+	case Postgres:
+		config := f.Config
+		if config == "" {
+			config = "simple"
+		}
+		cols := make([]string, len(f.Columns))
+		for i, c := range f.Columns {
+			cols[i] = "coalesce(" + c + ", '')"
+		}
+		doc := "to_tsvector(?::regconfig, " + strings.Join(cols, " || ' ' || ") + ")"
+		query := "plainto_tsquery(?::regconfig, ?)"
+		cond = clause.Expr{SQL: doc + " @@ " + query, Vars: []any{config, config, f.Term}}
+		rank = clause.Expr{SQL: "ts_rank(" + doc + ", " + query + ") DESC", Vars: []any{config, config, f.Term}}
+	case MySQL:
+		match := "MATCH (" + strings.Join(f.Columns, ", ") + ") AGAINST (? IN NATURAL LANGUAGE MODE)"
+		cond = clause.Expr{SQL: match, Vars: []any{f.Term}}
+		rank = clause.Expr{SQL: match + " DESC", Vars: []any{f.Term}}
+	default:
+		return cond, rank, fmt.Errorf("full-text search is not supported for %q", dialect)
+	}
+	return cond, rank, nil
+}
+
+// joinExprs joins expressions with sep into a single expression
+func joinExprs(exprs []clause.Expr, sep string) clause.Expr {
+	var (
+		sql  = make([]string, len(exprs))
+		vars []any
+	)
+	for i, e := range exprs {
+		sql[i] = e.SQL
+		vars = append(vars, e.Vars...)
+	}
+	return clause.Expr{SQL: strings.Join(sql, sep), Vars: vars, WithoutParentheses: true}
+}
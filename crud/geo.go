@@ -0,0 +1,76 @@
+package crud
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/clause"
+)
+
+type (
+	// Radius matches points of Column within Meters of Lat, Lng (WGS 84)
+	Radius struct {
+		Column string  `json:"column"`
+		Lat    float64 `json:"lat"`
+		Lng    float64 `json:"lng"`
+		Meters float64 `json:"meters"`
+		// OrderByDistance orders results nearest first before Query.OrderBy
+		OrderByDistance bool `json:"order_by_distance,omitempty"`
+	}
+
+	// BBox matches points of Column within bounding box
+	BBox struct {
+		Column string  `json:"column"`
+		MinLat float64 `json:"min_lat"`
+		MinLng float64 `json:"min_lng"`
+		MaxLat float64 `json:"max_lat"`
+		MaxLng float64 `json:"max_lng"`
+	}
+)
+
+// WithinRadius returns Radius condition ordered by distance
+func WithinRadius(column string, lat, lng, meters float64) *Radius {
+	return &Radius{Column: column, Lat: lat, Lng: lng, Meters: meters, OrderByDistance: true}
+}
+
+// WithinBBox returns BBox condition
+func WithinBBox(column string, minLat, minLng, maxLat, maxLng float64) *BBox {
+	return &BBox{Column: column, MinLat: minLat, MinLng: minLng, MaxLat: maxLat, MaxLng: maxLng}
+}
+
+// build returns radius condition and distance ordering for dialect.
+// Postgres requires PostGIS and geometry(Point, 4326) or geography column; MySQL uses spherical distance
+func (r Radius) build(dialect Dialect) (cond clause.Expr, order clause.Expr, err error) {
+	column := clause.Column{Name: r.Column}
+	switch dialect {
+	case Postgres:
+		point := "ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography"
+		cond = clause.Expr{SQL: "ST_DWithin(?::geography, " + point + ", ?)", Vars: []any{column, r.Lng, r.Lat, r.Meters}}
+		order = clause.Expr{SQL: "ST_Distance(?::geography, " + point + ")", Vars: []any{column, r.Lng, r.Lat}}
+	case MySQL:
+		distance := "ST_Distance_Sphere(?, POINT(?, ?))"
+		cond = clause.Expr{SQL: distance + " <= ?", Vars: []any{column, r.Lng, r.Lat, r.Meters}}
+		order = clause.Expr{SQL: distance, Vars: []any{column, r.Lng, r.Lat}}
+	default:
+		return cond, order, fmt.Errorf("geospatial queries are not supported for %q", dialect)
+	}
+	return cond, order, nil
+}
+
+// build returns bounding box condition for dialect
+func (b BBox) build(dialect Dialect) (clause.Expr, error) {
+	column := clause.Column{Name: b.Column}
+	switch dialect {
+	case Postgres:
+		return clause.Expr{
+			SQL:  "?::geometry && ST_MakeEnvelope(?, ?, ?, ?, 4326)",
+			Vars: []any{column, b.MinLng, b.MinLat, b.MaxLng, b.MaxLat},
+		}, nil
+	case MySQL:
+		return clause.Expr{
+			SQL:  "MBRContains(ST_MakeEnvelope(POINT(?, ?), POINT(?, ?)), ?)",
+			Vars: []any{b.MinLng, b.MinLat, b.MaxLng, b.MaxLat, column},
+		}, nil
+	default:
+		return clause.Expr{}, fmt.Errorf("geospatial queries are not supported for %q", dialect)
+	}
+}
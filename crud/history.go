@@ -0,0 +1,207 @@
+package crud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type (
+	// HistoryRecord is row of history table holding JSON snapshot of replaced version of a row
+	HistoryRecord struct {
+		ID        uint   `gorm:"primaryKey"`
+		EntityID  string `gorm:"index;size:255"`
+		Operation string `gorm:"size:16"`
+		Data      string
+		ValidFrom time.Time
+		ValidTo   time.Time `gorm:"index"`
+	}
+
+	// Version of a row; ValidTo is zero for current version. ValidFrom of first known version is creation time
+	// of the row if T has created time column, zero otherwise
+	Version[T GORMModel] struct {
+		Value     T
+		Operation string
+		ValidFrom time.Time
+		ValidTo   time.Time
+	}
+)
+
+// HistoryRecord operations
+const (
+	HistoryUpdate = "update"
+	HistoryDelete = "delete"
+)
+
+// WithHistory returns copy of g writing replaced version of a row to history table "<table>_history"
+// before every Update, UpdateField, UpdateMap, Patch and Delete by primary key. Create table with MigrateHistory
+func (g GenericCRUD[T]) WithHistory() GenericCRUD[T] {
+	g.history = true
+	return g
+}
+
+// HistoryTable returns name of history table of T
+func (g GenericCRUD[T]) HistoryTable() (string, error) {
+	s, err := g.schema()
+	if err != nil {
+		return "", err
+	}
+	return s.Table + "_history", nil
+}
+
+// MigrateHistory creates or updates history table of T
+func (g GenericCRUD[T]) MigrateHistory(ctx context.Context) error {
+	table, err := g.HistoryTable()
+	if err != nil {
+		return err
+	}
+	return g.conn(ctx).WithContext(ctx).Table(table).AutoMigrate(&HistoryRecord{})
+}
+
+// History returns versions of row with primary key id, oldest first; current version, if not deleted, is last
+func (g GenericCRUD[T]) History(ctx context.Context, id any) ([]Version[T], error) {
+	v, err := g.FromID(id)
+	if err != nil {
+		return nil, err
+	}
+	table, err := g.HistoryTable()
+	if err != nil {
+		return nil, err
+	}
+	var records []HistoryRecord
+	err = g.connSession(ctx).Table(table).
+		Where("entity_id = ?", fmt.Sprint(v.PrimaryKey())).Order("valid_to, id").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Version[T], 0, len(records)+1)
+	for _, r := range records {
+		version, err := historyVersion[T](r)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, version)
+	}
+	current, err := g.GetByID(ctx, v)
+	switch {
+	case err == nil:
+		version := Version[T]{Value: *current}
+		if len(res) > 0 {
+			version.ValidFrom = res[len(res)-1].ValidTo
+		}
+		res = append(res, version)
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+	if len(res) > 0 && res[0].ValidFrom.IsZero() {
+		s, err := g.schema()
+		if err != nil {
+			return nil, err
+		}
+		res[0].ValidFrom = createdAt(ctx, s, &res[0].Value)
+	}
+	return res, nil
+}
+
+// AsOf returns version of row with primary key id valid at t; gorm.ErrRecordNotFound if row wasn't created
+// or was deleted by then
+func (g GenericCRUD[T]) AsOf(ctx context.Context, id any, t time.Time) (*T, error) {
+	versions, err := g.History(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, version := range versions {
+		if t.Before(version.ValidFrom) {
+			break
+		}
+		if version.ValidTo.IsZero() || t.Before(version.ValidTo) {
+			return &version.Value, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// createdAt returns value of CreatedAt or other time field with autoCreateTime of v, zero if there is none
+func createdAt[T GORMModel](ctx context.Context, s *schema.Schema, v *T) time.Time {
+	f := s.LookUpField("CreatedAt")
+	if f == nil || f.DBName == "" || f.DataType != schema.Time {
+		f = nil
+		for _, field := range s.Fields {
+			if field.AutoCreateTime > 0 && field.DBName != "" && field.DataType == schema.Time {
+				f = field
+				break
+			}
+		}
+	}
+	if f == nil {
+		return time.Time{}
+	}
+	at, _ := f.ValueOf(ctx, reflect.ValueOf(v).Elem())
+	if p, ok := at.(*time.Time); ok && p != nil {
+		return *p
+	}
+	t, _ := at.(time.Time)
+	return t
+}
+
+// versioned runs write fn on v, first saving current version of v to history if enabled.
+// fn receives g without history to not record version twice
+func (g GenericCRUD[T]) versioned(ctx context.Context, v T, operation string, fn func(ctx context.Context, g GenericCRUD[T]) error) error {
+	if !g.history || reflect.ValueOf(v.PrimaryKey()).IsZero() {
+		return fn(ctx, g)
+	}
+	return g.RunInTransaction(ctx, func(ctx context.Context, tx GenericCRUD[T]) error {
+		tx.history = false
+		if err := tx.snapshot(ctx, v, operation); err != nil {
+			return fmt.Errorf("history: %w", err)
+		}
+		return fn(ctx, tx)
+	})
+}
+
+// snapshot writes current version of v to history table
+func (g GenericCRUD[T]) snapshot(ctx context.Context, v T, operation string) error {
+	current, err := g.GetByID(ctx, v)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	table, err := g.HistoryTable()
+	if err != nil {
+		return err
+	}
+	db := g.connSession(ctx).Table(table)
+	record := HistoryRecord{
+		EntityID:  fmt.Sprint(v.PrimaryKey()),
+		Operation: operation,
+		Data:      string(data),
+		ValidTo:   g.now(),
+	}
+	var last HistoryRecord
+	err = db.Where("entity_id = ?", record.EntityID).Order("valid_to DESC, id DESC").Limit(1).Find(&last).Error
+	if err != nil {
+		return err
+	}
+	record.ValidFrom = last.ValidTo
+	return db.Create(&record).Error
+}
+
+func historyVersion[T GORMModel](r HistoryRecord) (Version[T], error) {
+	version := Version[T]{Operation: r.Operation, ValidFrom: r.ValidFrom, ValidTo: r.ValidTo}
+	if err := json.Unmarshal([]byte(r.Data), &version.Value); err != nil {
+		return version, fmt.Errorf("history record %d: %w", r.ID, err)
+	}
+	return version, nil
+}
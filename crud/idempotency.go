@@ -0,0 +1,78 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyKey records entity created by CreateIdempotent; add it to migrations
+type IdempotencyKey struct {
+	Scope     string `gorm:"primarykey"`
+	Key       string `gorm:"primarykey"`
+	EntityID  string
+	CreatedAt time.Time
+}
+
+// CreateIdempotent creates v unless key was already used for T, in which case previously created entity is returned.
+// Key is recorded in the same transaction as v
+func (g GenericCRUD[T]) CreateIdempotent(ctx context.Context, v T, key string, omit ...string) (*T, error) {
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		res *T
+		// keyErr is error of recording key
+		keyErr error
+	)
+	create := func(ctx context.Context) error {
+		var existing IdempotencyKey
+		err := g.connSession(ctx).Where(&IdempotencyKey{Scope: s.Table, Key: key}).Take(&existing).Error
+		if err == nil && existing.Key == "" {
+			// dry run finds nothing
+			err = gorm.ErrRecordNotFound
+		}
+		if err == nil {
+			id, err := g.FromID(existing.EntityID)
+			if err != nil {
+				return err
+			}
+			res, err = g.GetByID(ctx, id)
+			return err
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if res, err = g.Create(ctx, v, omit...); err != nil {
+			return err
+		}
+		keyErr = g.connSession(ctx).Create(&IdempotencyKey{
+			Scope:    s.Table,
+			Key:      key,
+			EntityID: fmt.Sprint((*res).PrimaryKey()),
+		}).Error
+		return keyErr
+	}
+	err = g.RunInTransaction(ctx, func(ctx context.Context, _ GenericCRUD[T]) error { return create(ctx) })
+	if err != nil && keyErr != nil && isUniqueViolation(keyErr) {
+		// concurrent request with the same key won; its entity is returned
+		if retry := g.RunInTransaction(ctx, func(ctx context.Context, _ GenericCRUD[T]) error { return create(ctx) }); retry == nil {
+			return res, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// isUniqueViolation reports whether err is unique constraint violation of supported dialects
+func isUniqueViolation(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unique") || strings.Contains(msg, "duplicate")
+}
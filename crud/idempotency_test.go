@@ -0,0 +1,44 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCreateIdempotentRetry(t *testing.T) {
+	ctx := context.TODO()
+	db := benchDB(t, "idempotent_retry")
+	require.NoError(t, db.AutoMigrate(&IdempotencyKey{}))
+	created, raced, fail := 0, false, false
+	require.NoError(t, db.Callback().Create().Before("gorm:create").Register("test:idempotent", func(tx *gorm.DB) {
+		switch tx.Statement.Table {
+		case "users":
+			created++
+			if fail {
+				_ = tx.AddError(errors.New("boom"))
+			}
+		case "idempotency_keys":
+			if !raced {
+				// key of concurrent request, rolled back with the transaction
+				raced = true
+				_ = tx.AddError(tx.Session(&gorm.Session{NewDB: true}).
+					Exec("INSERT INTO idempotency_keys (scope, key, entity_id) VALUES ('users', 'k1', '0')").Error)
+			}
+		}
+	}))
+	g := New[User](db)
+
+	v, err := g.CreateIdempotent(ctx, User{Name: "ann"}, "k1")
+	require.NoError(t, err)
+	require.Equal(t, "ann", v.Name)
+	require.Equal(t, 2, created)
+
+	fail = true
+	_, err = g.CreateIdempotent(ctx, User{Name: "bob"}, "k2")
+	require.ErrorContains(t, err, "boom")
+	require.Equal(t, 3, created)
+}
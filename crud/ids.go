@@ -0,0 +1,62 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// MissingIDsError is returned by GetByIDsOrdered with results when some ids are not found;
+// errors.Is(err, gorm.ErrRecordNotFound) holds for it
+type MissingIDsError struct {
+	IDs []any
+}
+
+// Error implements error
+func (e MissingIDsError) Error() string {
+	return fmt.Sprintf("%s: ids %v", gorm.ErrRecordNotFound, e.IDs)
+}
+
+// Is makes error match gorm.ErrRecordNotFound
+func (e MissingIDsError) Is(target error) bool {
+	return target == gorm.ErrRecordNotFound
+}
+
+// GetByIDsOrdered returns Models by primary keys in order of ids, querying in chunks below parameter limits.
+// Results of ids not found are nil and MissingIDsError lists them; results are valid with it.
+// Ids are matched by string representation, so "1" finds primary key 1
+func (g GenericCRUD[T]) GetByIDsOrdered(ctx context.Context, ids []any) ([]*T, error) {
+	pk, err := g.PrimaryKeyColumn()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*T, len(ids))
+	chunk := g.Dialect().idsPerQuery()
+	for start := 0; start < len(ids); start += chunk {
+		end := start + chunk
+		if end > len(ids) {
+			end = len(ids)
+		}
+		found, err := g.uncapped().SmartQuery(ctx, Query{In: map[string][]any{pk: ids[start:end]}})
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range found {
+			byID[fmt.Sprint((*v).PrimaryKey())] = v
+		}
+	}
+	res := make([]*T, len(ids))
+	var missing []any
+	for i, id := range ids {
+		if v, ok := byID[fmt.Sprint(id)]; ok {
+			res[i] = v
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return res, MissingIDsError{IDs: missing}
+	}
+	return res, nil
+}
@@ -0,0 +1,202 @@
+package crud
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"gorm.io/gorm/clause"
+)
+
+type (
+	// ImportOptions configures Import
+	ImportOptions[T any] struct {
+		// Type of input; CSV must have header row with column names, JSONL objects are keyed by column
+		Type ExportType
+		// BatchSize is number of rows per INSERT; 500 if zero
+		BatchSize int
+		// Upsert updates existing rows conflicting on ConflictColumns (primary key if empty)
+		Upsert          bool
+		ConflictColumns []string
+		// UpdateColumns are updated on conflict; all columns if empty
+		UpdateColumns []string
+		// Validate is called for every parsed row; rows with error are skipped and reported
+		Validate func(v T) error
+	}
+
+	// RowError is error of a single input row; Row is 1-based and excludes CSV header
+	RowError struct {
+		Row int
+		Err error
+	}
+
+	// ImportReport summarizes Import
+	ImportReport struct {
+		Rows     int
+		Imported int
+		Errors   []RowError
+	}
+)
+
+// Error implements error
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+// Unwrap returns row's error
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// Import parses CSV or JSON Lines from r and inserts (or upserts) rows in batches.
+// Invalid rows and rows rejected by database are reported in ImportReport; error is returned only for unreadable input
+func (g GenericCRUD[T]) Import(ctx context.Context, r io.Reader, opts ImportOptions[T]) (ImportReport, error) {
+	var report ImportReport
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	next, err := g.rowReader(r, opts.Type)
+	if err != nil {
+		return report, err
+	}
+	var (
+		batch []T
+		rows  []int
+	)
+	for {
+		m, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		report.Rows++
+		if err != nil {
+			report.Errors = append(report.Errors, RowError{Row: report.Rows, Err: err})
+			if errors.Is(err, errUnreadable) {
+				return report, err
+			}
+			continue
+		}
+		v, err := g.FromMap(m)
+		if err == nil {
+			err = g.checkEnums(v)
+		}
+		if err == nil && opts.Validate != nil {
+			err = opts.Validate(v)
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, RowError{Row: report.Rows, Err: err})
+			continue
+		}
+		batch, rows = append(batch, v), append(rows, report.Rows)
+		if len(batch) == opts.BatchSize {
+			g.importBatch(ctx, batch, rows, opts, &report)
+			batch, rows = batch[:0], rows[:0]
+		}
+	}
+	g.importBatch(ctx, batch, rows, opts, &report)
+	return report, nil
+}
+
+// importBatch inserts batch; on failure rows are retried one by one to report failing ones
+func (g GenericCRUD[T]) importBatch(ctx context.Context, batch []T, rows []int, opts ImportOptions[T], report *ImportReport) {
+	if len(batch) == 0 {
+		return
+	}
+	s, err := g.schema()
+	if err != nil {
+		for _, row := range rows {
+			report.Errors = append(report.Errors, RowError{Row: row, Err: err})
+		}
+		return
+	}
+	insert := func(vs []T) error {
+		if !opts.Upsert && g.useCopy(ctx, len(vs)) {
+			return g.copyFrom(ctx, vs)
+		}
+		stmt := g.session(ctx).Omit(g.omits(ctx, g.cfg.Omit)...)
+		if opts.Upsert {
+			onConflict := clause.OnConflict{UpdateAll: len(opts.UpdateColumns) == 0}
+			for _, c := range opts.ConflictColumns {
+				onConflict.Columns = append(onConflict.Columns, clause.Column{Name: c})
+			}
+			if len(opts.ConflictColumns) == 0 {
+				for _, f := range s.PrimaryFields {
+					onConflict.Columns = append(onConflict.Columns, clause.Column{Name: f.DBName})
+				}
+			}
+			if len(opts.UpdateColumns) > 0 {
+				onConflict.DoUpdates = clause.AssignmentColumns(opts.UpdateColumns)
+			}
+			stmt = stmt.Clauses(onConflict)
+		}
+		return stmt.Create(&vs).Error
+	}
+	if insert(batch) == nil {
+		report.Imported += len(batch)
+		return
+	}
+	for i := range batch {
+		if err := insert(batch[i : i+1]); err != nil {
+			report.Errors = append(report.Errors, RowError{Row: rows[i], Err: err})
+			continue
+		}
+		report.Imported++
+	}
+}
+
+var errUnreadable = errors.New("unreadable input")
+
+// rowReader returns function reading next row as column map; io.EOF at the end
+func (g GenericCRUD[T]) rowReader(r io.Reader, typ ExportType) (func() (map[string]any, error), error) {
+	switch typ {
+	case CSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		header, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("csv header: %w", err)
+		}
+		return func() (map[string]any, error) {
+			record, err := cr.Read()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil, err
+				}
+				return nil, fmt.Errorf("%w: %s", errUnreadable, err)
+			}
+			if len(record) != len(header) {
+				return nil, fmt.Errorf("expected %d fields, got %d", len(header), len(record))
+			}
+			m := make(map[string]any, len(header))
+			for i, c := range header {
+				if record[i] != "" {
+					m[c] = record[i]
+				}
+			}
+			return m, nil
+		}, nil
+	case JSONL:
+		sc := bufio.NewScanner(r)
+		sc.Buffer(nil, 1<<20)
+		return func() (map[string]any, error) {
+			for sc.Scan() {
+				if len(sc.Bytes()) == 0 {
+					continue
+				}
+				var m map[string]any
+				err := json.Unmarshal(sc.Bytes(), &m)
+				return m, err
+			}
+			if err := sc.Err(); err != nil {
+				return nil, fmt.Errorf("%w: %s", errUnreadable, err)
+			}
+			return nil, io.EOF
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown import type %d", typ)
+	}
+}
@@ -0,0 +1,21 @@
+package crud
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportUpsertSQL(t *testing.T) {
+	stmts, err := New[User](dryRunDB(t)).DryRun(context.TODO(), func(ctx context.Context, g GenericCRUD[User]) error {
+		_, err := g.Import(ctx, strings.NewReader("id,name,age\n1,ann,30\n"), ImportOptions[User]{
+			Type: CSV, Upsert: true, UpdateColumns: []string{"age"},
+		})
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, stmts, 1)
+	require.Contains(t, stmts[0].SQL, `ON CONFLICT ("id") DO UPDATE SET "age"="excluded"."age"`)
+}
@@ -0,0 +1,127 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+type (
+	// Index declares index created by EnsureIndexes, including ones gorm tags can't express
+	Index struct {
+		Name string
+		// Columns are column names or expressions, e.g. "lower(email)"
+		Columns []string
+		Unique  bool
+		// Type is index method, e.g. "gin" or "gist"; Postgres only
+		Type string
+		// Where makes partial index, e.g. "deleted_at IS NULL"; Postgres and SQLite only
+		Where string
+	}
+
+	// Indexer is implemented by models declaring indexes besides ones of gorm `index` tags
+	Indexer interface {
+		Indexes() []Index
+	}
+
+	// IndexReport lists indexes of Table handled by EnsureIndexes
+	IndexReport struct {
+		Table    string
+		Created  []string
+		Existing []string
+	}
+)
+
+// String implements fmt.Stringer
+func (r IndexReport) String() string {
+	return fmt.Sprintf("%s: created indexes %v, existing %v", r.Table, r.Created, r.Existing)
+}
+
+// EnsureIndexes creates missing indexes of T declared by gorm `index` tags, Indexer and soft_unique tags; complements
+// AutoMigrate, which doesn't create indexes on expressions. Existing indexes are matched by name and never altered.
+// Soft unique indexes are partial on Postgres and SQLite; elsewhere they are not created and inserts check conflicts instead
+func (g GenericCRUD[T]) EnsureIndexes(ctx context.Context) (IndexReport, error) {
+	var v T
+	s, err := g.schema()
+	if err != nil {
+		return IndexReport{}, err
+	}
+	table, err := g.tableName(ctx, v)
+	if err != nil {
+		return IndexReport{}, err
+	}
+	report := IndexReport{Table: table}
+	db := g.session(ctx)
+	migrator := db.Migrator()
+	tagged := s.ParseIndexes()
+	names := make([]string, 0, len(tagged))
+	for name := range tagged {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if migrator.HasIndex(&v, name) {
+			report.Existing = append(report.Existing, name)
+			continue
+		}
+		if err = migrator.CreateIndex(&v, name); err != nil {
+			return report, fmt.Errorf("index %s: %w", name, err)
+		}
+		report.Created = append(report.Created, name)
+	}
+	var declared []Index
+	if indexer, ok := any(v).(Indexer); ok {
+		declared = indexer.Indexes()
+	}
+	for _, idx := range g.softUniqueIndexes(s) {
+		// checked before insert instead
+		if idx.Where == "" || partialIndexes(g.Dialect()) {
+			declared = append(declared, idx)
+		}
+	}
+	for _, idx := range declared {
+		sql, err := idx.build(g.Dialect())
+		if err != nil {
+			return report, err
+		}
+		if migrator.HasIndex(report.Table, idx.Name) {
+			report.Existing = append(report.Existing, idx.Name)
+			continue
+		}
+		if err = db.Exec(sql, clause.Column{Name: idx.Name}, clause.Table{Name: report.Table}).Error; err != nil {
+			return report, fmt.Errorf("index %s: %w", idx.Name, err)
+		}
+		report.Created = append(report.Created, idx.Name)
+	}
+	return report, nil
+}
+
+// build returns CREATE INDEX statement with index name and table vars
+func (idx Index) build(dialect Dialect) (string, error) {
+	if idx.Name == "" || len(idx.Columns) == 0 {
+		return "", fmt.Errorf("index %q: name and columns are required", idx.Name)
+	}
+	if idx.Type != "" && dialect != Postgres {
+		return "", fmt.Errorf("index %s: index type is not supported for %q", idx.Name, dialect)
+	}
+	if idx.Where != "" && dialect == MySQL {
+		return "", fmt.Errorf("index %s: partial indexes are not supported for %q", idx.Name, dialect)
+	}
+	var sql strings.Builder
+	sql.WriteString("CREATE ")
+	if idx.Unique {
+		sql.WriteString("UNIQUE ")
+	}
+	sql.WriteString("INDEX ? ON ?")
+	if idx.Type != "" {
+		sql.WriteString(" USING " + idx.Type)
+	}
+	sql.WriteString(" (" + strings.Join(idx.Columns, ", ") + ")")
+	if idx.Where != "" {
+		sql.WriteString(" WHERE " + idx.Where)
+	}
+	return sql.String(), nil
+}
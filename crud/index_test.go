@@ -0,0 +1,133 @@
+package crud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type document struct {
+	gorm.Model
+	Title  string `gorm:"index:idx_documents_title"`
+	Email  string
+	Status string
+}
+
+func (d document) PrimaryKey() any {
+	return d.ID
+}
+
+func (document) Indexes() []Index {
+	return []Index{
+		{Name: "idx_documents_email_lower", Columns: []string{"lower(email)"}, Unique: true, Where: "deleted_at IS NULL"},
+		{Name: "idx_documents_status", Columns: []string{"status"}},
+	}
+}
+
+func TestEnsureIndexes(t *testing.T) {
+	db := benchDB(t, "indexes")
+	require.NoError(t, db.Migrator().CreateTable(&document{}))
+	require.NoError(t, db.Migrator().DropIndex(&document{}, "idx_documents_title"))
+	g := New[document](db)
+	report, err := g.EnsureIndexes(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, IndexReport{
+		Table:    "documents",
+		Created:  []string{"idx_documents_title", "idx_documents_email_lower", "idx_documents_status"},
+		Existing: []string{"idx_documents_deleted_at"},
+	}, report)
+	require.True(t, db.Migrator().HasIndex(&document{}, "idx_documents_email_lower"))
+
+	report, err = g.EnsureIndexes(context.TODO())
+	require.NoError(t, err)
+	require.Empty(t, report.Created)
+	require.Len(t, report.Existing, 4)
+
+	_, err = Index{Name: "idx_tags", Columns: []string{"tags"}, Type: "gin"}.build(SQLite)
+	require.Error(t, err)
+	sql, err := Index{Name: "idx_tags", Columns: []string{"tags"}, Type: "gin", Where: "tags IS NOT NULL"}.build(Postgres)
+	require.NoError(t, err)
+	require.Equal(t, "CREATE INDEX ? ON ? USING gin (tags) WHERE tags IS NOT NULL", sql)
+}
+
+func TestSchema(t *testing.T) {
+	s, err := New[document](dryRunDB(t)).Schema()
+	require.NoError(t, err)
+	require.Equal(t, "document", s.Name)
+	require.Equal(t, "documents", s.Table)
+	require.Equal(t, []string{"id"}, s.PrimaryKey)
+	names := make([]string, len(s.Indexes))
+	for i, idx := range s.Indexes {
+		names[i] = idx.Name
+	}
+	require.Equal(t, []string{"idx_documents_deleted_at", "idx_documents_email_lower", "idx_documents_status", "idx_documents_title"}, names)
+	title, ok := s.Column("title")
+	require.True(t, ok)
+	require.Equal(t, ColumnSchema{
+		Name: "title", Field: "Title", DataType: "string", SQLType: "text", GoType: "string", Indexed: true,
+	}, title)
+	id, _ := s.Column("id")
+	require.True(t, id.PrimaryKey && id.NotNull && id.Indexed)
+	email, _ := s.Column("email")
+	require.False(t, email.Indexed)
+	_, ok = s.Column("missing")
+	require.False(t, ok)
+}
+
+type member struct {
+	gorm.Model
+	Email    string `crud:"soft_unique"`
+	TenantID uint   `crud:"soft_unique:uidx_members_tenant_login"`
+	Login    string `crud:"soft_unique:uidx_members_tenant_login"`
+}
+
+func (m member) PrimaryKey() any {
+	return m.ID
+}
+
+func TestSoftUnique(t *testing.T) {
+	db := benchDB(t, "soft_unique")
+	require.NoError(t, db.AutoMigrate(&member{}))
+	g := New[member](db)
+	constraints, err := g.SoftUniques()
+	require.NoError(t, err)
+	require.Equal(t, []SoftUnique{
+		{Name: "uidx_members_email", Columns: []string{"email"}},
+		{Name: "uidx_members_tenant_login", Columns: []string{"tenant_id", "login"}},
+	}, constraints)
+	report, err := g.EnsureIndexes(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, []string{"uidx_members_email", "uidx_members_tenant_login"}, report.Created)
+
+	ctx := context.TODO()
+	a, err := g.Create(ctx, member{Email: "a@example.com", TenantID: 1, Login: "a"})
+	require.NoError(t, err)
+	_, err = g.Create(ctx, member{Email: "a@example.com", TenantID: 1, Login: "b"})
+	var conflict UniqueConflictError
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, "uidx_members_email", conflict.Constraint)
+	require.Error(t, conflict.Err)
+	b, err := g.Create(ctx, member{Email: "b@example.com", TenantID: 1, Login: "b"})
+	require.NoError(t, err)
+	err = g.UpdateFieldsMap(ctx, *b, map[string]any{"login": "a"})
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, []string{"tenant_id", "login"}, conflict.Columns)
+
+	require.NoError(t, g.Delete(ctx, *a))
+	_, err = g.Create(ctx, member{Email: "a@example.com", TenantID: 1, Login: "a"})
+	require.NoError(t, err)
+
+	// existence check used without partial indexes
+	s, err := g.schema()
+	require.NoError(t, err)
+	checked := g.withSoftUnique(g.session(ctx), softUniques(s), true)
+	err = checked.Create(&member{Email: "a@example.com", TenantID: 2, Login: "c"}).Error
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, []any{"a@example.com"}, conflict.Values)
+	require.NoError(t, conflict.Err)
+	require.EqualError(t, err, "unique constraint uidx_members_email of email violated by [a@example.com]")
+	require.NoError(t, g.withSoftUnique(g.session(ctx), softUniques(s), true).
+		Create(&member{Email: "c@example.com", TenantID: 2, Login: "c"}).Error)
+}
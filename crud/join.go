@@ -0,0 +1,89 @@
+package crud
+
+import (
+	"context"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JoinCRUD manages explicit join table linking L and R, e.g. with extra columns gorm many2many can't set
+type JoinCRUD[L, R GORMModel] struct {
+	left        GenericCRUD[L]
+	right       GenericCRUD[R]
+	table       string
+	leftColumn  string
+	rightColumn string
+}
+
+// NewJoinCRUD is a constructor; table MUST have unique index on (leftColumn, rightColumn)
+func NewJoinCRUD[L, R GORMModel](left GenericCRUD[L], right GenericCRUD[R], table, leftColumn, rightColumn string) JoinCRUD[L, R] {
+	return JoinCRUD[L, R]{left: left, right: right, table: table, leftColumn: leftColumn, rightColumn: rightColumn}
+}
+
+// conn returns statement on join table in transaction of ctx, if any, with session options of left
+func (j JoinCRUD[L, R]) conn(ctx context.Context) *gorm.DB {
+	return j.left.connSession(ctx).Table(j.table)
+}
+
+// Link l and r setting extra columns of join row; existing link gets extra columns updated
+func (j JoinCRUD[L, R]) Link(ctx context.Context, l L, r R, extra map[string]any) error {
+	row := map[string]any{j.leftColumn: l.PrimaryKey(), j.rightColumn: r.PrimaryKey()}
+	onConflict := clause.OnConflict{
+		Columns: []clause.Column{{Name: j.leftColumn}, {Name: j.rightColumn}},
+	}
+	if len(extra) == 0 {
+		onConflict.DoNothing = true
+	} else {
+		columns := make([]string, 0, len(extra))
+		for k, v := range extra {
+			row[k] = v
+			columns = append(columns, k)
+		}
+		sort.Strings(columns)
+		onConflict.DoUpdates = clause.AssignmentColumns(columns)
+	}
+	return j.conn(ctx).Clauses(onConflict).Create(row).Error
+}
+
+// Unlink l and r; returns whether link existed
+func (j JoinCRUD[L, R]) Unlink(ctx context.Context, l L, r R) (bool, error) {
+	res := j.conn(ctx).Where(map[string]any{j.leftColumn: l.PrimaryKey(), j.rightColumn: r.PrimaryKey()}).
+		Delete(map[string]any{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// Linked reports whether l and r are linked
+func (j JoinCRUD[L, R]) Linked(ctx context.Context, l L, r R) (bool, error) {
+	var n int64
+	err := j.conn(ctx).Where(map[string]any{j.leftColumn: l.PrimaryKey(), j.rightColumn: r.PrimaryKey()}).Count(&n).Error
+	return n > 0, err
+}
+
+// ListRight returns R linked to l and matching q
+func (j JoinCRUD[L, R]) ListRight(ctx context.Context, l L, q Query) ([]*R, error) {
+	pk, err := j.right.PrimaryKeyColumn()
+	if err != nil {
+		return nil, err
+	}
+	set(&q.InQuery, pk, j.linked(j.leftColumn, j.rightColumn, l.PrimaryKey()))
+	return j.right.SmartQuery(ctx, q)
+}
+
+// ListLeft returns L linked to r and matching q
+func (j JoinCRUD[L, R]) ListLeft(ctx context.Context, r R, q Query) ([]*L, error) {
+	pk, err := j.left.PrimaryKeyColumn()
+	if err != nil {
+		return nil, err
+	}
+	set(&q.InQuery, pk, j.linked(j.rightColumn, j.leftColumn, r.PrimaryKey()))
+	return j.left.SmartQuery(ctx, q)
+}
+
+// linked returns subquery selecting column of join rows having by = id
+func (j JoinCRUD[L, R]) linked(by, column string, id any) Subquery {
+	return Subquery{build: func(ctx context.Context) (*gorm.DB, error) {
+		return j.conn(ctx).Select(column).Where(map[string]any{by: id}), nil
+	}}
+}
@@ -0,0 +1,189 @@
+package crud
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+var patternModes = map[PatternMode]string{Contains: "contains", Prefix: "prefix", Suffix: "suffix", Exact: "exact"}
+
+// MarshalText encodes OrderBy as "ASC" or "DESC"
+func (ob OrderBy) MarshalText() ([]byte, error) {
+	if ob != ASC && ob != DESC {
+		return nil, fmt.Errorf("%w: unknown order %d", InvalidFilterError, ob)
+	}
+	return []byte(ob.String()), nil
+}
+
+// UnmarshalText decodes case-insensitive "ASC" or "DESC"
+func (ob *OrderBy) UnmarshalText(text []byte) error {
+	switch strings.ToUpper(string(text)) {
+	case "ASC":
+		*ob = ASC
+	case "DESC":
+		*ob = DESC
+	default:
+		return fmt.Errorf("%w: unknown order %q", InvalidFilterError, text)
+	}
+	return nil
+}
+
+// MarshalText encodes PatternMode as its name
+func (m PatternMode) MarshalText() ([]byte, error) {
+	if s, ok := patternModes[m]; ok {
+		return []byte(s), nil
+	}
+	return nil, fmt.Errorf("%w: unknown pattern mode %d", InvalidFilterError, m)
+}
+
+// UnmarshalText decodes PatternMode from its name
+func (m *PatternMode) UnmarshalText(text []byte) error {
+	for k, v := range patternModes {
+		if v == string(text) {
+			*m = k
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown pattern mode %q", InvalidFilterError, text)
+}
+
+// UnmarshalJSON decodes Query rejecting unknown operators
+func (q *Query) UnmarshalJSON(data []byte) error {
+	type query Query
+	var v query
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("%w: %s", InvalidFilterError, err)
+	}
+	*q = Query(v)
+	return nil
+}
+
+// DecodeQuery decodes JSON Query and validates it against allowed columns (all columns of T if empty)
+func (g GenericCRUD[T]) DecodeQuery(data []byte, allowed ...string) (Query, error) {
+	var q Query
+	if err := json.Unmarshal(data, &q); err != nil {
+		return q, err
+	}
+	return q, g.ValidateQuery(q, allowed...)
+}
+
+// ValidateQuery checks that q references only allowed columns and known relations; if allowed is empty,
+// columns tagged crud:"filterable" and crud:"sortable" (for OrderBy) are allowed, or all columns of T if none is tagged
+func (g GenericCRUD[T]) ValidateQuery(q Query, allowed ...string) error {
+	columns, err := g.allowedColumns(allowed, TagFilterable)
+	if err != nil {
+		return err
+	}
+	sortable, err := g.allowedColumns(allowed, TagSortable)
+	if err != nil {
+		return err
+	}
+	all, err := g.allowedColumns(allowed, "")
+	if err != nil {
+		return err
+	}
+	for _, o := range q.OrderBy {
+		if _, ok := sortable[o.Column]; !ok {
+			return fmt.Errorf("%w: unknown sort column %q", InvalidFilterError, o.Column)
+		}
+		if err = o.validate(); err != nil {
+			return err
+		}
+	}
+	for _, c := range q.Omit {
+		if _, ok := all[c]; !ok {
+			return fmt.Errorf("%w: unknown column %q", InvalidFilterError, c)
+		}
+	}
+	if _, err = g.collation(q); err != nil {
+		return err
+	}
+	if q.Window != nil {
+		if _, err = q.Window.function(); err != nil {
+			return err
+		}
+	}
+	filters := q
+	filters.OrderBy, filters.Omit = nil, nil
+	for _, c := range filters.Columns() {
+		if _, ok := columns[c]; !ok {
+			return fmt.Errorf("%w: unknown column %q", InvalidFilterError, c)
+		}
+	}
+	if len(q.Preload) > 0 {
+		s, err := g.schema()
+		if err != nil {
+			return err
+		}
+		for _, p := range q.Preload {
+			name, _, _ := strings.Cut(p, ".")
+			if _, ok := s.Relationships.Relations[name]; !ok {
+				return fmt.Errorf("%w: unknown relation %q", InvalidFilterError, p)
+			}
+		}
+	}
+	if q.Limit < 0 || q.Offset < 0 {
+		return fmt.Errorf("%w: negative limit or offset", InvalidFilterError)
+	}
+	return g.checkEnumQuery(q)
+}
+
+// Columns returns all columns referenced by q
+func (q Query) Columns() []string {
+	var res []string
+	res = append(res, q.Omit...)
+	for _, o := range q.OrderBy {
+		res = append(res, o.Column)
+	}
+	res = appendKeys(res, q.Equal)
+	res = appendKeys(res, q.Like)
+	res = appendKeys(res, q.ILike)
+	res = appendKeys(res, q.Pattern)
+	res = appendKeys(res, q.Between)
+	res = appendKeys(res, q.NotEqual)
+	res = appendKeys(res, q.NotLike)
+	res = appendKeys(res, q.NotBetween)
+	res = appendKeys(res, q.Gt)
+	res = appendKeys(res, q.Gte)
+	res = appendKeys(res, q.Lt)
+	res = appendKeys(res, q.Lte)
+	res = appendKeys(res, q.In)
+	res = appendKeys(res, q.InQuery)
+	res = appendKeys(res, q.ArrayContains)
+	res = appendKeys(res, q.ArrayContainedBy)
+	res = appendKeys(res, q.ArrayOverlaps)
+	res = appendKeys(res, q.ArrayAny)
+	if q.FullText != nil {
+		res = append(res, q.FullText.Columns...)
+	}
+	if q.Radius != nil {
+		res = append(res, q.Radius.Column)
+	}
+	if q.BBox != nil {
+		res = append(res, q.BBox.Column)
+	}
+	if q.Owner != nil {
+		typeColumn, idColumn := q.Owner.Columns()
+		res = append(res, typeColumn, idColumn)
+	}
+	res = append(res, q.GroupBy...)
+	if q.Window != nil {
+		res = append(res, q.Window.PartitionBy...)
+		for _, o := range q.Window.OrderBy {
+			res = append(res, o.Column)
+		}
+	}
+	return res
+}
+
+func appendKeys[V any](s []string, m map[string]V) []string {
+	for k := range m {
+		s = append(s, k)
+	}
+	return s
+}
@@ -0,0 +1,40 @@
+package crud
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryJSON(t *testing.T) {
+	q := Query{
+		OrderBy: []OrderClause{{Column: "created_at", Direction: DESC}},
+		Equal:   map[string]any{"name": "test"},
+		Pattern: map[string]Pattern{"name": {Value: "te", Mode: Prefix}},
+		Limit:   10,
+	}
+	data, err := json.Marshal(q)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"order_by": [{"column": "created_at", "direction": "DESC"}],
+		"equal": {"name": "test"},
+		"pattern": {"name": {"value": "te", "mode": "prefix"}},
+		"limit": 10
+	}`, string(data))
+
+	g := New[User](dryRunDB(t))
+	decoded, err := g.DecodeQuery(data)
+	require.NoError(t, err)
+	require.Equal(t, q, decoded)
+
+	for _, s := range []string{
+		`{"regex": {"name": "x"}}`,
+		`{"order_by": {"name": "sideways"}}`,
+		`{"equal": {"password": "x"}}`,
+		`{"preload": ["Secrets"]}`,
+	} {
+		_, err = g.DecodeQuery([]byte(s))
+		require.ErrorIs(t, err, InvalidFilterError, s)
+	}
+}
@@ -0,0 +1,380 @@
+package crud
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PatchFormat is format of ApplyJSONPatch document
+type PatchFormat uint
+
+const (
+	// JSONPatch is RFC 6902 list of operations
+	JSONPatch PatchFormat = iota
+	// MergePatch is RFC 7386 partial document; null removes (sets NULL)
+	MergePatch
+)
+
+// PatchError is returned for malformed patch or failed "test" operation
+var PatchError = errors.New("invalid patch")
+
+// ApplyJSONPatch loads row with primary key id, applies patch to its document keyed by column name
+// and updates changed columns in one transaction; returns updated row.
+// Changes of unknown, primary key and hidden columns are rejected as in Patch
+func (g GenericCRUD[T]) ApplyJSONPatch(ctx context.Context, id any, patch []byte, format PatchFormat) (*T, error) {
+	v, err := g.FromID(id)
+	if err != nil {
+		return nil, err
+	}
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	var res *T
+	err = g.RunInTransaction(ctx, func(ctx context.Context, tx GenericCRUD[T]) error {
+		current, err := tx.GetByID(ctx, v)
+		if err != nil {
+			return err
+		}
+		doc, err := tx.document(*current)
+		if err != nil {
+			return err
+		}
+		var patched any
+		switch format {
+		case JSONPatch:
+			patched, err = applyJSONPatch(doc, patch)
+		case MergePatch:
+			var p any
+			if err = json.Unmarshal(patch, &p); err != nil {
+				return fmt.Errorf("%w: %s", PatchError, err)
+			}
+			patched = mergePatch(doc, p)
+		default:
+			return fmt.Errorf("%w: unknown format %d", PatchError, format)
+		}
+		if err != nil {
+			return err
+		}
+		m, ok := patched.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%w: document must stay an object", PatchError)
+		}
+		changed := make(map[string]any)
+		for k, value := range m {
+			if old, ok := doc[k]; !ok || !reflect.DeepEqual(old, value) {
+				changed[k] = value
+			}
+		}
+		for k := range doc {
+			if _, ok := m[k]; !ok {
+				changed[k] = nil
+			}
+		}
+		if changed, err = tx.patchColumns(ctx, changed); err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			res = current
+			return nil
+		}
+		// convert JSON values to column types via model fields
+		rv := reflect.ValueOf(current).Elem()
+		for k, value := range changed {
+			field := s.LookUpField(k)
+			if err = field.Set(ctx, rv, value); err != nil {
+				return fmt.Errorf("column %s: %w", k, err)
+			}
+			changed[k], _ = field.ValueOf(ctx, rv)
+		}
+		if err = tx.UpdateMap(ctx, v, changed); err != nil {
+			return err
+		}
+		res, err = tx.GetByID(ctx, v)
+		return err
+	})
+	return res, err
+}
+
+// document returns v as JSON object keyed by column name; driver.Valuer fields are encoded by their value
+func (g GenericCRUD[T]) document(v T) (map[string]any, error) {
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	rv := reflect.ValueOf(&v).Elem()
+	m := make(map[string]any, len(s.DBNames))
+	for _, f := range s.Fields {
+		if f.DBName == "" {
+			continue
+		}
+		value, _ := f.ValueOf(context.Background(), rv)
+		if valuer, ok := value.(driver.Valuer); ok {
+			if value, err = valuer.Value(); err != nil {
+				return nil, fmt.Errorf("column %s: %w", f.DBName, err)
+			}
+		}
+		m[f.DBName] = value
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err = json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// mergePatch applies RFC 7386 patch to target
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	} else {
+		t = copyObject(t)
+	}
+	for k, value := range p {
+		if value == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], value)
+	}
+	return t
+}
+
+func copyObject(m map[string]any) map[string]any {
+	res := make(map[string]any, len(m))
+	for k, v := range m {
+		res[k] = v
+	}
+	return res
+}
+
+type patchOperation struct {
+	Op    string           `json:"op"`
+	Path  string           `json:"path"`
+	From  string           `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// applyJSONPatch applies RFC 6902 operations to doc
+func applyJSONPatch(doc map[string]any, patch []byte) (any, error) {
+	var ops []patchOperation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("%w: %s", PatchError, err)
+	}
+	var res any = deepCopy(doc)
+	for i, op := range ops {
+		var err error
+		value := func() (any, error) {
+			if op.Value == nil {
+				return nil, errors.New("missing value")
+			}
+			var v any
+			return v, json.Unmarshal(*op.Value, &v)
+		}
+		switch op.Op {
+		case "add", "replace", "test":
+			var v any
+			if v, err = value(); err != nil {
+				break
+			}
+			switch op.Op {
+			case "add":
+				res, err = pointerAdd(res, op.Path, v)
+			case "replace":
+				if res, _, err = pointerRemove(res, op.Path); err == nil {
+					res, err = pointerAdd(res, op.Path, v)
+				}
+			case "test":
+				var current any
+				if current, err = pointerGet(res, op.Path); err == nil && !reflect.DeepEqual(current, v) {
+					err = errors.New("test failed")
+				}
+			}
+		case "remove":
+			res, _, err = pointerRemove(res, op.Path)
+		case "move":
+			var v any
+			if res, v, err = pointerRemove(res, op.From); err == nil {
+				res, err = pointerAdd(res, op.Path, v)
+			}
+		case "copy":
+			var v any
+			if v, err = pointerGet(res, op.From); err == nil {
+				res, err = pointerAdd(res, op.Path, deepCopy(v))
+			}
+		default:
+			err = fmt.Errorf("unknown op %q", op.Op)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: operation %d (%s %s): %s", PatchError, i, op.Op, op.Path, err)
+		}
+	}
+	return res, nil
+}
+
+// pointerTokens splits RFC 6901 JSON pointer
+func pointerTokens(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func pointerGet(doc any, pointer string) (any, error) {
+	tokens, err := pointerTokens(pointer)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		switch node := doc.(type) {
+		case map[string]any:
+			v, ok := node[t]
+			if !ok {
+				return nil, fmt.Errorf("%q not found", pointer)
+			}
+			doc = v
+		case []any:
+			i, err := arrayIndex(t, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("%q not found", pointer)
+		}
+	}
+	return doc, nil
+}
+
+// pointerAdd returns doc with value added at pointer; modifies doc in place except for root
+func pointerAdd(doc any, pointer string, value any) (any, error) {
+	tokens, err := pointerTokens(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, err := pointerGet(doc, pointer[:strings.LastIndex(pointer, "/")])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[last] = value
+	case []any:
+		i := len(node)
+		if last != "-" {
+			if i, err = arrayIndex(last, len(node)); err != nil {
+				return nil, err
+			}
+		}
+		node = append(node, nil)
+		copy(node[i+1:], node[i:])
+		node[i] = value
+		return setParent(doc, tokens[:len(tokens)-1], node)
+	default:
+		return nil, fmt.Errorf("%q not found", pointer)
+	}
+	return doc, nil
+}
+
+// pointerRemove returns doc with value at pointer removed and the value
+func pointerRemove(doc any, pointer string) (any, any, error) {
+	tokens, err := pointerTokens(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, doc, nil
+	}
+	parent, err := pointerGet(doc, pointer[:strings.LastIndex(pointer, "/")])
+	if err != nil {
+		return nil, nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		v, ok := node[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("%q not found", pointer)
+		}
+		delete(node, last)
+		return doc, v, nil
+	case []any:
+		i, err := arrayIndex(last, len(node)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		v := node[i]
+		doc, err = setParent(doc, tokens[:len(tokens)-1], append(node[:i:i], node[i+1:]...))
+		return doc, v, err
+	default:
+		return nil, nil, fmt.Errorf("%q not found", pointer)
+	}
+}
+
+// setParent replaces array at tokens path, as append may reallocate it
+func setParent(doc any, tokens []string, value []any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	pointer := ""
+	for _, t := range tokens {
+		pointer += "/" + strings.ReplaceAll(strings.ReplaceAll(t, "~", "~0"), "/", "~1")
+	}
+	if _, _, err := pointerRemove(doc, pointer); err != nil {
+		return nil, err
+	}
+	return pointerAdd(doc, pointer, value)
+}
+
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	return i, nil
+}
+
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		for k, e := range v {
+			res[k] = deepCopy(e)
+		}
+		return res
+	case []any:
+		res := make([]any, len(v))
+		for i, e := range v {
+			res[i] = deepCopy(e)
+		}
+		return res
+	default:
+		return v
+	}
+}
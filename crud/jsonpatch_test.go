@@ -0,0 +1,46 @@
+package crud
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePatch(t *testing.T) {
+	var target, patch any
+	require.NoError(t, json.Unmarshal([]byte(`{"a":"b","c":{"d":"e","f":"g"}}`), &target))
+	require.NoError(t, json.Unmarshal([]byte(`{"a":"z","c":{"f":null},"h":[1]}`), &patch))
+	require.Equal(t, map[string]any{"a": "z", "c": map[string]any{"d": "e"}, "h": []any{1.0}}, mergePatch(target, patch))
+	require.Equal(t, map[string]any{"a": "b", "c": map[string]any{"d": "e", "f": "g"}}, target)
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	doc := map[string]any{"name": "a", "tags": []any{"x", "y"}, "meta": map[string]any{"k/1": "v"}}
+	res, err := applyJSONPatch(doc, []byte(`[
+		{"op": "test", "path": "/name", "value": "a"},
+		{"op": "replace", "path": "/name", "value": "b"},
+		{"op": "add", "path": "/tags/1", "value": "z"},
+		{"op": "add", "path": "/tags/-", "value": "w"},
+		{"op": "remove", "path": "/tags/0"},
+		{"op": "move", "from": "/meta/k~11", "path": "/moved"},
+		{"op": "copy", "from": "/moved", "path": "/meta/copy"}
+	]`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"name":  "b",
+		"tags":  []any{"z", "y", "w"},
+		"meta":  map[string]any{"copy": "v"},
+		"moved": "v",
+	}, res)
+	require.Equal(t, "a", doc["name"], "source document is not modified")
+
+	_, err = applyJSONPatch(doc, []byte(`[{"op": "test", "path": "/name", "value": "b"}]`))
+	require.ErrorIs(t, err, PatchError)
+	_, err = applyJSONPatch(doc, []byte(`[{"op": "remove", "path": "/unknown"}]`))
+	require.ErrorIs(t, err, PatchError)
+	_, err = applyJSONPatch(doc, []byte(`[{"op": "add", "path": "/tags/5", "value": 1}]`))
+	require.ErrorIs(t, err, PatchError)
+	_, err = applyJSONPatch(doc, []byte(`[{"op": "add", "path": "/name"}]`))
+	require.ErrorIs(t, err, PatchError)
+}
@@ -0,0 +1,45 @@
+package crud
+
+import "strings"
+
+type (
+	// PatternMode defines wildcard placement of Pattern
+	PatternMode uint
+
+	// Pattern is a structured LIKE condition
+	Pattern struct {
+		// Value is matched literally; LIKE wildcards in it are escaped
+		Value string      `json:"value"`
+		Mode  PatternMode `json:"mode,omitempty"`
+		// Insensitive makes match case-insensitive (ILIKE on Postgres)
+		Insensitive bool `json:"insensitive,omitempty"`
+	}
+)
+
+const (
+	// Contains matches %value%
+	Contains PatternMode = iota
+	// Prefix matches value%; can use btree index
+	Prefix
+	// Suffix matches %value
+	Suffix
+	// Exact matches value without wildcards
+	Exact
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// pattern returns LIKE pattern for p
+func (p Pattern) pattern() string {
+	v := likeEscaper.Replace(p.Value)
+	switch p.Mode {
+	case Prefix:
+		return v + "%"
+	case Suffix:
+		return "%" + v
+	case Exact:
+		return v
+	default:
+		return "%" + v + "%"
+	}
+}
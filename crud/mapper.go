@@ -0,0 +1,80 @@
+package crud
+
+import "context"
+
+// Mapper wraps GenericCRUD to accept and return DTO type D instead of model T
+type Mapper[T GORMModel, D any] struct {
+	crud   GenericCRUD[T]
+	encode func(T) D
+	decode func(D) T
+}
+
+// NewMapper is a constructor; encode converts model to DTO, decode converts DTO to model
+func NewMapper[T GORMModel, D any](crud GenericCRUD[T], encode func(T) D, decode func(D) T) Mapper[T, D] {
+	return Mapper[T, D]{crud: crud, encode: encode, decode: decode}
+}
+
+// CRUD returns underlying GenericCRUD
+func (m Mapper[T, D]) CRUD() GenericCRUD[T] {
+	return m.crud
+}
+
+// Create DTO
+func (m Mapper[T, D]) Create(ctx context.Context, d D, omit ...string) (*D, error) {
+	return m.one(m.crud.Create(ctx, m.decode(d), omit...))
+}
+
+// GetByID get DTO by primary key; decoded d MUST have non-zero primary key
+func (m Mapper[T, D]) GetByID(ctx context.Context, d D) (*D, error) {
+	return m.one(m.crud.GetByID(ctx, m.decode(d)))
+}
+
+// Query by non-zero fields of decoded d
+func (m Mapper[T, D]) Query(ctx context.Context, d D, omit ...string) ([]*D, error) {
+	return m.many(m.crud.Query(ctx, m.decode(d), omit...))
+}
+
+// QueryOne by non-zero fields of decoded d; returns exactly one DTO or error
+func (m Mapper[T, D]) QueryOne(ctx context.Context, d D, omit ...string) (*D, error) {
+	return m.one(m.crud.QueryOne(ctx, m.decode(d), omit...))
+}
+
+// SmartQuery returns DTOs matching q
+func (m Mapper[T, D]) SmartQuery(ctx context.Context, q Query) ([]*D, error) {
+	return m.many(m.crud.SmartQuery(ctx, q))
+}
+
+// SmartQueryOne returns exactly one DTO matching q or error
+func (m Mapper[T, D]) SmartQueryOne(ctx context.Context, q Query) (*D, error) {
+	return m.one(m.crud.SmartQueryOne(ctx, q))
+}
+
+// Update by decoded d; filter by primary key if non-zero
+func (m Mapper[T, D]) Update(ctx context.Context, d D, omit ...string) error {
+	return m.crud.Update(ctx, m.decode(d), omit...)
+}
+
+// Delete by decoded d; filter by primary key if non-zero
+func (m Mapper[T, D]) Delete(ctx context.Context, d D) error {
+	return m.crud.Delete(ctx, m.decode(d))
+}
+
+func (m Mapper[T, D]) one(v *T, err error) (*D, error) {
+	if err != nil || v == nil {
+		return nil, err
+	}
+	d := m.encode(*v)
+	return &d, nil
+}
+
+func (m Mapper[T, D]) many(vs []*T, err error) ([]*D, error) {
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*D, len(vs))
+	for i, v := range vs {
+		d := m.encode(*v)
+		res[i] = &d
+	}
+	return res, nil
+}
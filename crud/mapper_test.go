@@ -0,0 +1,60 @@
+package crud
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type userDTO struct {
+	ID   uint
+	Name string
+	Age  int
+}
+
+func TestMapper(t *testing.T) {
+	ctx := context.TODO()
+	m := NewMapper(New[User](benchDB(t, "mapper")),
+		func(u User) userDTO { return userDTO{ID: u.ID, Name: u.Name, Age: int(u.Age.Int16)} },
+		func(d userDTO) User {
+			return User{Model: gorm.Model{ID: d.ID}, Name: d.Name, Age: sql.NullInt16{Int16: int16(d.Age), Valid: d.Age != 0}}
+		})
+
+	ann, err := m.Create(ctx, userDTO{Name: "ann", Age: 30})
+	require.NoError(t, err)
+	require.NotZero(t, ann.ID)
+	require.Equal(t, userDTO{ID: ann.ID, Name: "ann", Age: 30}, *ann)
+	_, err = m.Create(ctx, userDTO{Name: "bob", Age: 40})
+	require.NoError(t, err)
+
+	got, err := m.GetByID(ctx, userDTO{ID: ann.ID})
+	require.NoError(t, err)
+	require.Equal(t, *ann, *got)
+	got, err = m.QueryOne(ctx, userDTO{Name: "bob"})
+	require.NoError(t, err)
+	require.Equal(t, 40, got.Age)
+	res, err := m.Query(ctx, userDTO{Age: 30})
+	require.NoError(t, err)
+	require.Equal(t, []*userDTO{ann}, res)
+	res, err = m.SmartQuery(ctx, Query{Gte: map[string]any{"age": 30}, OrderBy: []OrderClause{{Column: "age", Direction: DESC}}})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, []string{"bob", "ann"}, []string{res[0].Name, res[1].Name})
+	got, err = m.SmartQueryOne(ctx, Query{Equal: map[string]any{"name": "ann"}})
+	require.NoError(t, err)
+	require.Equal(t, *ann, *got)
+
+	require.NoError(t, m.Update(ctx, userDTO{ID: ann.ID, Name: "anna", Age: 31}))
+	got, err = m.GetByID(ctx, userDTO{ID: ann.ID})
+	require.NoError(t, err)
+	require.Equal(t, userDTO{ID: ann.ID, Name: "anna", Age: 31}, *got)
+
+	require.NoError(t, m.Delete(ctx, userDTO{ID: ann.ID}))
+	_, err = m.GetByID(ctx, userDTO{ID: ann.ID})
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = m.SmartQueryOne(ctx, Query{Equal: map[string]any{"name": "nobody"}})
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,93 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+type (
+	// Drift describes difference between model schema and database
+	Drift struct {
+		Model          string
+		Table          string
+		MissingTable   bool
+		MissingColumns []string
+		MissingIndexes []string
+	}
+
+	// MigrateOptions configures Registry.MigrateAll
+	MigrateOptions struct {
+		// DryRun only reports drift without applying changes
+		DryRun bool
+		// Before and After run around AutoMigrate; not called in DryRun mode
+		Before, After []func(ctx context.Context, db *gorm.DB) error
+	}
+)
+
+// String implements fmt.Stringer
+func (d Drift) String() string {
+	if d.MissingTable {
+		return fmt.Sprintf("%s: missing table %s", d.Model, d.Table)
+	}
+	return fmt.Sprintf("%s: table %s missing columns %v, indexes %v", d.Model, d.Table, d.MissingColumns, d.MissingIndexes)
+}
+
+// MigrateAll reports drift of all registered models and, unless opts.DryRun, auto-migrates them
+func (r *Registry) MigrateAll(ctx context.Context, opts MigrateOptions) ([]Drift, error) {
+	db := r.db.WithContext(ctx)
+	models := r.Models()
+	drift, err := detectDrift(db, models)
+	if err != nil || opts.DryRun {
+		return drift, err
+	}
+	for _, fn := range opts.Before {
+		if err = fn(ctx, db); err != nil {
+			return drift, fmt.Errorf("before migration: %w", err)
+		}
+	}
+	if err = db.AutoMigrate(models...); err != nil {
+		return drift, fmt.Errorf("auto migrate: %w", err)
+	}
+	for _, fn := range opts.After {
+		if err = fn(ctx, db); err != nil {
+			return drift, fmt.Errorf("after migration: %w", err)
+		}
+	}
+	return drift, nil
+}
+
+// detectDrift returns drift of models; models without drift are skipped
+func detectDrift(db *gorm.DB, models []any) ([]Drift, error) {
+	var res []Drift
+	migrator := db.Migrator()
+	for _, m := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			return nil, err
+		}
+		d := Drift{Model: stmt.Schema.Name, Table: stmt.Schema.Table}
+		if !migrator.HasTable(m) {
+			d.MissingTable = true
+			res = append(res, d)
+			continue
+		}
+		for _, c := range stmt.Schema.DBNames {
+			if !migrator.HasColumn(m, c) {
+				d.MissingColumns = append(d.MissingColumns, c)
+			}
+		}
+		for name := range stmt.Schema.ParseIndexes() {
+			if !migrator.HasIndex(m, name) {
+				d.MissingIndexes = append(d.MissingIndexes, name)
+			}
+		}
+		sort.Strings(d.MissingIndexes)
+		if len(d.MissingColumns) > 0 || len(d.MissingIndexes) > 0 {
+			res = append(res, d)
+		}
+	}
+	return res, nil
+}
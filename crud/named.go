@@ -0,0 +1,158 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+)
+
+// UnknownQueryError is returned for name not registered by RegisterQuery
+var UnknownQueryError = errors.New("unknown named query")
+
+/*
+Param is placeholder of named query value, bound by NamedQuery params; it may be used as value of Equal,
+NotEqual, Gt, Gte, Lt, Lte and ArrayAny, Between bounds and elements of In and array conditions. Param as the
+only element of In is replaced by elements of slice param. Values of Like, ILike, NotLike and Pattern take
+placeholders as {name} within string:
+
+	users = users.RegisterQuery("active_users", crud.Query{
+		Equal: map[string]any{"status": "active"},
+		Gte:   map[string]any{"last_seen_at": crud.Param("since")},
+		Like:  map[string]string{"name": "{name}"},
+	})
+	res, err := users.NamedQuery(ctx, "active_users", map[string]any{"since": since, "name": "ann"})
+*/
+type Param string
+
+// RegisterQuery returns copy of g with q registered as name, replacing query of the same name
+func (g GenericCRUD[T]) RegisterQuery(name string, q Query) GenericCRUD[T] {
+	queries := make(map[string]Query, len(g.queries)+1)
+	for k, v := range g.queries {
+		queries[k] = v
+	}
+	queries[name] = q
+	g.queries = queries
+	return g
+}
+
+// Queries returns names of registered queries, sorted
+func (g GenericCRUD[T]) Queries() []string {
+	res := make([]string, 0, len(g.queries))
+	for name := range g.queries {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Named returns query registered as name with params bound, e.g. to Count or paginate it
+func (g GenericCRUD[T]) Named(name string, params map[string]any) (Query, error) {
+	q, ok := g.queries[name]
+	if !ok {
+		return Query{}, fmt.Errorf("%w: %q", UnknownQueryError, name)
+	}
+	return q.bind(params)
+}
+
+// NamedQuery runs SmartQuery of query registered as name with params bound
+func (g GenericCRUD[T]) NamedQuery(ctx context.Context, name string, params map[string]any) ([]*T, error) {
+	q, err := g.Named(name, params)
+	if err != nil {
+		return nil, err
+	}
+	return g.SmartQuery(ctx, q)
+}
+
+// bind returns copy of q with placeholders replaced by params; maps of q are not modified
+func (q Query) bind(params map[string]any) (Query, error) {
+	b := binder{params: params}
+	q.Equal = bindMap(q.Equal, b.value)
+	q.NotEqual = bindMap(q.NotEqual, b.value)
+	q.Gt = bindMap(q.Gt, b.value)
+	q.Gte = bindMap(q.Gte, b.value)
+	q.Lt = bindMap(q.Lt, b.value)
+	q.Lte = bindMap(q.Lte, b.value)
+	q.ArrayAny = bindMap(q.ArrayAny, b.value)
+	q.Like = bindMap(q.Like, b.string)
+	q.ILike = bindMap(q.ILike, b.string)
+	q.NotLike = bindMap(q.NotLike, b.string)
+	q.Pattern = bindMap(q.Pattern, func(p Pattern) Pattern {
+		p.Value = b.string(p.Value)
+		return p
+	})
+	q.Between = bindMap(q.Between, b.between)
+	q.NotBetween = bindMap(q.NotBetween, b.between)
+	q.In = bindMap(q.In, b.values)
+	q.ArrayContains = bindMap(q.ArrayContains, b.values)
+	q.ArrayContainedBy = bindMap(q.ArrayContainedBy, b.values)
+	q.ArrayOverlaps = bindMap(q.ArrayOverlaps, b.values)
+	return q, b.err
+}
+
+func bindMap[V any](m map[string]V, fn func(V) V) map[string]V {
+	if m == nil {
+		return nil
+	}
+	res := make(map[string]V, len(m))
+	for k, v := range m {
+		res[k] = fn(v)
+	}
+	return res
+}
+
+// binder replaces placeholders, recording first missing param
+type binder struct {
+	params map[string]any
+	err    error
+}
+
+var placeholder = regexp.MustCompile(`\{(\w+)\}`)
+
+func (b *binder) param(name string) any {
+	v, ok := b.params[name]
+	if !ok && b.err == nil {
+		b.err = fmt.Errorf("%w: missing parameter %q", InvalidFilterError, name)
+	}
+	return v
+}
+
+func (b *binder) value(v any) any {
+	if p, ok := v.(Param); ok {
+		return b.param(string(p))
+	}
+	return v
+}
+
+func (b *binder) string(s string) string {
+	return placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		return fmt.Sprint(b.param(m[1 : len(m)-1]))
+	})
+}
+
+func (b *binder) between(v Between) Between {
+	return Between{From: b.value(v.From), To: b.value(v.To)}
+}
+
+func (b *binder) values(vs []any) []any {
+	if len(vs) == 1 {
+		if p, ok := vs[0].(Param); ok {
+			v := b.param(string(p))
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+				res := make([]any, rv.Len())
+				for i := range res {
+					res[i] = rv.Index(i).Interface()
+				}
+				return res
+			}
+			return []any{v}
+		}
+	}
+	res := make([]any, len(vs))
+	for i, v := range vs {
+		res[i] = b.value(v)
+	}
+	return res
+}
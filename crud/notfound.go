@@ -0,0 +1,114 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxNotFound limits cached not-found primary keys per table; cache is reset when exceeded
+const maxNotFound = 10000
+
+// WithNotFoundCache returns copy of g remembering primary keys GetByID did not find for ttl,
+// returning gorm.ErrRecordNotFound for them without querying, e.g. against lookups of nonexistent IDs.
+// Any create or update of table clears its entries; calls in transaction or with SessionSettings are not cached
+func (g GenericCRUD[T]) WithNotFoundCache(ttl time.Duration) GenericCRUD[T] {
+	g.cfg.NotFoundTTL = ttl
+	return g
+}
+
+type (
+	// notFoundTable identifies table of database by its callbacks, shared by all sessions
+	notFoundTable struct {
+		db    any
+		table string
+	}
+
+	// notFoundCache holds expiration of not found primary keys per table
+	notFoundCache struct {
+		mu     sync.Mutex
+		tables map[notFoundTable]map[string]time.Time
+	}
+)
+
+var notFound notFoundCache
+
+func (c *notFoundCache) has(t notFoundTable, id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expires, ok := c.tables[t][id]
+	if ok && !now().Before(expires) {
+		delete(c.tables[t], id)
+		return false
+	}
+	return ok
+}
+
+func (c *notFoundCache) add(t notFoundTable, id string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tables == nil {
+		c.tables = make(map[notFoundTable]map[string]time.Time)
+	}
+	ids := c.tables[t]
+	if ids == nil || len(ids) >= maxNotFound {
+		ids = make(map[string]time.Time)
+		c.tables[t] = ids
+	}
+	ids[id] = now().Add(ttl)
+}
+
+func (c *notFoundCache) clear(t notFoundTable) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tables, t)
+}
+
+// cachedNotFound wraps GetByID fetch with not-found cache if it applies to ctx
+func (g GenericCRUD[T]) cachedNotFound(ctx context.Context, v T, fetch func() (*T, error)) (*T, error) {
+	if g.cfg.NotFoundTTL <= 0 || g.cfg.SessionSettings != nil || g.cfg.DryRun != nil {
+		return fetch()
+	}
+	if _, ok := TxFromContext(ctx); ok {
+		return fetch()
+	}
+	table, err := g.tableName(ctx, v)
+	if err != nil {
+		return fetch()
+	}
+	// writes report unqualified table, so entries are grouped by it
+	t, id := notFoundTable{g.db.Callback(), unqualified(table)}, table+"|"+fmt.Sprint(v.PrimaryKey())
+	if notFound.has(t, id) {
+		return &v, gorm.ErrRecordNotFound
+	}
+	res, err := fetch()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		notFound.add(t, id, g.cfg.NotFoundTTL)
+	}
+	return res, err
+}
+
+// unqualified returns table name without schema
+func unqualified(table string) string {
+	return table[strings.LastIndex(table, ".")+1:]
+}
+
+// registerNotFoundInvalidation adds callbacks clearing not-found cache of written table to db; see Setup
+func registerNotFoundInvalidation(db *gorm.DB) {
+	const name = "crud:not_found_invalidate"
+	if db.Callback().Create().Get(name) != nil {
+		return
+	}
+	invalidate := func(db *gorm.DB) {
+		if db.Error == nil && db.Statement.Table != "" {
+			notFound.clear(notFoundTable{db.Callback(), db.Statement.Table})
+		}
+	}
+	_ = db.Callback().Create().After("gorm:create").Register(name, invalidate)
+	_ = db.Callback().Update().After("gorm:update").Register(name, invalidate)
+}
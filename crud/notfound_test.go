@@ -0,0 +1,41 @@
+package crud
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestNotFoundCache(t *testing.T) {
+	db := benchDB(t, "not_found")
+	var queries int32
+	require.NoError(t, db.Callback().Query().Before("gorm:query").Register("test:count", func(*gorm.DB) {
+		atomic.AddInt32(&queries, 1)
+	}))
+	start := time.Now()
+	now = func() time.Time { return start }
+	t.Cleanup(func() { now = time.Now })
+	g := New[User](db).WithNotFoundCache(time.Minute)
+	missing := User{Model: gorm.Model{ID: 1000}}
+
+	for i := 0; i < 3; i++ {
+		_, err := g.GetByID(context.TODO(), missing)
+		require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	}
+	require.EqualValues(t, 1, queries)
+
+	now = func() time.Time { return start.Add(time.Minute) }
+	_, err := g.GetByID(context.TODO(), missing)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.EqualValues(t, 2, queries)
+
+	_, err = g.Create(context.TODO(), missing)
+	require.NoError(t, err)
+	res, err := g.GetByID(context.TODO(), missing)
+	require.NoError(t, err)
+	require.EqualValues(t, 1000, res.ID)
+}
@@ -0,0 +1,79 @@
+package crud
+
+import (
+	"errors"
+	"log"
+	"reflect"
+	"runtime/debug"
+
+	"gorm.io/gorm"
+)
+
+// NPlusOne describes single-row query repeated within context of ContextWithStats, likely a missing Preload
+type NPlusOne struct {
+	// SQL of query with placeholders; vars differ between repetitions
+	SQL   string
+	Model string
+	Count int
+	// Stack of goroutine running query which reached threshold
+	Stack string
+}
+
+const nPlusOneKey = "crud:n_plus_one"
+
+// WithNPlusOneDetection returns copy of g reporting single-row queries repeated threshold times within context of
+// ContextWithStats, once per query and context; nil fn logs warning with stack trace to Config.Logger. Meant for
+// development: tracking repeated queries holds their SQL for lifetime of context
+func (g GenericCRUD[T]) WithNPlusOneDetection(threshold int, fn func(NPlusOne)) GenericCRUD[T] {
+	g.cfg.NPlusOneThreshold, g.cfg.OnNPlusOne = threshold, fn
+	return g
+}
+
+// withNPlusOne marks db with detector counting queries in c for callback running it
+func (g GenericCRUD[T]) withNPlusOne(db *gorm.DB, c *statsCollector) *gorm.DB {
+	threshold, model := g.cfg.NPlusOneThreshold, reflect.TypeOf((*T)(nil)).Elem().Name()
+	report := g.cfg.OnNPlusOne
+	if report == nil {
+		l := g.cfg.Logger
+		if l == nil {
+			l = log.Default()
+		}
+		report = func(n NPlusOne) {
+			l.Printf("crud: possible N+1: %d identical queries of %s: %s\n%s", n.Count, n.Model, n.SQL, n.Stack)
+		}
+	}
+	return db.Set(nPlusOneKey, func(sql string) {
+		if count := c.repeat(sql); count == threshold {
+			report(NPlusOne{SQL: sql, Model: model, Count: count, Stack: string(debug.Stack())})
+		}
+	})
+}
+
+// registerNPlusOne adds callback running detector to db; see Setup
+func registerNPlusOne(db *gorm.DB) {
+	const name = "crud:n_plus_one"
+	if db.Callback().Query().Get(name) != nil {
+		return
+	}
+	_ = db.Callback().Query().After("gorm:query").Register(name, detectNPlusOne)
+}
+
+// detectNPlusOne counts queries returning at most one row
+func detectNPlusOne(db *gorm.DB) {
+	fn, ok := db.Get(nPlusOneKey)
+	if !ok || db.DryRun || db.RowsAffected > 1 || db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		return
+	}
+	fn.(func(string))(db.Statement.SQL.String())
+}
+
+// repeat counts query sql and returns number of its executions
+func (c *statsCollector) repeat(sql string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.queries == nil {
+		c.queries = make(map[string]int)
+	}
+	c.queries[sql]++
+	return c.queries[sql]
+}
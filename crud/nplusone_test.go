@@ -0,0 +1,53 @@
+package crud
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestNPlusOneDetection(t *testing.T) {
+	db := benchDB(t, "nplusone")
+	var reports []NPlusOne
+	g := New[User](db).WithNPlusOneDetection(3, func(n NPlusOne) { reports = append(reports, n) })
+	require.NoError(t, g.CreateMany(context.TODO(), []User{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}, 10))
+	users, err := g.Query(context.TODO(), User{})
+	require.NoError(t, err)
+
+	load := func(ctx context.Context) {
+		for _, u := range users {
+			_, err := g.GetByID(ctx, User{Model: gorm.Model{ID: u.ID}})
+			require.NoError(t, err)
+		}
+	}
+	load(context.TODO())
+	require.Empty(t, reports)
+
+	ctx := ContextWithStats(context.TODO())
+	for i := 0; i < 3; i++ {
+		_, err = g.Query(ctx, User{})
+		require.NoError(t, err)
+	}
+	require.Empty(t, reports)
+	load(ctx)
+	require.Len(t, reports, 1)
+	require.Equal(t, "User", reports[0].Model)
+	require.Equal(t, 3, reports[0].Count)
+	require.Contains(t, reports[0].SQL, "`users`.`id` = ?")
+	require.Contains(t, reports[0].Stack, "TestNPlusOneDetection")
+
+	// not found counts as single row
+	_, err = g.GetByID(ctx, User{Model: gorm.Model{ID: 42}})
+	require.Error(t, err)
+	require.Len(t, reports, 1)
+
+	var buf bytes.Buffer
+	g = NewWithConfig[User](db, Config{Logger: log.New(&buf, "", 0)}).WithNPlusOneDetection(2, nil)
+	load(ContextWithStats(context.TODO()))
+	require.Contains(t, buf.String(), "crud: possible N+1: 2 identical queries of User: SELECT")
+	require.Contains(t, buf.String(), "goroutine")
+}
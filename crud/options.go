@@ -0,0 +1,139 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type (
+	// CallOption overrides limits for operations of GenericCRUD returned by With
+	CallOption func(*callOptions)
+
+	callOptions struct {
+		timeout time.Duration
+		maxRows int
+	}
+)
+
+// WithQueryTimeout cancels every statement running longer than d
+func WithQueryTimeout(d time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = d
+	}
+}
+
+// WithMaxRows limits Query, QueryMap and SmartQuery results to n rows; SmartQuery with lower Limit is not changed
+func WithMaxRows(n int) CallOption {
+	return func(o *callOptions) {
+		o.maxRows = n
+	}
+}
+
+// With returns copy of g with opts applied, e.g. for stricter limits on hot endpoints:
+//
+//	users.With(crud.WithQueryTimeout(2*time.Second), crud.WithMaxRows(1000)).SmartQuery(ctx, q)
+func (g GenericCRUD[T]) With(opts ...CallOption) GenericCRUD[T] {
+	for _, o := range opts {
+		o(&g.call)
+	}
+	return g
+}
+
+const timeoutKey = "crud:timeout"
+
+// statementTimeout is deadline of running statement: its context before deadline and cancel releasing it
+type statementTimeout struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// withTimeout marks db with call timeout for callbacks setting deadline of every statement
+func (g GenericCRUD[T]) withTimeout(db *gorm.DB) *gorm.DB {
+	if g.call.timeout <= 0 {
+		return db
+	}
+	return db.Set(timeoutKey, g.call.timeout)
+}
+
+// registerTimeout adds callbacks setting deadline of statement and releasing it once statement is done to db;
+// rows of Rows are read after callbacks, so their deadline is released when it passes. See Setup
+func registerTimeout(db *gorm.DB) {
+	const start, stop = "crud:timeout_start", "crud:timeout_stop"
+	if db.Callback().Query().Get(start) != nil {
+		return
+	}
+	cb := db.Callback()
+	_ = cb.Query().Before("gorm:query").Register(start, startTimeout)
+	_ = cb.Query().After("gorm:after_query").Register(stop, stopTimeout)
+	_ = cb.Create().Before(firstCallback(db, "create")).Register(start, startTimeout)
+	_ = cb.Create().After("gorm:after_create").Register(stop, stopTimeout)
+	_ = cb.Update().Before(firstCallback(db, "update")).Register(start, startTimeout)
+	_ = cb.Update().After("gorm:after_update").Register(stop, stopTimeout)
+	_ = cb.Delete().Before(firstCallback(db, "delete")).Register(start, startTimeout)
+	_ = cb.Delete().After("gorm:after_delete").Register(stop, stopTimeout)
+	_ = cb.Row().Before("gorm:row").Register(start, startTimeout)
+	_ = cb.Raw().Before("gorm:raw").Register(start, startTimeout)
+	_ = cb.Raw().After("gorm:raw").Register(stop, stopTimeout)
+}
+
+// startTimeout sets deadline of statement of session marked with timeout
+func startTimeout(db *gorm.DB) {
+	d, ok := db.Get(timeoutKey)
+	if !ok {
+		return
+	}
+	t := statementTimeout{ctx: db.Statement.Context}
+	db.Statement.Context, t.cancel = context.WithTimeout(t.ctx, d.(time.Duration))
+	db.InstanceSet(timeoutKey, t)
+}
+
+// stopTimeout releases deadline of statement, restoring its context for next operation on the same db
+func stopTimeout(db *gorm.DB) {
+	v, ok := db.InstanceGet(timeoutKey)
+	if !ok {
+		return
+	}
+	t := v.(statementTimeout)
+	t.cancel()
+	db.Statement.Context = t.ctx
+}
+
+// limit applies row cap to list query
+func (g GenericCRUD[T]) limit(stmt *gorm.DB) *gorm.DB {
+	if limit, _ := g.rowCap(0); limit > 0 {
+		return stmt.Limit(limit)
+	}
+	return stmt
+}
+
+// rowCap returns LIMIT for list query requesting limit rows (0 for all) and whether reaching it is an error;
+// with Config.MaxRowsError one extra row is fetched to detect overflow
+func (g GenericCRUD[T]) rowCap(limit int) (int, bool) {
+	if m := g.call.maxRows; m > 0 && (limit <= 0 || limit > m) {
+		limit = m
+	}
+	if m := g.cfg.MaxRows; m > 0 && (limit <= 0 || limit > m) {
+		if g.cfg.MaxRowsError {
+			return m + 1, true
+		}
+		limit = m
+	}
+	return limit, false
+}
+
+// checkRows returns TooManyRowsError if n rows fetched for limit exceed Config.MaxRows
+func (g GenericCRUD[T]) checkRows(n, limit int) error {
+	if capped, strict := g.rowCap(limit); strict && n >= capped {
+		return fmt.Errorf("%w: more than %d", TooManyRowsError, g.cfg.MaxRows)
+	}
+	return nil
+}
+
+// uncapped returns copy of g without row caps, for counts, exports and subqueries
+func (g GenericCRUD[T]) uncapped() GenericCRUD[T] {
+	g.call.maxRows, g.cfg.MaxRows = 0, 0
+	return g
+}
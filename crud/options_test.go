@@ -0,0 +1,73 @@
+package crud
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCallOptions(t *testing.T) {
+	db := dryRunDB(t)
+	sql := captureSQL(t, db)
+	g := New[User](db).With(WithMaxRows(100))
+
+	_, err := g.Query(context.TODO(), User{Name: "test"})
+	require.NoError(t, err)
+	require.Contains(t, (*sql)[0], `LIMIT 100`)
+
+	stmt, _ := smartSQL(t, g, Query{})
+	require.Contains(t, stmt, `LIMIT 100`)
+	stmt, _ = smartSQL(t, g, Query{Limit: 10})
+	require.Contains(t, stmt, `LIMIT 10`)
+	require.NotContains(t, stmt, `LIMIT 100`)
+
+	_, err = g.Count(context.TODO(), Query{})
+	require.NoError(t, err)
+	require.NotContains(t, (*sql)[len(*sql)-1], `LIMIT`)
+
+	var contexts []context.Context
+	require.NoError(t, db.Callback().Query().Before("gorm:after_query").Register("test:context", func(tx *gorm.DB) {
+		contexts = append(contexts, tx.Statement.Context)
+	}))
+	g = g.With(WithQueryTimeout(time.Second))
+	session := g.session(context.TODO())
+	_, ok := session.Statement.Context.Deadline()
+	require.False(t, ok)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, session.Find(&[]User{}).Error)
+	}
+	require.Len(t, contexts, 2)
+	for _, ctx := range contexts {
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		require.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+		require.ErrorIs(t, ctx.Err(), context.Canceled)
+	}
+	_, ok = session.Statement.Context.Deadline()
+	require.False(t, ok)
+}
+
+func TestMaxRows(t *testing.T) {
+	db := dryRunDB(t)
+	sql := captureSQL(t, db)
+	g := NewWithConfig[User](db, Config{MaxRows: 50})
+
+	stmt, _ := smartSQL(t, g, Query{})
+	require.Contains(t, stmt, `LIMIT 50`)
+	stmt, _ = smartSQL(t, g.With(WithMaxRows(10)), Query{})
+	require.Contains(t, stmt, `LIMIT 10`)
+
+	g = NewWithConfig[User](db, Config{MaxRows: 50, MaxRowsError: true})
+	_, err := g.QueryMap(context.TODO(), map[string]any{})
+	require.NoError(t, err)
+	require.Contains(t, (*sql)[len(*sql)-1], `LIMIT 51`)
+	require.ErrorIs(t, g.checkRows(51, 0), TooManyRowsError)
+	require.NoError(t, g.checkRows(50, 0))
+	require.NoError(t, g.checkRows(20, 20))
+
+	stmt, _ = smartSQL(t, g.uncapped(), Query{})
+	require.NotContains(t, stmt, `LIMIT`)
+}
@@ -0,0 +1,143 @@
+package crud
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// WithDefaultOrder returns copy of g ordering Query, QueryMap and SmartQuery results by order,
+// e.g. "created_at DESC", unless OrderBy, rank or distance ordering is requested; raw SQL, not validated
+func (g GenericCRUD[T]) WithDefaultOrder(order string) GenericCRUD[T] {
+	g.cfg.DefaultOrder = order
+	return g
+}
+
+// ordered applies default order to list query
+func (g GenericCRUD[T]) ordered(stmt *gorm.DB) *gorm.DB {
+	if g.cfg.DefaultOrder != "" {
+		return stmt.Order(g.cfg.DefaultOrder)
+	}
+	return stmt
+}
+
+// WithTieBreaker returns copy of g with primary key tie-breaker enabled (default) or disabled; see Config.DisableTieBreaker
+func (g GenericCRUD[T]) WithTieBreaker(enabled bool) GenericCRUD[T] {
+	g.cfg.DisableTieBreaker = !enabled
+	return g
+}
+
+// tieBreaker returns order with primary key appended if query is paginated and order does not include it.
+// Primary key is DESC if all of q.OrderBy is DESC, so reversed order (Last) is reversed completely
+func (g GenericCRUD[T]) tieBreaker(q Query, order []clause.Expr) []clause.Expr {
+	if g.cfg.DisableTieBreaker || len(order) == 0 || len(q.GroupBy) > 0 {
+		return order
+	}
+	if limit, _ := g.rowCap(q.Limit); limit <= 0 && q.Offset <= 0 {
+		return order
+	}
+	s, err := g.schema()
+	if err != nil || s.PrioritizedPrimaryField == nil {
+		return order
+	}
+	pk := s.PrioritizedPrimaryField.DBName
+	for _, o := range q.OrderBy {
+		if o.Column == pk && o.Func == "" {
+			return order
+		}
+	}
+	// raw orders: default order, rank and distance
+	for _, o := range order {
+		for _, item := range strings.Split(o.SQL, ",") {
+			if c := strings.Fields(item); len(c) > 0 && strings.Trim(c[0], `"`+"`") == pk {
+				return order
+			}
+		}
+	}
+	dir := ASC
+	for _, o := range q.OrderBy {
+		if o.direction() != DESC {
+			dir = ASC
+			break
+		}
+		dir = DESC
+	}
+	return append(order, g.Dialect().order(OrderClause{Column: pk, Direction: dir}, ""))
+}
+
+// WithCollation returns copy of g sorting text columns of Query.OrderBy by collation unless Query.Collation is set,
+// e.g. "uk-UA-x-icu" for Ukrainian ICU collation on Postgres
+func (g GenericCRUD[T]) WithCollation(collation string) GenericCRUD[T] {
+	g.cfg.Collation = collation
+	return g
+}
+
+// collationName matches collation names safe to quote as identifiers
+var collationName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// collation returns collation of text ordering of q
+func (g GenericCRUD[T]) collation(q Query) (string, error) {
+	c := q.Collation
+	if c == "" {
+		c = g.cfg.Collation
+	}
+	if c != "" && !collationName.MatchString(c) {
+		return "", fmt.Errorf("%w: invalid collation %q", InvalidFilterError, c)
+	}
+	return c, nil
+}
+
+// orderFuncs are functions OrderClause.Func may apply to column
+var orderFuncs = map[string]bool{"LOWER": true, "UPPER": true, "LENGTH": true, "ABS": true}
+
+// validate checks function and null placement of o
+func (o OrderClause) validate() error {
+	if o.NullsFirst && o.NullsLast {
+		return fmt.Errorf("%w: %s: both nulls first and last", InvalidFilterError, o.Column)
+	}
+	if o.Func != "" && !orderFuncs[strings.ToUpper(o.Func)] {
+		return fmt.Errorf("%w: unknown order function %q", InvalidFilterError, o.Func)
+	}
+	return nil
+}
+
+// reverse returns o in opposite direction, including placement of NULLs
+func (o OrderClause) reverse() OrderClause {
+	if o.direction() == DESC {
+		o.Direction = ASC
+	} else {
+		o.Direction = DESC
+	}
+	o.NullsFirst, o.NullsLast = o.NullsLast, o.NullsFirst
+	return o
+}
+
+// orderExpr returns ordering by o; column must be a column of T or rank column of q.Window
+func (g GenericCRUD[T]) orderExpr(q Query, o OrderClause) (clause.Expr, error) {
+	if err := o.validate(); err != nil {
+		return clause.Expr{}, err
+	}
+	if q.Window != nil && o.Column == q.Window.alias() {
+		return g.Dialect().order(o, ""), nil
+	}
+	s, err := g.schema()
+	if err != nil {
+		return clause.Expr{}, err
+	}
+	f := s.LookUpField(o.Column)
+	if f == nil || f.DBName == "" {
+		return clause.Expr{}, fmt.Errorf("%w: unknown sort column %q", InvalidFilterError, o.Column)
+	}
+	o.Column = f.DBName
+	var collation string
+	if f.DataType == schema.String && (o.Func == "" || strings.EqualFold(o.Func, "lower") || strings.EqualFold(o.Func, "upper")) {
+		if collation, err = g.collation(q); err != nil {
+			return clause.Expr{}, err
+		}
+	}
+	return g.Dialect().order(o, collation), nil
+}
@@ -0,0 +1,148 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// OrphanReport lists primary keys of child rows of Table whose parent is Missing or SoftDeleted
+type OrphanReport struct {
+	Association string
+	Table       string
+	Missing     []any
+	SoftDeleted []any
+	// Deleted is number of orphans deleted by DeleteOrphans
+	Deleted int64
+}
+
+// Len returns number of orphans
+func (r OrphanReport) Len() int {
+	return len(r.Missing) + len(r.SoftDeleted)
+}
+
+// CheckOrphans reports child rows of association, has-one or has-many of T or belongs-to of child T, whose parent
+// row is missing or soft-deleted, e.g. after partial failures without foreign keys. Soft-deleted children and
+// children with NULL foreign key are ignored
+func (g GenericCRUD[T]) CheckOrphans(ctx context.Context, association string) (OrphanReport, error) {
+	return g.orphans(ctx, association, false)
+}
+
+// DeleteOrphans deletes rows reported by CheckOrphans within transaction; children with gorm.DeletedAt are soft-deleted
+func (g GenericCRUD[T]) DeleteOrphans(ctx context.Context, association string) (OrphanReport, error) {
+	return g.orphans(ctx, association, true)
+}
+
+// orphanSide is table of association side with its schema
+type orphanSide struct {
+	schema *schema.Schema
+	table  string
+}
+
+func (g GenericCRUD[T]) orphans(ctx context.Context, association string, cleanup bool) (OrphanReport, error) {
+	report := OrphanReport{Association: association}
+	s, err := g.schema()
+	if err != nil {
+		return report, err
+	}
+	rel, ok := s.Relationships.Relations[association]
+	if !ok {
+		return report, fmt.Errorf("%w: unknown association %q", InvalidFilterError, association)
+	}
+	var v T
+	table, err := g.tableName(ctx, v)
+	if err != nil {
+		return report, err
+	}
+	self, other := orphanSide{s, table}, orphanSide{rel.FieldSchema, rel.FieldSchema.Table}
+	var parent, child orphanSide
+	switch rel.Type {
+	case schema.HasOne, schema.HasMany:
+		parent, child = self, other
+	case schema.BelongsTo:
+		parent, child = other, self
+	default:
+		return report, fmt.Errorf("%w: %q is not has-one, has-many or belongs-to association", InvalidFilterError, association)
+	}
+	if child.schema.PrioritizedPrimaryField == nil {
+		return report, fmt.Errorf("%s has no primary key", child.schema.Name)
+	}
+	report.Table = child.table
+	err = g.RunInTransaction(ctx, func(ctx context.Context, tx GenericCRUD[T]) error {
+		db := tx.conn(ctx).WithContext(ctx)
+		if report.Missing, err = orphanKeys(db, rel, parent, child, false); err != nil {
+			return err
+		}
+		if deletedColumn(parent.schema) != "" {
+			if report.SoftDeleted, err = orphanKeys(db, rel, parent, child, true); err != nil {
+				return err
+			}
+		}
+		if !cleanup || report.Len() == 0 {
+			return nil
+		}
+		ids := append(append([]any(nil), report.Missing...), report.SoftDeleted...)
+		res := db.Table(child.table).Where(clause.IN{
+			Column: clause.Column{Name: child.schema.PrioritizedPrimaryField.DBName},
+			Values: ids,
+		}).Delete(reflect.New(child.schema.ModelType).Interface())
+		report.Deleted = res.RowsAffected
+		return res.Error
+	})
+	return report, err
+}
+
+// orphanKeys returns primary keys of children without parent, or with soft-deleted parent if softDeleted
+func orphanKeys(db *gorm.DB, rel *schema.Relationship, parent, child orphanSide, softDeleted bool) ([]any, error) {
+	const c, p = "c", "p"
+	var where, join []string
+	var vars []any
+	for _, ref := range rel.References {
+		fk := clause.Column{Table: c, Name: ref.ForeignKey.DBName}
+		if ref.PrimaryKey == nil {
+			// polymorphic type column
+			where, vars = append(where, "? = ?"), append(vars, fk, ref.PrimaryValue)
+			continue
+		}
+		where, vars = append(where, "? IS NOT NULL"), append(vars, fk)
+		join = append(join, "? = ?")
+	}
+	if deleted := deletedColumn(child.schema); deleted != "" {
+		where, vars = append(where, "? IS NULL"), append(vars, clause.Column{Table: c, Name: deleted})
+	}
+	sub := "SELECT 1 FROM ? WHERE " + strings.Join(join, " AND ")
+	vars = append(vars, clause.Table{Name: parent.table, Alias: p})
+	for _, ref := range rel.References {
+		if ref.PrimaryKey != nil {
+			vars = append(vars, clause.Column{Table: p, Name: ref.PrimaryKey.DBName}, clause.Column{Table: c, Name: ref.ForeignKey.DBName})
+		}
+	}
+	exists := "NOT EXISTS"
+	if softDeleted {
+		exists = "EXISTS"
+		sub += " AND ? IS NOT NULL"
+		vars = append(vars, clause.Column{Table: p, Name: deletedColumn(parent.schema)})
+	}
+	pk := child.schema.PrioritizedPrimaryField
+	sql := "SELECT ? FROM ? WHERE " + strings.Join(where, " AND ") + " AND " + exists + " (" + sub + ") ORDER BY ?"
+	vars = append([]any{clause.Column{Table: c, Name: pk.DBName}, clause.Table{Name: child.table, Alias: c}}, vars...)
+	vars = append(vars, clause.Column{Table: c, Name: pk.DBName})
+	keys := reflect.New(reflect.SliceOf(pk.FieldType))
+	if err := db.Raw(sql, vars...).Scan(keys.Interface()).Error; err != nil {
+		return nil, err
+	}
+	keys = keys.Elem()
+	if keys.Len() == 0 {
+		return nil, nil
+	}
+	res := make([]any, keys.Len())
+	for i := range res {
+		res[i] = keys.Index(i).Interface()
+	}
+	return res, nil
+}
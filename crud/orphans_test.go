@@ -0,0 +1,84 @@
+package crud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestOrphans(t *testing.T) {
+	db := benchDB(t, "orphans")
+	require.NoError(t, db.AutoMigrate(&Owner{}, &Pet{}))
+	owners, pets := New[Owner](db), New[Pet](db)
+	ctx := context.TODO()
+	alice, err := owners.Create(ctx, Owner{Name: "alice", Pets: []Pet{{Name: "rex"}}})
+	require.NoError(t, err)
+	bob, err := owners.Create(ctx, Owner{Name: "bob", Pets: []Pet{{Name: "tom"}}})
+	require.NoError(t, err)
+	stray, err := pets.Create(ctx, Pet{Name: "stray", OwnerID: 999})
+	require.NoError(t, err)
+	gone, err := pets.Create(ctx, Pet{Name: "gone", OwnerID: 999})
+	require.NoError(t, err)
+	require.NoError(t, pets.Delete(ctx, *gone))
+	require.NoError(t, owners.Delete(ctx, *bob))
+
+	report, err := owners.CheckOrphans(ctx, "Pets")
+	require.NoError(t, err)
+	require.Equal(t, OrphanReport{
+		Association: "Pets",
+		Table:       "pets",
+		Missing:     []any{stray.ID},
+		SoftDeleted: []any{bob.Pets[0].ID},
+	}, report)
+	_, err = pets.CheckOrphans(ctx, "Owner")
+	require.ErrorIs(t, err, InvalidFilterError)
+
+	report, err = owners.DeleteOrphans(ctx, "Pets")
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Len())
+	require.EqualValues(t, 2, report.Deleted)
+	report, err = owners.CheckOrphans(ctx, "Pets")
+	require.NoError(t, err)
+	require.Zero(t, report.Len())
+	left, err := pets.Query(ctx, Pet{})
+	require.NoError(t, err)
+	require.Len(t, left, 1)
+	require.Equal(t, alice.Pets[0].ID, left[0].ID)
+
+	_, err = owners.CheckOrphans(ctx, "Tags")
+	require.ErrorIs(t, err, InvalidFilterError)
+}
+
+type collar struct {
+	gorm.Model
+	PetID *uint
+	Pet   *Pet
+}
+
+func (c collar) PrimaryKey() any {
+	return c.ID
+}
+
+func TestOrphansBelongsTo(t *testing.T) {
+	db := benchDB(t, "orphans_belongs_to")
+	require.NoError(t, db.AutoMigrate(&Pet{}, &collar{}))
+	collars := New[collar](db)
+	ctx := context.TODO()
+	rex, err := New[Pet](db).Create(ctx, Pet{Name: "rex"})
+	require.NoError(t, err)
+	missing := uint(999)
+	_, err = collars.Create(ctx, collar{PetID: &rex.ID})
+	require.NoError(t, err)
+	lost, err := collars.Create(ctx, collar{PetID: &missing})
+	require.NoError(t, err)
+	_, err = collars.Create(ctx, collar{})
+	require.NoError(t, err)
+
+	report, err := collars.CheckOrphans(ctx, "Pet")
+	require.NoError(t, err)
+	require.Equal(t, []any{lost.ID}, report.Missing)
+	require.Empty(t, report.SoftDeleted)
+	require.Equal(t, "collars", report.Table)
+}
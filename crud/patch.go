@@ -0,0 +1,111 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// Diff returns columns of new which differ from old, with new values; primary keys are not compared
+func (g GenericCRUD[T]) Diff(old, new T) map[string]any {
+	s, err := g.schema()
+	if err != nil {
+		return nil
+	}
+	res := make(map[string]any)
+	ov, nv := reflect.ValueOf(&old).Elem(), reflect.ValueOf(&new).Elem()
+	for _, f := range s.Fields {
+		if f.DBName == "" || f.PrimaryKey {
+			continue
+		}
+		a, _ := f.ValueOf(context.Background(), ov)
+		b, _ := f.ValueOf(context.Background(), nv)
+		if !reflect.DeepEqual(a, b) {
+			res[f.DBName] = b
+		}
+	}
+	return res
+}
+
+// Patch updates columns of row with primary key id from patch keyed by column or field name;
+// unknown, primary key and hidden columns are rejected before update
+func (g GenericCRUD[T]) Patch(ctx context.Context, id any, patch map[string]any) error {
+	v, err := g.FromID(id)
+	if err != nil {
+		return err
+	}
+	columns, err := g.patchColumns(ctx, patch)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+	return g.versioned(ctx, v, HistoryUpdate, func(ctx context.Context, g GenericCRUD[T]) error {
+		res := g.sessionOf(ctx, v).Omit(g.omits(ctx, g.cfg.Omit)...).Model(&v).Updates(columns)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// errNotApplied rolls back history snapshot of UpdateIf not matching any row
+var errNotApplied = errors.New("update not applied")
+
+// UpdateIf updates columns of v (by primary key if non-zero) only while rows match condition, e.g. a status;
+// returns 0 rows affected when condition no longer holds. Only filter fields of condition are used
+func (g GenericCRUD[T]) UpdateIf(ctx context.Context, v T, updates map[string]any, condition Query) (int64, error) {
+	columns, err := g.patchColumns(ctx, updates)
+	if err != nil {
+		return 0, err
+	}
+	if len(columns) == 0 {
+		return 0, nil
+	}
+	var affected int64
+	err = g.versioned(ctx, v, HistoryUpdate, func(ctx context.Context, g GenericCRUD[T]) error {
+		stmt, _, err := g.where(g.sessionOf(ctx, v), condition)
+		if err != nil {
+			return err
+		}
+		res := stmt.Omit(g.omits(ctx, g.cfg.Omit)...).Model(&v).Updates(columns)
+		if res.Error != nil {
+			return res.Error
+		}
+		if affected = res.RowsAffected; affected == 0 {
+			return errNotApplied
+		}
+		return nil
+	})
+	if errors.Is(err, errNotApplied) {
+		err = nil
+	}
+	return affected, err
+}
+
+// patchColumns validates patch keys and returns patch keyed by column name
+func (g GenericCRUD[T]) patchColumns(ctx context.Context, patch map[string]any) (map[string]any, error) {
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]any, len(patch))
+	for k, value := range patch {
+		field := s.LookUpField(k)
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("%w: unknown column %q", InvalidFilterError, k)
+		}
+		if field.PrimaryKey || !field.Updatable || g.hidden(ctx, field.DBName) {
+			return nil, fmt.Errorf("%w: %s", ForbiddenFieldError, field.DBName)
+		}
+		columns[field.DBName] = value
+	}
+	return columns, g.checkEnumMap(columns)
+}
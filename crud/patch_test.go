@@ -0,0 +1,47 @@
+package crud
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestDiff(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	old := User{Model: gorm.Model{ID: 1}, Name: "a"}
+	require.Empty(t, g.Diff(old, old))
+	require.Equal(t, map[string]any{"name": "b", "age": sql.NullInt16{Int16: 3, Valid: true}},
+		g.Diff(old, User{Model: gorm.Model{ID: 2}, Name: "b", Age: sql.NullInt16{Int16: 3, Valid: true}}))
+}
+
+func TestPatch(t *testing.T) {
+	db := dryRunDB(t)
+	stmts := captureSQL(t, db)
+	g := New[User](db).WithFieldPolicy(func(ctx context.Context) []string { return []string{"age"} })
+
+	require.ErrorIs(t, g.Patch(context.TODO(), 1, map[string]any{"unknown": 1}), InvalidFilterError)
+	require.ErrorIs(t, g.Patch(context.TODO(), 1, map[string]any{"id": 2}), ForbiddenFieldError)
+	require.ErrorIs(t, g.Patch(context.TODO(), 1, map[string]any{"Age": 2}), ForbiddenFieldError)
+	require.Empty(t, *stmts)
+
+	// dry run affects no rows
+	require.ErrorIs(t, g.Patch(context.TODO(), "1", map[string]any{"Name": "b"}), gorm.ErrRecordNotFound)
+	require.Contains(t, (*stmts)[0], `UPDATE "users" SET "name"=$1,"updated_at"=$2 WHERE "users"."deleted_at" IS NULL AND "id" = $3`)
+}
+
+func TestUpdateIf(t *testing.T) {
+	db := dryRunDB(t)
+	stmts := captureSQL(t, db)
+	g := New[User](db)
+
+	n, err := g.UpdateIf(context.TODO(), User{Model: gorm.Model{ID: 1}}, map[string]any{"name": "b"}, Query{Equal: map[string]any{"name": "a"}})
+	require.NoError(t, err)
+	require.Zero(t, n)
+	require.Contains(t, (*stmts)[0], `UPDATE "users" SET "name"=$1,"updated_at"=$2 WHERE name = $3 AND "users"."deleted_at" IS NULL AND "id" = $4`)
+
+	_, err = g.UpdateIf(context.TODO(), User{}, map[string]any{"id": 2}, Query{})
+	require.ErrorIs(t, err, ForbiddenFieldError)
+}
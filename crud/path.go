@@ -0,0 +1,168 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PathTree maintains materialized path column of T, e.g. "/1/4/9/" for node 9 under 4 under root 1,
+// so subtrees are read with one prefix query of the standard Query DSL (Within)
+type PathTree[T GORMModel] struct {
+	crud GenericCRUD[T]
+	path string
+}
+
+// NewPathTree is a constructor; pathColumn should be indexed
+func NewPathTree[T GORMModel](crud GenericCRUD[T], pathColumn string) *PathTree[T] {
+	return &PathTree[T]{crud: crud, path: pathColumn}
+}
+
+// Create v under node parent (root if nil) and set its path
+func (t *PathTree[T]) Create(ctx context.Context, v T, parent any, omit ...string) (*T, error) {
+	var res *T
+	err := t.crud.RunInTransaction(ctx, func(ctx context.Context, tx GenericCRUD[T]) error {
+		prefix := "/"
+		if parent != nil {
+			p, err := tx.FromID(parent)
+			if err != nil {
+				return err
+			}
+			if prefix, err = t.pathOf(ctx, tx, p); err != nil {
+				return err
+			}
+		}
+		created, err := tx.Create(ctx, v, omit...)
+		if err != nil {
+			return err
+		}
+		path := fmt.Sprintf("%s%v/", prefix, (*created).PrimaryKey())
+		if err = t.setPath(created, path); err != nil {
+			return err
+		}
+		res = created
+		return tx.UpdateField(ctx, *created, t.path, path)
+	})
+	return res, err
+}
+
+// Path returns path of node id
+func (t *PathTree[T]) Path(ctx context.Context, id any) (string, error) {
+	v, err := t.crud.FromID(id)
+	if err != nil {
+		return "", err
+	}
+	return t.pathOf(ctx, t.crud, v)
+}
+
+// Within returns copy of q restricted to subtree of node with path; the node itself is included
+func (t *PathTree[T]) Within(q Query, path string) Query {
+	pattern := make(map[string]Pattern, len(q.Pattern)+1)
+	for k, v := range q.Pattern {
+		pattern[k] = v
+	}
+	pattern[t.path] = Pattern{Value: path, Mode: Prefix}
+	q.Pattern = pattern
+	return q
+}
+
+// Descendants returns nodes below id ordered by path
+func (t *PathTree[T]) Descendants(ctx context.Context, id any) ([]*T, error) {
+	path, err := t.Path(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return t.crud.SmartQuery(ctx, t.Within(Query{
+		NotEqual: map[string]any{t.path: path},
+		OrderBy:  []OrderClause{{Column: t.path, Direction: ASC}},
+	}, path))
+}
+
+// Ancestors returns nodes above id, root first
+func (t *PathTree[T]) Ancestors(ctx context.Context, id any) ([]*T, error) {
+	path, err := t.Path(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ids := strings.Split(strings.Trim(path, "/"), "/")
+	var paths []any
+	for i := 1; i < len(ids); i++ {
+		paths = append(paths, "/"+strings.Join(ids[:i], "/")+"/")
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	return t.crud.SmartQuery(ctx, Query{
+		In:      map[string][]any{t.path: paths},
+		OrderBy: []OrderClause{{Column: t.path, Direction: ASC}},
+	})
+}
+
+// Move node id with its subtree under newParent (root if nil), rewriting paths of the subtree
+func (t *PathTree[T]) Move(ctx context.Context, id, newParent any) error {
+	v, err := t.crud.FromID(id)
+	if err != nil {
+		return err
+	}
+	return t.crud.RunInTransaction(ctx, func(ctx context.Context, tx GenericCRUD[T]) error {
+		old, err := t.pathOf(ctx, tx, v)
+		if err != nil {
+			return err
+		}
+		prefix := "/"
+		if newParent != nil {
+			p, err := tx.FromID(newParent)
+			if err != nil {
+				return err
+			}
+			if prefix, err = t.pathOf(ctx, tx, p); err != nil {
+				return err
+			}
+			if strings.HasPrefix(prefix, old) {
+				return TreeCycleError
+			}
+		}
+		path := fmt.Sprintf("%s%v/", prefix, v.PrimaryKey())
+		rest := clause.Expr{SQL: "SUBSTR(?, ?)", Vars: []any{clause.Column{Name: t.path}, len(old) + 1}}
+		expr := gorm.Expr("? || ?", path, rest)
+		if tx.Dialect() == MySQL {
+			expr = gorm.Expr("CONCAT(?, ?)", path, rest)
+		}
+		return tx.session(ctx).Model(new(T)).Where(tx.Dialect().Like(t.path, likeEscaper.Replace(old)+"%", false, true)).
+			UpdateColumn(t.path, expr).Error
+	})
+}
+
+// pathOf loads path of v
+func (t *PathTree[T]) pathOf(ctx context.Context, g GenericCRUD[T], v T) (string, error) {
+	node, err := g.GetByID(ctx, v)
+	if err != nil {
+		return "", err
+	}
+	s, err := g.schema()
+	if err != nil {
+		return "", err
+	}
+	f := s.LookUpField(t.path)
+	if f == nil {
+		return "", fmt.Errorf("%w: unknown column %q", InvalidFilterError, t.path)
+	}
+	path, _ := f.ValueOf(ctx, reflect.ValueOf(node).Elem())
+	return fmt.Sprint(path), nil
+}
+
+func (t *PathTree[T]) setPath(v *T, path string) error {
+	s, err := t.crud.schema()
+	if err != nil {
+		return err
+	}
+	f := s.LookUpField(t.path)
+	if f == nil {
+		return fmt.Errorf("%w: unknown column %q", InvalidFilterError, t.path)
+	}
+	return f.Set(context.Background(), reflect.ValueOf(v).Elem(), path)
+}
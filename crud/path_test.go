@@ -0,0 +1,29 @@
+package crud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathTreeMoveEscapes(t *testing.T) {
+	ctx := context.TODO()
+	db := benchDB(t, "path_escapes")
+	require.NoError(t, db.AutoMigrate(&Category{}))
+	// legacy paths of slugs rather than primary keys
+	for _, c := range []Category{{Name: "a", Path: "/a_b/"}, {Name: "c", Path: "/a_b/c%d/"}, {Name: "root", Path: "/root/"}} {
+		require.NoError(t, db.Create(&c).Error)
+	}
+	categories := New[Category](db)
+	tree := NewPathTree(categories, "path")
+	a, err := categories.QueryOne(ctx, Category{Name: "a"})
+	require.NoError(t, err)
+	root, err := categories.QueryOne(ctx, Category{Name: "root"})
+	require.NoError(t, err)
+
+	require.NoError(t, tree.Move(ctx, a.ID, root.ID))
+	c, err := categories.QueryOne(ctx, Category{Name: "c"})
+	require.NoError(t, err)
+	require.Equal(t, "/root/1/c%d/", c.Path)
+}
@@ -0,0 +1,52 @@
+package crud
+
+import "context"
+
+// FieldPolicy returns columns hidden from the caller in ctx, e.g. based on role;
+// hidden columns are omitted on reads and never written
+type FieldPolicy func(ctx context.Context) []string
+
+// WithFieldPolicy returns copy of g applying policy to every operation
+func (g GenericCRUD[T]) WithFieldPolicy(policy FieldPolicy) GenericCRUD[T] {
+	g.cfg.FieldPolicy = policy
+	return g
+}
+
+// omits returns hidden columns followed by lists
+func (g GenericCRUD[T]) omits(ctx context.Context, lists ...[]string) []string {
+	if g.cfg.FieldPolicy == nil {
+		// avoid copying single list on hot paths; result MUST NOT be appended to
+		var single []string
+		n := 0
+		for _, l := range lists {
+			if len(l) > 0 {
+				single = l
+				n++
+			}
+		}
+		if n <= 1 {
+			return single
+		}
+	}
+	var res []string
+	if g.cfg.FieldPolicy != nil {
+		res = append(res, g.cfg.FieldPolicy(ctx)...)
+	}
+	for _, l := range lists {
+		res = append(res, l...)
+	}
+	return res
+}
+
+// hidden reports whether column is hidden by policy in ctx
+func (g GenericCRUD[T]) hidden(ctx context.Context, column string) bool {
+	if g.cfg.FieldPolicy == nil {
+		return false
+	}
+	for _, c := range g.cfg.FieldPolicy(ctx) {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package crud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type roleKey struct{}
+
+func TestFieldPolicy(t *testing.T) {
+	db := dryRunDB(t)
+	sql := captureSQL(t, db)
+	g := New[User](db).WithFieldPolicy(func(ctx context.Context) []string {
+		if ctx.Value(roleKey{}) == "admin" {
+			return nil
+		}
+		return []string{"age"}
+	})
+	user := User{Name: "test", Age: a1}
+	user.ID = 1
+
+	_, err := g.GetByID(context.TODO(), user)
+	require.NoError(t, err)
+	require.NotContains(t, (*sql)[0], "age")
+
+	require.NoError(t, g.Update(context.TODO(), user))
+	require.Contains(t, (*sql)[1], `"name"=`)
+	require.NotContains(t, (*sql)[1], "age")
+
+	require.ErrorIs(t, g.UpdateField(context.TODO(), user, "age", 1), ForbiddenFieldError)
+
+	admin := context.WithValue(context.TODO(), roleKey{}, "admin")
+	_, err = g.GetByID(admin, user)
+	require.NoError(t, err)
+	require.Contains(t, (*sql)[2], "SELECT *")
+	require.NoError(t, g.UpdateField(admin, user, "age", 1))
+}
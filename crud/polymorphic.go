@@ -0,0 +1,83 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm/clause"
+)
+
+// Default columns of polymorphic association, as created by gorm `polymorphic:Owner` tag
+const (
+	OwnerTypeColumn = "owner_type"
+	OwnerIDColumn   = "owner_id"
+)
+
+// OwnerFilter matches polymorphic children of owner with Type and ID, e.g. comments of post 1:
+//
+//	comments.SmartQuery(ctx, crud.Query{Owner: crud.OwnedBy("posts", 1)})
+type OwnerFilter struct {
+	// Type is value of type column, by default table name of owner
+	Type string `json:"type"`
+	ID   any    `json:"id"`
+	// TypeColumn and IDColumn default to owner_type and owner_id
+	TypeColumn string `json:"type_column,omitempty"`
+	IDColumn   string `json:"id_column,omitempty"`
+}
+
+// OwnedBy returns OwnerFilter on default owner_type and owner_id columns
+func OwnedBy(ownerType string, id any) *OwnerFilter {
+	return &OwnerFilter{Type: ownerType, ID: id}
+}
+
+// OwnerOf returns OwnerFilter matching children of v: type is table name of T as stored by gorm, id is primary key of v
+func (g GenericCRUD[T]) OwnerOf(ctx context.Context, v T) (*OwnerFilter, error) {
+	table, err := g.tableName(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	return OwnedBy(table, v.PrimaryKey()), nil
+}
+
+// Columns returns type and id columns, defaults applied
+func (o OwnerFilter) Columns() (typeColumn, idColumn string) {
+	typeColumn, idColumn = o.TypeColumn, o.IDColumn
+	if typeColumn == "" {
+		typeColumn = OwnerTypeColumn
+	}
+	if idColumn == "" {
+		idColumn = OwnerIDColumn
+	}
+	return typeColumn, idColumn
+}
+
+// build returns condition on owner columns
+func (o OwnerFilter) build() (clause.Expression, error) {
+	if o.Type == "" || o.ID == nil {
+		return nil, fmt.Errorf("%w: owner type and id are required", InvalidFilterError)
+	}
+	typeColumn, idColumn := o.Columns()
+	return clause.And(
+		clause.Eq{Column: clause.Column{Name: typeColumn}, Value: o.Type},
+		clause.Eq{Column: clause.Column{Name: idColumn}, Value: o.ID},
+	), nil
+}
+
+// Attach sets owner columns of v (by primary key) to owner
+func (g GenericCRUD[T]) Attach(ctx context.Context, v T, owner OwnerFilter) error {
+	if _, err := owner.build(); err != nil {
+		return err
+	}
+	typeColumn, idColumn := owner.Columns()
+	return g.UpdateMap(ctx, v, map[string]any{typeColumn: owner.Type, idColumn: owner.ID})
+}
+
+// Detach clears owner columns of v (by primary key) if v belongs to owner; reports whether v was detached
+func (g GenericCRUD[T]) Detach(ctx context.Context, v T, owner OwnerFilter) (bool, error) {
+	if _, err := owner.build(); err != nil {
+		return false, err
+	}
+	typeColumn, idColumn := owner.Columns()
+	affected, err := g.UpdateIf(ctx, v, map[string]any{typeColumn: nil, idColumn: nil}, Query{Owner: &owner})
+	return affected > 0, err
+}
@@ -0,0 +1,61 @@
+package crud
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PoolConfig is connection pool settings; zero fields are left unchanged
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// ConfigurePool applies cfg to connection pool of db; pool is shared by all GenericCRUD using db
+func ConfigurePool(db *gorm.DB, cfg PoolConfig) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if cfg.MaxOpenConns != 0 {
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns != 0 {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime != 0 {
+		sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+	if cfg.ConnMaxIdleTime != 0 {
+		sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+	return nil
+}
+
+// NewWithPool is a constructor that also configures connection pool of db
+func NewWithPool[T GORMModel](db *gorm.DB, cfg PoolConfig, omit ...string) (GenericCRUD[T], error) {
+	return New[T](db, omit...), ConfigurePool(db, cfg)
+}
+
+// Health pings database; suitable for readiness probes
+func (g GenericCRUD[T]) Health(ctx context.Context) error {
+	sqlDB, err := g.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Stats returns connection pool statistics; zero if db is not backed by *sql.DB (e.g. inside transaction)
+func (g GenericCRUD[T]) Stats() sql.DBStats {
+	sqlDB, err := g.db.DB()
+	if err != nil {
+		return sql.DBStats{}
+	}
+	return sqlDB.Stats()
+}
@@ -0,0 +1,15 @@
+package crud
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool(t *testing.T) {
+	db := dryRunDB(t)
+	g, err := NewWithPool[User](db, PoolConfig{MaxOpenConns: 7, ConnMaxLifetime: time.Minute})
+	require.NoError(t, err)
+	require.Equal(t, 7, g.Stats().MaxOpenConnections)
+}
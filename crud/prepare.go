@@ -0,0 +1,9 @@
+package crud
+
+// WithPrepareStmt returns copy of g running statements as prepared statements cached per SQL
+// (gorm PrepareStmt session mode), saving parsing on hot paths. Cache is shared by all sessions of db;
+// statements of dynamic queries (varying IN lists, filters) fill it, so enable it for stable query shapes
+func (g GenericCRUD[T]) WithPrepareStmt() GenericCRUD[T] {
+	g.cfg.PrepareStmt = true
+	return g
+}
@@ -0,0 +1,51 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// benchDB returns in-memory SQLite database with users table
+func benchDB(tb testing.TB, name string) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", name)), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	require.NoError(tb, err)
+	require.NoError(tb, db.AutoMigrate(&User{}))
+	return db
+}
+
+func TestPrepareStmt(t *testing.T) {
+	db := benchDB(t, "prepare")
+	g := New[User](db).WithPrepareStmt()
+	for i := 0; i < 3; i++ {
+		_, err := g.SmartQuery(context.TODO(), Query{Equal: map[string]any{"name": fmt.Sprint(i)}})
+		require.NoError(t, err)
+	}
+	prepared := db.Session(&gorm.Session{PrepareStmt: true}).Statement.ConnPool.(*gorm.PreparedStmtDB)
+	require.Len(t, prepared.Stmts, 1)
+}
+
+func BenchmarkPrepareStmt(b *testing.B) {
+	db := benchDB(b, "bench_prepare")
+	for name, g := range map[string]GenericCRUD[User]{
+		"plain":    New[User](db),
+		"prepared": New[User](db).WithPrepareStmt(),
+	} {
+		b.Run(name, func(b *testing.B) {
+			q := Query{Equal: map[string]any{"name": "x"}, Limit: 10}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := g.SmartQuery(context.TODO(), q); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package crud
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type (
+	// FieldMask is implemented by *fieldmaskpb.FieldMask
+	FieldMask interface {
+		GetPaths() []string
+	}
+
+	// ListRequest is implemented by AIP-132 style List request messages
+	ListRequest interface {
+		GetFilter() string
+		GetOrderBy() string
+		GetPageSize() int32
+		GetPageToken() string
+	}
+)
+
+// MaskColumns maps FieldMask paths (proto or Go field names) to columns of T
+func (g GenericCRUD[T]) MaskColumns(mask FieldMask) ([]string, error) {
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, p := range mask.GetPaths() {
+		field := s.LookUpField(p)
+		if field == nil {
+			field = s.LookUpField(g.db.NamingStrategy.ColumnName("", p))
+		}
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("%w: unknown field mask path %q", InvalidFilterError, p)
+		}
+		res = append(res, field.DBName)
+	}
+	return res, nil
+}
+
+// UpdateMask updates only fields of v listed in mask; v MUST have non-zero primary key
+func (g GenericCRUD[T]) UpdateMask(ctx context.Context, v T, mask FieldMask) error {
+	columns, err := g.MaskColumns(mask)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return g.Update(ctx, v)
+	}
+	if err = g.checkEnums(v); err != nil {
+		return err
+	}
+	return g.sessionOf(ctx, v).Model(&v).Select(columns).Omit(g.omits(ctx, g.cfg.Omit)...).Updates(&v).Error
+}
+
+/*
+ListQuery builds Query from List request.
+Filter is a conjunction of comparisons (subset of AIP-160):
+
+	name = "john" AND age >= 18 AND email : "example.com" AND title = 'Q&A AND more'
+
+where ":" is substring match. Values may be quoted with double or single quotes and backslash escapes;
+quoted values may contain " AND " and operators. OrderBy is "created_at desc, id".
+Page token is opaque; use NextPageToken to make one.
+*/
+func (g GenericCRUD[T]) ListQuery(req ListRequest, allowed ...string) (Query, error) {
+	var q Query
+	columns, err := g.allowedColumns(allowed, TagFilterable)
+	if err != nil {
+		return q, err
+	}
+	sortable, err := g.allowedColumns(allowed, TagSortable)
+	if err != nil {
+		return q, err
+	}
+	check := func(c string) error {
+		if _, ok := columns[c]; !ok {
+			return fmt.Errorf("%w: unknown column %q", InvalidFilterError, c)
+		}
+		return nil
+	}
+	checkSort := func(c string) error {
+		if _, ok := sortable[c]; !ok {
+			return fmt.Errorf("%w: unknown sort column %q", InvalidFilterError, c)
+		}
+		return nil
+	}
+	if f := strings.TrimSpace(req.GetFilter()); f != "" {
+		conds, err := splitConditions(f)
+		if err != nil {
+			return q, err
+		}
+		for _, cond := range conds {
+			column, op, value, err := parseComparison(cond)
+			if err != nil {
+				return q, err
+			}
+			if err = check(column); err != nil {
+				return q, err
+			}
+			if err = q.AddFilter(column, op, value); err != nil {
+				return q, err
+			}
+		}
+	}
+	if o := strings.TrimSpace(req.GetOrderBy()); o != "" {
+		for _, item := range strings.Split(o, ",") {
+			column, dir, _ := strings.Cut(strings.TrimSpace(item), " ")
+			if err = checkSort(column); err != nil {
+				return q, err
+			}
+			ob := ASC
+			if dir != "" {
+				if err = ob.UnmarshalText([]byte(strings.TrimSpace(dir))); err != nil {
+					return q, err
+				}
+			}
+			q.OrderBy = append(q.OrderBy, OrderClause{Column: column, Direction: ob})
+		}
+	}
+	q.Limit = int(req.GetPageSize())
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageSize
+	}
+	if q.Limit > MaxPageSize {
+		q.Limit = MaxPageSize
+	}
+	if t := req.GetPageToken(); t != "" {
+		b, err := base64.RawURLEncoding.DecodeString(t)
+		if err == nil {
+			q.Offset, err = strconv.Atoi(string(b))
+		}
+		if err != nil || q.Offset < 0 {
+			return q, fmt.Errorf("%w: page token %q", InvalidFilterError, t)
+		}
+	}
+	return q, nil
+}
+
+// NextPageToken returns page token following q if page of n results is full, empty string otherwise
+func NextPageToken(q Query, n int) string {
+	if q.Limit == 0 || n < q.Limit {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(q.Offset + q.Limit)))
+}
+
+var comparisons = []struct{ token, op string }{
+	{"!=", "ne"}, {">=", "gte"}, {"<=", "lte"}, {"=", "eq"}, {">", "gt"}, {"<", "lt"}, {":", "like"},
+}
+
+// splitConditions splits filter by " AND " outside of quoted values
+func splitConditions(s string) ([]string, error) {
+	var res []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '"' || s[i] == '\'':
+			end, err := quoteEnd(s, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end - 1
+		case strings.HasPrefix(s[i:], " AND "):
+			res = append(res, s[start:i])
+			start = i + len(" AND ")
+			i = start - 1
+		}
+	}
+	return append(res, s[start:]), nil
+}
+
+// quoteEnd returns index after closing quote of value quoted at s[i]
+func quoteEnd(s string, i int) (int, error) {
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case s[i]:
+			return j + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unterminated quote in %q", InvalidFilterError, s)
+}
+
+// parseComparison parses `column op value` returning AddFilter operator name
+func parseComparison(s string) (column, op, value string, err error) {
+	s = strings.TrimSpace(s)
+	n := strings.IndexAny(s, " !=<>:")
+	if n <= 0 {
+		return "", "", "", fmt.Errorf("%w: can't parse condition %q", InvalidFilterError, s)
+	}
+	column, rest := s[:n], strings.TrimLeft(s[n:], " ")
+	for _, c := range comparisons {
+		if strings.HasPrefix(rest, c.token) {
+			value, err = unquoteValue(strings.TrimSpace(rest[len(c.token):]))
+			return column, c.op, value, err
+		}
+	}
+	return "", "", "", fmt.Errorf("%w: can't parse condition %q", InvalidFilterError, s)
+}
+
+// unquoteValue unquotes Go style double quoted or single quoted value; unquoted values are returned as is
+func unquoteValue(s string) (string, error) {
+	if s == "" || s[0] != '"' && s[0] != '\'' {
+		return s, nil
+	}
+	if end, err := quoteEnd(s, 0); err != nil || end != len(s) {
+		return "", fmt.Errorf("%w: malformed quoted value %s", InvalidFilterError, s)
+	}
+	if s[0] == '"' {
+		u, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("%w: malformed quoted value %s", InvalidFilterError, s)
+		}
+		return u, nil
+	}
+	var b strings.Builder
+	for i := 1; i < len(s)-1; i++ {
+		if s[i] == '\\' {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,81 @@
+package crud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	testMask []string
+
+	testListRequest struct {
+		filter, orderBy, pageToken string
+		pageSize                   int32
+	}
+)
+
+func (m testMask) GetPaths() []string { return m }
+
+func (r testListRequest) GetFilter() string    { return r.filter }
+func (r testListRequest) GetOrderBy() string   { return r.orderBy }
+func (r testListRequest) GetPageSize() int32   { return r.pageSize }
+func (r testListRequest) GetPageToken() string { return r.pageToken }
+
+func TestMaskColumns(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	columns, err := g.MaskColumns(testMask{"name", "Age", "created_at"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"name", "age", "created_at"}, columns)
+	_, err = g.MaskColumns(testMask{"password"})
+	require.ErrorIs(t, err, InvalidFilterError)
+}
+
+func TestListQuery(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	q, err := g.ListQuery(testListRequest{
+		filter:   `name = "john smith" AND age >= 18 AND name : "jo"`,
+		orderBy:  "created_at desc, id",
+		pageSize: 10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, Query{
+		Equal:   map[string]any{"name": "john smith"},
+		Gte:     map[string]any{"age": "18"},
+		Like:    map[string]string{"name": "jo"},
+		OrderBy: []OrderClause{{Column: "created_at", Direction: DESC}, {Column: "id", Direction: ASC}},
+		Limit:   10,
+	}, q)
+
+	token := NextPageToken(q, 10)
+	require.NotEmpty(t, token)
+	require.Empty(t, NextPageToken(q, 9))
+	q, err = g.ListQuery(testListRequest{pageToken: token, pageSize: 10})
+	require.NoError(t, err)
+	require.Equal(t, 10, q.Offset)
+
+	_, err = g.ListQuery(testListRequest{filter: "password = 1"})
+	require.ErrorIs(t, err, InvalidFilterError)
+
+	_, err = g.ListQuery(testListRequest{filter: `name = "john`})
+	require.ErrorIs(t, err, InvalidFilterError)
+	_, err = g.ListQuery(testListRequest{filter: `name "john"`})
+	require.ErrorIs(t, err, InvalidFilterError)
+}
+
+func TestListQueryQuoted(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	for filter, want := range map[string]Query{
+		`name = 'a AND b'`:              {Equal: map[string]any{"name": "a AND b"}},
+		`name = "a AND b" AND age > 1`:  {Equal: map[string]any{"name": "a AND b"}, Gt: map[string]any{"age": "1"}},
+		`name != "x>=y"`:                {NotEqual: map[string]any{"name": "x>=y"}},
+		`name:'it\'s'`:                  {Like: map[string]string{"name": "it's"}},
+		`name = "say \"hi\" AND bye\n"`: {Equal: map[string]any{"name": "say \"hi\" AND bye\n"}},
+		`name = john`:                   {Equal: map[string]any{"name": "john"}},
+	} {
+		q, err := g.ListQuery(testListRequest{filter: filter})
+		require.NoError(t, err, filter)
+		want.Limit = DefaultPageSize
+		require.Equal(t, want, q, filter)
+	}
+}
@@ -0,0 +1,74 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// PurgeBatchSize is number of rows deleted by single statement of Purge
+var PurgeBatchSize = 1000
+
+// Purge permanently deletes rows soft-deleted more than olderThan ago in batches; returns number of deleted rows
+func (g GenericCRUD[T]) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	s, err := g.schema()
+	if err != nil {
+		return 0, err
+	}
+	deletedAt := softDeleteField(s)
+	if deletedAt == nil || s.PrioritizedPrimaryField == nil {
+		return 0, fmt.Errorf("%s has no soft delete or primary key", s.Name)
+	}
+	var (
+		pk     = s.PrioritizedPrimaryField.DBName
+		cutoff = g.now().Add(-olderThan)
+		total  int64
+	)
+	for {
+		var ids []any
+		err = g.session(ctx).Unscoped().Model(new(T)).
+			Where(deletedAt.DBName+" < ?", cutoff).
+			Limit(PurgeBatchSize).
+			Pluck(pk, &ids).Error
+		if err != nil || len(ids) == 0 {
+			return total, err
+		}
+		res := g.session(ctx).Unscoped().Where(pk+" IN ?", ids).Delete(new(T))
+		total += res.RowsAffected
+		if res.Error != nil || len(ids) < PurgeBatchSize {
+			return total, res.Error
+		}
+	}
+}
+
+// PurgeEvery runs Purge every interval until ctx is done; errors are passed to onError if not nil
+func (g GenericCRUD[T]) PurgeEvery(ctx context.Context, interval, olderThan time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := g.Purge(ctx, olderThan); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+// softDeleteField returns gorm.DeletedAt field of s
+func softDeleteField(s *schema.Schema) *schema.Field {
+	for _, f := range s.Fields {
+		if f.FieldType == deletedAtType && f.DBName != "" {
+			return f
+		}
+	}
+	return nil
+}
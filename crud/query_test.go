@@ -0,0 +1,504 @@
+package crud
+
+import (
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB returns postgres *gorm.DB that builds statements without connecting
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+// captureSQL records SQL of every statement executed by db
+func captureSQL(t *testing.T, db *gorm.DB) *[]string {
+	var res []string
+	capture := func(tx *gorm.DB) {
+		res = append(res, tx.Statement.SQL.String())
+	}
+	cb := db.Callback()
+	require.NoError(t, cb.Create().After("gorm:create").Register("test:capture", capture))
+	require.NoError(t, cb.Query().After("gorm:query").Register("test:capture", capture))
+	require.NoError(t, cb.Update().After("gorm:update").Register("test:capture", capture))
+	require.NoError(t, cb.Delete().After("gorm:delete").Register("test:capture", capture))
+	require.NoError(t, cb.Row().After("gorm:row").Register("test:capture", capture))
+	require.NoError(t, cb.Raw().After("gorm:raw").Register("test:capture", capture))
+	return &res
+}
+
+// smartSQL returns SQL and vars generated by SmartQuery for q
+func smartSQL(t *testing.T, g GenericCRUD[User], q Query) (string, []any) {
+	stmt, err := g.smartStmt(context.TODO(), q)
+	require.NoError(t, err)
+	var res []*User
+	stmt = stmt.Find(&res)
+	require.NoError(t, stmt.Error)
+	return stmt.Statement.SQL.String(), stmt.Statement.Vars
+}
+
+func TestSmartQueryFullText(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	sql, vars := smartSQL(t, g, Query{
+		FullText: &FullText{Columns: []string{"name"}, Term: "john smith", Rank: true},
+		OrderBy:  []OrderClause{{Column: "id", Direction: ASC}},
+	})
+	require.Contains(t, sql, `to_tsvector($1::regconfig, coalesce(name, '')) @@ plainto_tsquery($2::regconfig, $3)`)
+	require.Contains(t, sql, `ORDER BY ts_rank(to_tsvector($4::regconfig, coalesce(name, '')), plainto_tsquery($5::regconfig, $6)) DESC, "id" ASC`)
+	require.Equal(t, []any{"simple", "simple", "john smith", "simple", "simple", "john smith"}, vars)
+
+	_, err := g.smartStmt(context.TODO(), Query{FullText: &FullText{Term: "x"}})
+	require.Error(t, err)
+}
+
+func TestSmartQueryPattern(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	for _, c := range []struct {
+		p    Pattern
+		sql  string
+		want string
+	}{
+		{Pattern{Value: "jo"}, "name LIKE $1", "%jo%"},
+		{Pattern{Value: "jo", Mode: Prefix}, "name LIKE $1", "jo%"},
+		{Pattern{Value: "jo", Mode: Suffix, Insensitive: true}, "name ILIKE $1", "%jo"},
+		{Pattern{Value: "50%_off", Mode: Exact}, "name LIKE $1", `50\%\_off`},
+	} {
+		sql, vars := smartSQL(t, g, Query{Pattern: map[string]Pattern{"name": c.p}})
+		require.Contains(t, sql, c.sql)
+		require.Equal(t, []any{c.want}, vars)
+	}
+	sql, vars := smartSQL(t, g, Query{ILike: map[string]string{"name": "Jo"}})
+	require.Contains(t, sql, "name ILIKE $1")
+	require.Equal(t, []any{"%Jo%"}, vars)
+}
+
+func TestSmartQueryNot(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	sql, vars := smartSQL(t, g, Query{
+		NotEqual:   map[string]any{"age": 18},
+		NotLike:    map[string]string{"name": "test"},
+		NotBetween: map[string]Between{"id": {From: "1", To: "10"}},
+	})
+	require.Contains(t, sql, "age <> $1")
+	require.Contains(t, sql, "name NOT LIKE $2")
+	require.Contains(t, sql, "id NOT BETWEEN $3 AND $4")
+	require.Equal(t, []any{18, "%test%", "1", "10"}, vars)
+}
+
+func TestSmartQueryEqualTyped(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	now := time.Date(2023, 1, 23, 0, 0, 0, 0, time.UTC)
+	for _, v := range []any{18, int64(18), true, now} {
+		sql, vars := smartSQL(t, g, Query{Equal: map[string]any{"col": v}})
+		require.Contains(t, sql, "col = $1")
+		require.Equal(t, []any{v}, vars)
+	}
+	sql, vars := smartSQL(t, g, Query{Between: map[string]Between{"created_at": {From: now, To: now.AddDate(0, 0, 1)}}})
+	require.Contains(t, sql, "created_at BETWEEN $1 AND $2")
+	require.Equal(t, []any{now, now.AddDate(0, 0, 1)}, vars)
+}
+
+func TestSmartQueryStableOrder(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	q := Query{
+		Equal: map[string]any{"name": "ann", "age": 30, "id": 1, "created_at": "2023-01-23", "updated_at": "2023-01-24"},
+		Gt:    map[string]any{"id": 0, "age": 18},
+	}
+	for i := 0; i < 20; i++ {
+		sql, vars := smartSQL(t, g, q)
+		require.Equal(t, `SELECT * FROM "users" WHERE age = $1 AND created_at = $2 AND id = $3 AND name = $4 AND updated_at = $5 `+
+			`AND age > $6 AND id > $7 AND "users"."deleted_at" IS NULL`, sql)
+		require.Equal(t, []any{30, "2023-01-23", 1, "ann", "2023-01-24", 18, 0}, vars)
+	}
+}
+
+func TestFromID(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	v, err := g.FromID("42")
+	require.NoError(t, err)
+	require.Equal(t, uint(42), v.ID)
+	_, err = g.FromID("forty-two")
+	require.Error(t, err)
+}
+
+func TestFromMap(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	v, err := g.FromMap(map[string]any{"id": 1, "name": "test", "Age": 11})
+	require.NoError(t, err)
+	require.Equal(t, uint(1), v.ID)
+	require.Equal(t, "test", v.Name)
+	require.Equal(t, a1, v.Age)
+	_, err = g.FromMap(map[string]any{"password": "x"})
+	require.ErrorIs(t, err, InvalidFilterError)
+}
+
+func TestScanQuery(t *testing.T) {
+	db := dryRunDB(t)
+	sql := captureSQL(t, db)
+	g := New[User](db)
+	var names []struct{ Name string }
+	require.NoError(t, g.ScanQuery(context.TODO(), Query{Select: []string{"name"}, Like: map[string]string{"name": "te"}}, &names))
+	require.Equal(t, `SELECT "name" FROM "users" WHERE name LIKE $1 AND "users"."deleted_at" IS NULL`, (*sql)[0])
+	var rows []map[string]any
+	require.NoError(t, g.ScanQuery(context.TODO(), Query{Select: []string{"count(*) AS n"}}, &rows))
+	require.Equal(t, `SELECT count(*) AS n FROM "users" WHERE "users"."deleted_at" IS NULL`, (*sql)[1])
+}
+
+func TestSmartQueryGroupBy(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	sql, vars := smartSQL(t, g, Query{
+		Select:  []string{"name", "count(*) AS n"},
+		GroupBy: []string{"name"},
+		Having:  []Expr{{SQL: "count(*) > ?", Vars: []any{1}}},
+	})
+	require.Contains(t, sql, `GROUP BY "name" HAVING count(*) > $1`)
+	require.Equal(t, []any{1}, vars)
+}
+
+func TestSmartQueryWindow(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	sql, vars := smartSQL(t, g, Query{
+		Like: map[string]string{"name": "te"},
+		Window: &Window{
+			PartitionBy: []string{"name"},
+			OrderBy:     []OrderClause{{Column: "created_at", Direction: DESC}},
+			Max:         1,
+		},
+		OrderBy: []OrderClause{{Column: "id", Direction: ASC}},
+	})
+	require.Equal(t, `SELECT * FROM (SELECT *, ROW_NUMBER() OVER (PARTITION BY "name" ORDER BY "created_at" DESC) AS "window_rank" `+
+		`FROM "users" WHERE name LIKE $1 AND "users"."deleted_at" IS NULL) AS "users" `+
+		`WHERE "window_rank" <= $2 AND "users"."deleted_at" IS NULL ORDER BY "id" ASC`, sql)
+	require.Equal(t, []any{"%te%", 1}, vars)
+
+	_, err := g.smartStmt(context.TODO(), Query{Window: &Window{Func: "pg_sleep"}})
+	require.ErrorIs(t, err, InvalidFilterError)
+
+	hostile := `{"window":{"partition_by":["name"],"alias":"rn FROM users; DROP TABLE users; --"}}`
+	_, err = g.DecodeQuery([]byte(hostile))
+	require.ErrorIs(t, err, InvalidFilterError)
+	_, err = g.smartStmt(context.TODO(), Query{Window: &Window{Alias: "rn FROM users; --"}})
+	require.ErrorIs(t, err, InvalidFilterError)
+
+	hostile = `{"window":{"order_by":[{"column":"id","func":"pg_sleep(10)||"}]}}`
+	_, err = g.DecodeQuery([]byte(hostile))
+	require.ErrorIs(t, err, InvalidFilterError)
+	_, err = g.smartStmt(context.TODO(), Query{Window: &Window{OrderBy: []OrderClause{{Column: "id", Func: "pg_sleep(10)||"}}}})
+	require.ErrorIs(t, err, InvalidFilterError)
+	sql, _ = smartSQL(t, g, Query{Window: &Window{OrderBy: []OrderClause{{Column: "name", Func: "lower", NullsLast: true}}}})
+	require.Contains(t, sql, `OVER (ORDER BY LOWER("name") ASC NULLS LAST) AS "window_rank"`)
+}
+
+func TestSmartQuerySubquery(t *testing.T) {
+	db := dryRunDB(t)
+	users := New[User](db)
+	sql, vars := smartSQL(t, users, Query{
+		InQuery: map[string]Subquery{"id": users.Subquery(Query{Gt: map[string]any{"age": 18}}, "id")},
+	})
+	require.Equal(t, `SELECT * FROM "users" WHERE id IN (SELECT "id" FROM "users" WHERE age > $1 AND "users"."deleted_at" IS NULL) AND "users"."deleted_at" IS NULL`, sql)
+	require.Equal(t, []any{18}, vars)
+}
+
+func TestSmartQueryGeo(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	sql, vars := smartSQL(t, g, Query{
+		Radius:  WithinRadius("location", 52.5, 13.4, 500),
+		BBox:    WithinBBox("location", 52, 13, 53, 14),
+		OrderBy: []OrderClause{{Column: "id", Direction: ASC}},
+	})
+	require.Contains(t, sql, `ST_DWithin("location"::geography, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, $3)`)
+	require.Contains(t, sql, `"location"::geometry && ST_MakeEnvelope($4, $5, $6, $7, 4326)`)
+	require.Contains(t, sql, `ORDER BY ST_Distance("location"::geography, ST_SetSRID(ST_MakePoint($8, $9), 4326)::geography), "id" ASC`)
+	require.Equal(t, []any{13.4, 52.5, 500.0, 13.0, 52.0, 14.0, 53.0, 13.4, 52.5}, vars)
+
+	cond, _, err := Radius{Column: "location", Lat: 1, Lng: 2, Meters: 3}.build(MySQL)
+	require.NoError(t, err)
+	require.Equal(t, "ST_Distance_Sphere(?, POINT(?, ?)) <= ?", cond.SQL)
+	_, _, err = Radius{Column: "location"}.build(SQLite)
+	require.Error(t, err)
+	_, err = BBox{Column: "location"}.build(SQLite)
+	require.Error(t, err)
+	require.Equal(t, []string{"location"}, Query{BBox: &BBox{Column: "location"}}.Columns())
+}
+
+func TestSmartQueryArray(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	sql, vars := smartSQL(t, g, Query{
+		ArrayContains:    map[string][]any{"tags": {"a", `b"c`}},
+		ArrayContainedBy: map[string][]any{"ids": {1, 2}},
+		ArrayOverlaps:    map[string][]any{"roles": {"admin", nil}},
+		ArrayAny:         map[string]any{"tags": "x"},
+	})
+	require.Contains(t, sql, `tags @> $1 AND ids <@ $2 AND roles && $3 AND $4 = ANY(tags)`)
+	require.Equal(t, []any{`{"a","b\"c"}`, `{"1","2"}`, `{"admin",NULL}`, "x"}, vars)
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+	_, err = New[User](db).smartStmt(context.TODO(), Query{ArrayAny: map[string]any{"tags": "x"}})
+	require.Error(t, err)
+}
+
+func TestFirstLast(t *testing.T) {
+	db := dryRunDB(t)
+	stmts := captureSQL(t, db)
+	g := New[User](db)
+
+	_, err := g.First(context.TODO(), Query{Equal: map[string]any{"name": "x"}})
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.Contains(t, (*stmts)[0], `WHERE name = $1 AND "users"."deleted_at" IS NULL ORDER BY "id" ASC LIMIT 1`)
+
+	_, err = g.Last(context.TODO(), Query{})
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.Contains(t, (*stmts)[1], `ORDER BY "id" DESC LIMIT 1`)
+
+	_, err = g.Last(context.TODO(), Query{OrderBy: []OrderClause{{Column: "created_at", Direction: DESC}}})
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.Contains(t, (*stmts)[2], `ORDER BY "created_at" ASC, "id" ASC LIMIT 1`)
+}
+
+func TestSample(t *testing.T) {
+	db := dryRunDB(t)
+	stmts := captureSQL(t, db)
+	_, err := New[User](db).Sample(context.TODO(), Query{
+		Equal:   map[string]any{"name": "x"},
+		OrderBy: []OrderClause{{Column: "id", Direction: ASC}},
+		Offset:  10,
+	}, 5)
+	require.NoError(t, err)
+	require.Contains(t, (*stmts)[0], `WHERE name = $1 AND "users"."deleted_at" IS NULL ORDER BY RANDOM() LIMIT 5`)
+	require.Equal(t, "RAND()", MySQL.Random())
+}
+
+func TestDefaultOrder(t *testing.T) {
+	db := dryRunDB(t)
+	stmts := captureSQL(t, db)
+	g := New[User](db).WithDefaultOrder("created_at DESC")
+	ctx := context.TODO()
+
+	_, err := g.SmartQuery(ctx, Query{Limit: 10})
+	require.NoError(t, err)
+	require.Contains(t, (*stmts)[0], `ORDER BY created_at DESC, "id" ASC LIMIT 10`)
+	_, err = g.SmartQuery(ctx, Query{OrderBy: []OrderClause{{Column: "name", Direction: ASC}}})
+	require.NoError(t, err)
+	require.Contains(t, (*stmts)[1], `ORDER BY "name" ASC`)
+	require.NotContains(t, (*stmts)[1], `created_at`)
+	_, err = g.Query(ctx, User{Name: "x"})
+	require.NoError(t, err)
+	require.Contains(t, (*stmts)[2], `ORDER BY created_at DESC`)
+	_, err = g.QueryMap(ctx, map[string]any{"name": "x"})
+	require.NoError(t, err)
+	require.Contains(t, (*stmts)[3], `ORDER BY created_at DESC`)
+	_, err = g.Sample(ctx, Query{}, 1)
+	require.NoError(t, err)
+	require.NotContains(t, (*stmts)[4], `created_at`)
+}
+
+func TestTieBreaker(t *testing.T) {
+	db := dryRunDB(t)
+	stmts := captureSQL(t, db)
+	g := New[User](db)
+	ctx := context.TODO()
+
+	_, err := g.SmartQuery(ctx, Query{OrderBy: []OrderClause{{Column: "name", Direction: DESC}}, Limit: 10, Offset: 10})
+	require.NoError(t, err)
+	require.Contains(t, (*stmts)[0], `ORDER BY "name" DESC, "id" DESC LIMIT 10 OFFSET 10`)
+	_, err = g.SmartQuery(ctx, Query{OrderBy: []OrderClause{{Column: "name", Direction: ASC}}})
+	require.NoError(t, err)
+	require.NotContains(t, (*stmts)[1], `id ASC`)
+	_, err = g.SmartQuery(ctx, Query{OrderBy: []OrderClause{{Column: "id", Direction: DESC}}, Limit: 10})
+	require.NoError(t, err)
+	require.Contains(t, (*stmts)[2], `ORDER BY "id" DESC LIMIT 10`)
+	_, err = g.WithTieBreaker(false).SmartQuery(ctx, Query{OrderBy: []OrderClause{{Column: "name", Direction: ASC}}, Limit: 10})
+	require.NoError(t, err)
+	require.Contains(t, (*stmts)[3], `ORDER BY "name" ASC LIMIT 10`)
+}
+
+func TestQueryOneLimit(t *testing.T) {
+	db := dryRunDB(t)
+	stmts := captureSQL(t, db)
+	g := New[User](db)
+	_, err := g.QueryOne(context.TODO(), User{Name: "x"})
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.Contains(t, (*stmts)[0], `LIMIT 2`)
+	_, err = g.QueryMapOne(context.TODO(), map[string]any{"name": "x"})
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.Contains(t, (*stmts)[1], `LIMIT 2`)
+	_, err = g.SmartQueryOne(context.TODO(), Query{Equal: map[string]any{"name": "x"}})
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.Contains(t, (*stmts)[2], `LIMIT 2`)
+}
+
+func TestJoinLink(t *testing.T) {
+	db := dryRunDB(t)
+	stmts := captureSQL(t, db)
+	j := NewJoinCRUD(New[User](db), New[User](db), "user_friends", "user_id", "friend_id")
+	a, b := User{Model: gorm.Model{ID: 1}}, User{Model: gorm.Model{ID: 2}}
+	require.NoError(t, j.Link(context.TODO(), a, b, map[string]any{"since": "2024-01-01"}))
+	require.Contains(t, (*stmts)[0], `INSERT INTO "user_friends" ("friend_id","since","user_id") VALUES ($1,$2,$3) ON CONFLICT ("user_id","friend_id") DO UPDATE SET "since"="excluded"."since"`)
+	_, err := j.ListRight(context.TODO(), a, Query{})
+	require.NoError(t, err)
+	require.Contains(t, (*stmts)[len(*stmts)-1], `WHERE id IN (SELECT friend_id FROM "user_friends" WHERE "user_id" = $1)`)
+}
+
+type comment struct {
+	gorm.Model
+	OwnerType string
+	OwnerID   *uint
+	Body      string
+}
+
+func (c comment) PrimaryKey() any {
+	return c.ID
+}
+
+func TestOwnerFilter(t *testing.T) {
+	db := dryRunDB(t)
+	comments := New[comment](db)
+	post, err := New[User](db).OwnerOf(context.TODO(), User{Model: gorm.Model{ID: 7}})
+	require.NoError(t, err)
+	require.Equal(t, OwnedBy("users", uint(7)), post)
+	stmt, err := comments.smartStmt(context.TODO(), Query{Owner: post})
+	require.NoError(t, err)
+	stmt = stmt.Find(&[]*comment{})
+	require.NoError(t, stmt.Error)
+	sql, vars := stmt.Statement.SQL.String(), stmt.Statement.Vars
+	require.Equal(t, `SELECT * FROM "comments" WHERE ("owner_type" = $1 AND "owner_id" = $2) AND "comments"."deleted_at" IS NULL`, sql)
+	require.Equal(t, []any{"users", uint(7)}, vars)
+	require.Equal(t, []string{"owner_type", "owner_id"}, Query{Owner: post}.Columns())
+	require.NoError(t, comments.ValidateQuery(Query{Owner: post}))
+	require.Error(t, comments.ValidateQuery(Query{Owner: &OwnerFilter{Type: "users", ID: 1, IDColumn: "parent_id"}}))
+	_, err = comments.SmartQuery(context.TODO(), Query{Owner: &OwnerFilter{Type: "users"}})
+	require.ErrorIs(t, err, InvalidFilterError)
+
+	stmts := captureSQL(t, db)
+	c := comment{Model: gorm.Model{ID: 3}}
+	require.NoError(t, comments.Attach(context.TODO(), c, *post))
+	require.Contains(t, (*stmts)[0], `UPDATE "comments" SET "owner_id"=$1,"owner_type"=$2,"updated_at"=$3 WHERE "comments"."deleted_at" IS NULL AND "id" = $4`)
+	_, err = comments.Detach(context.TODO(), c, *post)
+	require.NoError(t, err)
+	require.Contains(t, (*stmts)[1], `UPDATE "comments" SET "owner_id"=$1,"owner_type"=$2,"updated_at"=$3 WHERE ("owner_type" = $4 AND "owner_id" = $5) AND "comments"."deleted_at" IS NULL AND "id" = $6`)
+}
+
+func TestOrderClauses(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	sql, _ := smartSQL(t, g, Query{OrderBy: []OrderClause{
+		{Column: "name"}, {Column: "age", Direction: DESC, NullsLast: true}, {Column: "CreatedAt", Direction: DESC},
+	}})
+	require.Contains(t, sql, `ORDER BY "name" ASC, "age" DESC NULLS LAST, "created_at" DESC`)
+	_, err := g.SmartQuery(context.TODO(), Query{OrderBy: []OrderClause{{Column: "name; DROP TABLE users"}}})
+	require.ErrorIs(t, err, InvalidFilterError)
+
+	require.Equal(t, "? IS NULL, ? ASC", MySQL.order(OrderClause{Column: "age", NullsLast: true}, "").SQL)
+	require.Equal(t, "? IS NULL DESC, LOWER(?) DESC", MySQL.order(OrderClause{Column: "name", Func: "lower", Direction: DESC, NullsFirst: true}, "").SQL)
+	require.Equal(t, "age DESC NULLS LAST", OrderClause{Column: "age", Direction: DESC, NullsLast: true}.String())
+}
+
+func TestOrderExpression(t *testing.T) {
+	db := dryRunDB(t)
+	stmts := captureSQL(t, db)
+	g := New[User](db)
+	q := Query{OrderBy: []OrderClause{{Column: "name", Func: "lower", NullsFirst: true}}}
+	_, err := g.First(context.TODO(), q)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.Contains(t, (*stmts)[0], `ORDER BY LOWER("name") ASC NULLS FIRST, "id" ASC LIMIT 1`)
+	_, err = g.Last(context.TODO(), q)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.Contains(t, (*stmts)[1], `ORDER BY LOWER("name") DESC NULLS LAST, "id" DESC LIMIT 1`)
+
+	for _, o := range []OrderClause{{Column: "name", Func: "md5"}, {Column: "name", NullsFirst: true, NullsLast: true}} {
+		_, err = g.SmartQuery(context.TODO(), Query{OrderBy: []OrderClause{o}})
+		require.ErrorIs(t, err, InvalidFilterError)
+		require.ErrorIs(t, g.ValidateQuery(Query{OrderBy: []OrderClause{o}}), InvalidFilterError)
+	}
+}
+
+func TestCollation(t *testing.T) {
+	g := New[User](dryRunDB(t)).WithCollation("C")
+	sql, _ := smartSQL(t, g, Query{
+		OrderBy:   []OrderClause{{Column: "name", Func: "lower"}, {Column: "age", Direction: DESC}},
+		Collation: "uk-UA-x-icu",
+	})
+	require.Contains(t, sql, `ORDER BY LOWER("name") COLLATE "uk-UA-x-icu" ASC, "age" DESC`)
+	sql, _ = smartSQL(t, g, Query{OrderBy: []OrderClause{{Column: "name", NullsLast: true}}})
+	require.Contains(t, sql, `ORDER BY "name" COLLATE "C" ASC NULLS LAST`)
+	_, err := g.SmartQuery(context.TODO(), Query{OrderBy: []OrderClause{{Column: "name"}}, Collation: `x" DESC; --`})
+	require.ErrorIs(t, err, InvalidFilterError)
+}
+
+func TestConflicts(t *testing.T) {
+	q := Query{
+		Like:  map[string]string{"name": "test"},
+		Equal: map[string]any{"name": "test2"},
+		Gt:    map[string]any{"age": 1},
+		Lt:    map[string]any{"age": 10},
+		Gte:   map[string]any{"id": 1},
+		In:    map[string][]any{"id": {1, 2}},
+	}
+	require.Equal(t, []Conflict{{Column: "name", Operators: []string{"like", "equal"}}}, q.Conflicts())
+	q.Between = map[string]Between{"age": {From: 2, To: 5}}
+	require.Equal(t, []Conflict{
+		{Column: "age", Operators: []string{"between", "gt", "lt"}},
+		{Column: "name", Operators: []string{"like", "equal"}},
+	}, q.Conflicts())
+
+	g := New[User](dryRunDB(t))
+	sql, _ := smartSQL(t, g, q)
+	require.Contains(t, sql, "name LIKE")
+	_, err := g.WithConflicts(ConflictsError).SmartQuery(context.TODO(), q)
+	require.ErrorIs(t, err, ConflictingFilterError)
+	require.ErrorIs(t, err, InvalidFilterError)
+	require.EqualError(t, err, "invalid filter: conflicting conditions: age: between, gt, lt; name: like, equal")
+
+	sql, vars := smartSQL(t, g.WithConflicts(ConflictsLastWins), q)
+	require.NotContains(t, sql, "LIKE")
+	require.NotContains(t, sql, "BETWEEN")
+	require.Contains(t, sql, "name = $")
+	require.Contains(t, sql, "age > $")
+	require.Contains(t, sql, "age < $")
+	require.Len(t, vars, 6)
+	require.Len(t, q.Like, 1, "query maps are not modified")
+
+	var buf strings.Builder
+	g = NewWithConfig[User](dryRunDB(t), Config{Logger: log.New(&buf, "", 0), Conflicts: ConflictsWarn})
+	smartSQL(t, g, q)
+	require.Equal(t, "crud: conflicting conditions of age: between, gt, lt\ncrud: conflicting conditions of name: like, equal\n", buf.String())
+}
+
+func TestNamedQuery(t *testing.T) {
+	g := New[User](dryRunDB(t)).RegisterQuery("adults", Query{
+		Gte:     map[string]any{"age": Param("age")},
+		In:      map[string][]any{"id": {Param("ids")}},
+		Like:    map[string]string{"name": "{prefix} %"},
+		OrderBy: []OrderClause{{Column: "name"}},
+	})
+	require.Equal(t, []string{"adults"}, g.Queries())
+	q, err := g.Named("adults", map[string]any{"age": 18, "ids": []int{1, 2}, "prefix": "Dr."})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"age": 18}, q.Gte)
+	require.Equal(t, []any{1, 2}, q.In["id"])
+	require.Equal(t, "Dr. %", q.Like["name"])
+	sql, vars := smartSQL(t, g, q)
+	require.Contains(t, sql, "age >= $")
+	require.Contains(t, sql, "id IN ($")
+	require.Len(t, vars, 4)
+
+	_, err = g.NamedQuery(context.TODO(), "adults", map[string]any{"age": 18, "prefix": "Dr."})
+	require.ErrorIs(t, err, InvalidFilterError)
+	require.ErrorContains(t, err, `missing parameter "ids"`)
+	_, err = g.NamedQuery(context.TODO(), "children", nil)
+	require.ErrorIs(t, err, UnknownQueryError)
+	require.Empty(t, New[User](dryRunDB(t)).Queries())
+}
@@ -0,0 +1,150 @@
+package crud
+
+import (
+	"context"
+	"io"
+	"net/url"
+
+	"gorm.io/gorm"
+)
+
+// ReadOnlyCRUD wraps GenericCRUD exposing only query methods, e.g. for models backed by SQL views;
+// writes do not compile
+type ReadOnlyCRUD[T GORMModel] struct {
+	crud GenericCRUD[T]
+}
+
+// NewReadOnly is a constructor
+func NewReadOnly[T GORMModel](db *gorm.DB, omit ...string) ReadOnlyCRUD[T] {
+	return New[T](db, omit...).ReadOnly()
+}
+
+// ReadOnly returns read-only view of g
+func (g GenericCRUD[T]) ReadOnly() ReadOnlyCRUD[T] {
+	return ReadOnlyCRUD[T]{crud: g}
+}
+
+// WithTable returns copy of r pinned to table or view; see GenericCRUD.WithTable
+func (r ReadOnlyCRUD[T]) WithTable(table string) ReadOnlyCRUD[T] {
+	return r.crud.WithTable(table).ReadOnly()
+}
+
+// WithSchema returns copy of r qualifying tables with schema; see GenericCRUD.WithSchema
+func (r ReadOnlyCRUD[T]) WithSchema(schema string) ReadOnlyCRUD[T] {
+	return r.crud.WithSchema(schema).ReadOnly()
+}
+
+// WithDB returns copy of r using db, e.g. read replica
+func (r ReadOnlyCRUD[T]) WithDB(db *gorm.DB) ReadOnlyCRUD[T] {
+	return r.crud.WithDB(db).ReadOnly()
+}
+
+// With returns copy of r with opts applied
+func (r ReadOnlyCRUD[T]) With(opts ...CallOption) ReadOnlyCRUD[T] {
+	return r.crud.With(opts...).ReadOnly()
+}
+
+// GetByID get Model by primary key; v MUST have non-zero primary key
+func (r ReadOnlyCRUD[T]) GetByID(ctx context.Context, v T) (*T, error) {
+	return r.crud.GetByID(ctx, v)
+}
+
+// Query by non-zero fields of v
+func (r ReadOnlyCRUD[T]) Query(ctx context.Context, v T, omit ...string) ([]*T, error) {
+	return r.crud.Query(ctx, v, omit...)
+}
+
+// QueryOne by non-zero fields of v; returns exactly one Model or error
+func (r ReadOnlyCRUD[T]) QueryOne(ctx context.Context, v T, omit ...string) (*T, error) {
+	return r.crud.QueryOne(ctx, v, omit...)
+}
+
+// QueryMap by column values
+func (r ReadOnlyCRUD[T]) QueryMap(ctx context.Context, q map[string]any, omit ...string) ([]*T, error) {
+	return r.crud.QueryMap(ctx, q, omit...)
+}
+
+// QueryMapOne by column values; returns exactly one Model or error
+func (r ReadOnlyCRUD[T]) QueryMapOne(ctx context.Context, q map[string]any, omit ...string) (*T, error) {
+	return r.crud.QueryMapOne(ctx, q, omit...)
+}
+
+// SmartQuery Models matching q
+func (r ReadOnlyCRUD[T]) SmartQuery(ctx context.Context, q Query) ([]*T, error) {
+	return r.crud.SmartQuery(ctx, q)
+}
+
+// SmartQueryOne returns exactly one Model matching q or error
+func (r ReadOnlyCRUD[T]) SmartQueryOne(ctx context.Context, q Query) (*T, error) {
+	return r.crud.SmartQueryOne(ctx, q)
+}
+
+// ScanQuery scans rows matching q into dest
+func (r ReadOnlyCRUD[T]) ScanQuery(ctx context.Context, q Query, dest any) error {
+	return r.crud.ScanQuery(ctx, q, dest)
+}
+
+// Count Models matching q
+func (r ReadOnlyCRUD[T]) Count(ctx context.Context, q Query) (int64, error) {
+	return r.crud.Count(ctx, q)
+}
+
+// First returns first Model matching q; see GenericCRUD.First
+func (r ReadOnlyCRUD[T]) First(ctx context.Context, q Query) (*T, error) {
+	return r.crud.First(ctx, q)
+}
+
+// Last returns last Model matching q; see GenericCRUD.Last
+func (r ReadOnlyCRUD[T]) Last(ctx context.Context, q Query) (*T, error) {
+	return r.crud.Last(ctx, q)
+}
+
+// Sample returns up to n random Models matching q
+func (r ReadOnlyCRUD[T]) Sample(ctx context.Context, q Query, n int) ([]*T, error) {
+	return r.crud.Sample(ctx, q, n)
+}
+
+// Export writes Models matching q to w
+func (r ReadOnlyCRUD[T]) Export(ctx context.Context, q Query, format ExportFormat, w io.Writer) error {
+	return r.crud.Export(ctx, q, format, w)
+}
+
+// Explain returns query plan of q
+func (r ReadOnlyCRUD[T]) Explain(ctx context.Context, q Query) (string, error) {
+	return r.crud.Explain(ctx, q)
+}
+
+// Subquery selecting column of Models matching q
+func (r ReadOnlyCRUD[T]) Subquery(q Query, column string) Subquery {
+	return r.crud.Subquery(q, column)
+}
+
+// FromID returns T with primary key set to id
+func (r ReadOnlyCRUD[T]) FromID(id any) (T, error) {
+	return r.crud.FromID(id)
+}
+
+// DecodeQuery decodes and validates JSON query; see GenericCRUD.DecodeQuery
+func (r ReadOnlyCRUD[T]) DecodeQuery(data []byte, allowed ...string) (Query, error) {
+	return r.crud.DecodeQuery(data, allowed...)
+}
+
+// ParseQuery parses URL query; see GenericCRUD.ParseQuery
+func (r ReadOnlyCRUD[T]) ParseQuery(values url.Values, allowed ...string) (Query, error) {
+	return r.crud.ParseQuery(values, allowed...)
+}
+
+// ListQuery converts list request; see GenericCRUD.ListQuery
+func (r ReadOnlyCRUD[T]) ListQuery(req ListRequest, allowed ...string) (Query, error) {
+	return r.crud.ListQuery(req, allowed...)
+}
+
+// Dialect of database
+func (r ReadOnlyCRUD[T]) Dialect() Dialect {
+	return r.crud.Dialect()
+}
+
+// Schema returns metadata of T
+func (r ReadOnlyCRUD[T]) Schema() (ModelSchema, error) {
+	return r.crud.Schema()
+}
@@ -0,0 +1,68 @@
+package crud
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Registry holds GenericCRUD instances for many models sharing db and Config
+type Registry struct {
+	mu     sync.RWMutex
+	db     *gorm.DB
+	cfg    Config
+	cruds  map[reflect.Type]any
+	models []any
+}
+
+// NewRegistry is a constructor
+func NewRegistry(db *gorm.DB, cfg Config) *Registry {
+	return &Registry{db: db, cfg: cfg, cruds: make(map[reflect.Type]any)}
+}
+
+// DB returns db shared by registered models
+func (r *Registry) DB() *gorm.DB {
+	return r.db
+}
+
+// Models returns pointers to zero values of registered models in registration order
+func (r *Registry) Models() []any {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]any(nil), r.models...)
+}
+
+// Register creates GenericCRUD for T with shared configuration; omit is appended to Config.Omit.
+// Registering T again replaces previous instance
+func Register[T GORMModel](r *Registry, omit ...string) GenericCRUD[T] {
+	cfg := r.cfg
+	cfg.Omit = append(append([]string(nil), cfg.Omit...), omit...)
+	g := NewWithConfig[T](r.db, cfg)
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.cruds[t]; !ok {
+		r.models = append(r.models, new(T))
+	}
+	r.cruds[t] = g
+	return g
+}
+
+// Get returns registered GenericCRUD for T
+func Get[T GORMModel](r *Registry) (GenericCRUD[T], bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	g, ok := r.cruds[reflect.TypeOf((*T)(nil)).Elem()].(GenericCRUD[T])
+	return g, ok
+}
+
+// MustGet returns registered GenericCRUD for T; panics if T is not registered
+func MustGet[T GORMModel](r *Registry) GenericCRUD[T] {
+	g, ok := Get[T](r)
+	if !ok {
+		panic(fmt.Sprintf("crud: %T is not registered", *new(T)))
+	}
+	return g
+}
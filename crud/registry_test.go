@@ -0,0 +1,21 @@
+package crud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(dryRunDB(t), Config{Omit: []string{"deleted_at"}})
+	_, ok := Get[User](r)
+	require.False(t, ok)
+	require.Panics(t, func() { MustGet[User](r) })
+
+	g := Register[User](r, "age")
+	require.Equal(t, []string{"deleted_at", "age"}, g.Config().Omit)
+	require.Equal(t, g, MustGet[User](r))
+	Register[User](r)
+	require.Len(t, r.Models(), 1)
+	require.IsType(t, &User{}, r.Models()[0])
+}
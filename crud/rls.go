@@ -0,0 +1,97 @@
+package crud
+
+import (
+	"context"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// SessionSettings returns Postgres settings for the caller in ctx, e.g. {"app.tenant_id": "42"} for row-level security
+type SessionSettings func(ctx context.Context) map[string]string
+
+const (
+	sessionSettingsKey     = "crud:session_settings"
+	sessionSettingsStarted = "crud:session_settings_started"
+)
+
+// WithSessionSettings returns copy of g applying settings with set_config(name, value, true) (SET LOCAL)
+// before every statement, within transaction of ctx or a transaction started for the statement.
+// Rows and raw Scan (Export, Tree queries) are not wrapped; call them within RunInTransaction
+func (g GenericCRUD[T]) WithSessionSettings(settings SessionSettings) GenericCRUD[T] {
+	g.cfg.SessionSettings = settings
+	return g
+}
+
+// withSessionSettings marks db with session settings of ctx for callbacks applying them
+func (g GenericCRUD[T]) withSessionSettings(ctx context.Context, db *gorm.DB) *gorm.DB {
+	settings := g.cfg.SessionSettings(ctx)
+	if len(settings) == 0 {
+		return db
+	}
+	return db.Set(sessionSettingsKey, settings)
+}
+
+// registerSessionSettings adds callbacks applying session settings to db; see Setup
+func registerSessionSettings(db *gorm.DB) {
+	const begin, end = "crud:session_settings_begin", "crud:session_settings_end"
+	if db.Callback().Query().Get(begin) != nil {
+		return
+	}
+	cb := db.Callback()
+	_ = cb.Query().Before("gorm:query").Register(begin, beginSessionSettings)
+	_ = cb.Query().After("gorm:after_query").Register(end, endSessionSettings)
+	_ = cb.Create().Before("gorm:before_create").Register(begin, beginSessionSettings)
+	_ = cb.Create().After("gorm:after_create").Register(end, endSessionSettings)
+	_ = cb.Update().Before("gorm:before_update").Register(begin, beginSessionSettings)
+	_ = cb.Update().After("gorm:after_update").Register(end, endSessionSettings)
+	_ = cb.Delete().Before("gorm:before_delete").Register(begin, beginSessionSettings)
+	_ = cb.Delete().After("gorm:after_delete").Register(end, endSessionSettings)
+	_ = cb.Raw().Before("gorm:raw").Register(begin, beginSessionSettings)
+	_ = cb.Raw().After("gorm:raw").Register(end, endSessionSettings)
+}
+
+// beginSessionSettings starts transaction if statement is not in one and applies settings
+func beginSessionSettings(db *gorm.DB) {
+	v, ok := db.Get(sessionSettingsKey)
+	if !ok || db.Error != nil {
+		return
+	}
+	settings := v.(map[string]string)
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); !ok {
+		tx := db.Begin()
+		if tx.Error != nil {
+			_ = db.AddError(tx.Error)
+			return
+		}
+		db.Statement.ConnPool = tx.Statement.ConnPool
+		db.InstanceSet(sessionSettingsStarted, true)
+	}
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, "SELECT set_config($1, $2, true)", name, settings[name])
+		if err != nil {
+			_ = db.AddError(err)
+			return
+		}
+	}
+}
+
+// endSessionSettings commits or rolls back transaction started by beginSessionSettings
+func endSessionSettings(db *gorm.DB) {
+	if _, ok := db.InstanceGet(sessionSettingsStarted); !ok {
+		return
+	}
+	if tx, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		if db.Error != nil {
+			_ = tx.Rollback()
+		} else {
+			_ = db.AddError(tx.Commit())
+		}
+	}
+	db.Statement.ConnPool = db.ConnPool
+}
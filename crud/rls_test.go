@@ -0,0 +1,136 @@
+package crud
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// recordingPool is gorm.ConnPool logging executed statements and transactions
+type recordingPool struct {
+	log *[]string
+}
+
+func (p recordingPool) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, nil
+}
+
+func (p recordingPool) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
+	*p.log = append(*p.log, fmt.Sprint(query, args))
+	return driver.RowsAffected(1), nil
+}
+
+// QueryContext records query and fails as there are no rows to return
+func (p recordingPool) QueryContext(_ context.Context, query string, args ...any) (*sql.Rows, error) {
+	*p.log = append(*p.log, fmt.Sprint(query, args))
+	return nil, errRecorded
+}
+
+func (p recordingPool) QueryRowContext(context.Context, string, ...any) *sql.Row {
+	return nil
+}
+
+func (p recordingPool) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	*p.log = append(*p.log, "BEGIN")
+	return &recordingTx{p}, nil
+}
+
+type recordingTx struct {
+	recordingPool
+}
+
+func (tx *recordingTx) Commit() error {
+	*tx.log = append(*tx.log, "COMMIT")
+	return nil
+}
+
+func (tx *recordingTx) Rollback() error {
+	*tx.log = append(*tx.log, "ROLLBACK")
+	return nil
+}
+
+var errRecorded = errors.New("recorded")
+
+type tenantKey struct{}
+
+func TestSessionSettings(t *testing.T) {
+	var log []string
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: recordingPool{&log}}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	g := New[User](db).WithSessionSettings(func(ctx context.Context) map[string]string {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return map[string]string{"app.tenant_id": tenant, "app.role": "user"}
+		}
+		return nil
+	})
+	ctx := context.WithValue(context.TODO(), tenantKey{}, "42")
+
+	_, err = g.SmartQuery(ctx, Query{})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"BEGIN",
+		"SELECT set_config($1, $2, true)[app.role user]",
+		"SELECT set_config($1, $2, true)[app.tenant_id 42]",
+		"COMMIT",
+	}, log)
+
+	log = nil
+	err = g.UpdateField(ctx, User{Model: gorm.Model{ID: 1}}, "name", "x")
+	require.NoError(t, err)
+	require.Len(t, log, 4)
+
+	log = nil
+	err = Transaction(ctx, db, func(ctx context.Context) error {
+		_, err := g.Create(ctx, User{Name: "x"})
+		return err
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"BEGIN",
+		"SELECT set_config($1, $2, true)[app.role user]",
+		"SELECT set_config($1, $2, true)[app.tenant_id 42]",
+		"COMMIT",
+	}, log)
+
+	log = nil
+	_, err = g.SmartQuery(context.TODO(), Query{})
+	require.NoError(t, err)
+	require.Empty(t, log)
+}
+
+func TestQueryTags(t *testing.T) {
+	var log []string
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: recordingPool{&log}}), &gorm.Config{
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+	g := New[User](db).WithQueryTags(func(ctx context.Context) map[string]string {
+		return map[string]string{"service": "checkout", "endpoint": "Create*/Order"}
+	})
+
+	_, err = g.SmartQuery(context.TODO(), Query{Equal: map[string]any{"name": "x"}})
+	require.ErrorIs(t, err, errRecorded)
+	require.Equal(t, `SELECT * FROM "users" WHERE name = $1 AND "users"."deleted_at" IS NULL /* endpoint=Create__Order service=checkout */[x]`, log[0])
+
+	log = nil
+	require.NoError(t, g.UpdateField(context.TODO(), User{Model: gorm.Model{ID: 1}}, "name", "x"))
+	require.Len(t, log, 3)
+	require.Equal(t, "BEGIN", log[0])
+	require.Contains(t, log[1], `UPDATE "users" SET "name"=$1`)
+	require.Contains(t, log[1], "/* endpoint=Create__Order service=checkout */")
+	require.Equal(t, "COMMIT", log[2])
+
+	require.Equal(t, " /* trace=____OR_1_1_-- */", queryComment(map[string]string{"trace": "/*/ OR 1=1 --"}))
+	require.Equal(t, " /* _=a_b */", queryComment(map[string]string{"*": "a/b"}))
+}
@@ -0,0 +1,31 @@
+package crud
+
+import (
+	"context"
+
+	"gorm.io/gorm/clause"
+)
+
+// Sample returns up to n random Models matching q; q.OrderBy, full-text rank and distance ordering are ignored.
+// Rows are ordered randomly by database, which scans all matching rows; narrow q on large tables
+func (g GenericCRUD[T]) Sample(ctx context.Context, q Query, n int) ([]*T, error) {
+	q.OrderBy, q.Limit, q.Offset = nil, n, 0
+	g.cfg.DefaultOrder = ""
+	if q.FullText != nil {
+		ft := *q.FullText
+		ft.Rank = false
+		q.FullText = &ft
+	}
+	if q.Radius != nil {
+		r := *q.Radius
+		r.OrderByDistance = false
+		q.Radius = &r
+	}
+	stmt, err := g.smartStmt(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	var res []*T
+	err = stmt.Clauses(clause.OrderBy{Expression: clause.Expr{SQL: g.Dialect().Random()}}).Find(&res).Error
+	return res, err
+}
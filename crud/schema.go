@@ -0,0 +1,142 @@
+package crud
+
+import (
+	"sort"
+
+	"gorm.io/gorm/schema"
+)
+
+type (
+	// ModelSchema describes table of model for generic tooling, e.g. admin UIs and exporters
+	ModelSchema struct {
+		// Name is Go type name of model
+		Name  string
+		Table string
+		// PrimaryKey columns, prioritized one first
+		PrimaryKey []string
+		Columns    []ColumnSchema
+		// Indexes declared by gorm tags, Indexer and soft_unique tags, sorted by name
+		Indexes []IndexSchema
+	}
+
+	// ColumnSchema describes column of model
+	ColumnSchema struct {
+		Name string
+		// Field is Go field name
+		Field string
+		// DataType is gorm data type, e.g. "string" or "time"; SQLType is database type of column, e.g. "varchar(255)"
+		DataType string
+		SQLType  string
+		// GoType is Go type of field, e.g. "*time.Time"
+		GoType string
+		// Size is maximum length from gorm `size` tag; zero if not set
+		Size       int
+		PrimaryKey bool
+		NotNull    bool
+		Unique     bool
+		Indexed    bool
+		// Filterable, Sortable and OmitRead reflect crud struct tag options
+		Filterable bool
+		Sortable   bool
+		OmitRead   bool
+	}
+
+	// IndexSchema describes index of model
+	IndexSchema struct {
+		Name string
+		// Columns are column names or expressions
+		Columns []string
+		Unique  bool
+		Type    string
+		Where   string
+	}
+)
+
+// Column returns column by name
+func (s ModelSchema) Column(name string) (ColumnSchema, bool) {
+	for _, c := range s.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return ColumnSchema{}, false
+}
+
+// Schema returns metadata of T parsed from gorm schema, in field order
+func (g GenericCRUD[T]) Schema() (ModelSchema, error) {
+	s, err := g.schema()
+	if err != nil {
+		return ModelSchema{}, err
+	}
+	res := ModelSchema{Name: s.Name, Table: s.Table}
+	if s.PrioritizedPrimaryField != nil {
+		res.PrimaryKey = append(res.PrimaryKey, s.PrioritizedPrimaryField.DBName)
+	}
+	for _, f := range s.PrimaryFields {
+		if f != s.PrioritizedPrimaryField {
+			res.PrimaryKey = append(res.PrimaryKey, f.DBName)
+		}
+	}
+	indexed := map[string]bool{}
+	for _, idx := range s.ParseIndexes() {
+		columns := indexColumns(idx.Fields)
+		for _, c := range columns {
+			indexed[c] = true
+		}
+		res.Indexes = append(res.Indexes, IndexSchema{
+			Name: idx.Name, Columns: columns, Unique: idx.Class == "UNIQUE", Type: idx.Type, Where: idx.Where,
+		})
+	}
+	var v T
+	if indexer, ok := any(v).(Indexer); ok {
+		for _, idx := range indexer.Indexes() {
+			for _, c := range idx.Columns {
+				indexed[c] = true
+			}
+			res.Indexes = append(res.Indexes, IndexSchema(idx))
+		}
+	}
+	for _, idx := range g.softUniqueIndexes(s) {
+		for _, c := range idx.Columns {
+			indexed[c] = true
+		}
+		res.Indexes = append(res.Indexes, IndexSchema(idx))
+	}
+	sort.Slice(res.Indexes, func(i, j int) bool {
+		return res.Indexes[i].Name < res.Indexes[j].Name
+	})
+	// database type is reported by migrators of all supported dialects
+	typer, _ := g.db.Migrator().(interface{ DataTypeOf(*schema.Field) string })
+	for _, f := range s.Fields {
+		if f.DBName == "" {
+			continue
+		}
+		res.Columns = append(res.Columns, ColumnSchema{
+			Name:       f.DBName,
+			Field:      f.Name,
+			DataType:   string(f.DataType),
+			GoType:     f.FieldType.String(),
+			Size:       f.Size,
+			PrimaryKey: f.PrimaryKey,
+			NotNull:    f.NotNull || f.PrimaryKey,
+			Unique:     f.Unique,
+			Indexed:    indexed[f.DBName] || f.PrimaryKey || f.Unique,
+			Filterable: hasTag(f, TagFilterable),
+			Sortable:   hasTag(f, TagSortable),
+			OmitRead:   hasTag(f, TagOmitRead),
+		})
+		if typer != nil {
+			res.Columns[len(res.Columns)-1].SQLType = typer.DataTypeOf(f)
+		}
+	}
+	return res, nil
+}
+
+// indexColumns returns columns of index fields
+func indexColumns(fields []schema.IndexOption) []string {
+	res := make([]string, len(fields))
+	for i, f := range fields {
+		res[i] = f.DBName
+	}
+	return res
+}
@@ -0,0 +1,45 @@
+package crud
+
+import (
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// registrations add callbacks of GenericCRUD features; callbacks act only on sessions marked by their feature
+var registrations = []func(db *gorm.DB){
+	registerCircuitBreaker,
+	registerTimeout,
+	registerSessionSettings,
+	registerNotFoundInvalidation,
+	registerDryRun,
+	registerAfterFind,
+	registerComputed,
+	registerSoftUnique,
+	registerNPlusOne,
+}
+
+// setups are once per callbacks of db
+var setups sync.Map
+
+// firstCallback returns name of default callback running first in create, update or delete chain of db.
+// Callbacks are anchored to default ones by name: gorm sorts chains having callbacks before or after "*" unstably,
+// which reorders default callbacks of long chains. Callbacks after default ones run last in order of registration
+func firstCallback(db *gorm.DB, operation string) string {
+	if db.SkipDefaultTransaction {
+		return "gorm:before_" + operation
+	}
+	return "gorm:begin_transaction"
+}
+
+// Setup registers callbacks of GenericCRUD features on db once; callbacks are shared by sessions and transactions
+// of db. New, NewWithConfig, WithDB and Transaction call it. Registering callbacks isn't safe while db runs
+// statements, so create GenericCRUDs of db or call Setup before db is used by other goroutines
+func Setup(db *gorm.DB) {
+	once, _ := setups.LoadOrStore(db.Callback(), new(sync.Once))
+	once.(*sync.Once).Do(func() {
+		for _, register := range registrations {
+			register(db)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package crud
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestSetup(t *testing.T) {
+	db := benchDB(t, "setup")
+	tx := db.Session(&gorm.Session{})
+	g := New[User](db)
+	for _, name := range []string{
+		"crud:session_settings_begin",
+		"crud:circuit_breaker_allow",
+		"crud:timeout_start",
+		"crud:not_found_invalidate",
+		"crud:dry_run",
+		"crud:after_find",
+		"crud:computed",
+		"crud:soft_unique",
+		"crud:n_plus_one",
+	} {
+		require.True(t, tx.Callback().Query().Get(name) != nil || tx.Callback().Create().Get(name) != nil, name)
+	}
+
+	// run with -race: first statements of features don't register callbacks
+	features := g.WithSessionSettings(func(ctx context.Context) map[string]string { return nil }).
+		WithCircuitBreaker(&Breaker{Failures: 100, Cooldown: time.Second}).
+		WithNotFoundCache(time.Minute).
+		WithAfterFind(func(ctx context.Context, u *User) error { return nil })
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := features.GetByID(context.TODO(), User{Model: gorm.Model{ID: 1}})
+			require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		}()
+	}
+	wg.Wait()
+}
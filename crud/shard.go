@@ -0,0 +1,302 @@
+package crud
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ShardResolver returns database holding rows with shard key
+type ShardResolver func(key any) *gorm.DB
+
+// Sharded routes operations of GenericCRUD to shards by shard key
+type Sharded[T GORMModel] struct {
+	crud     GenericCRUD[T]
+	resolver ShardResolver
+	shards   []*gorm.DB
+	key      string
+}
+
+// NewSharded is a constructor; key is shard key column or field of T,
+// shards lists all databases for fan-out queries; crud provides configuration for every shard
+func NewSharded[T GORMModel](crud GenericCRUD[T], key string, resolver ShardResolver, shards ...*gorm.DB) Sharded[T] {
+	return Sharded[T]{crud: crud, resolver: resolver, shards: shards, key: key}
+}
+
+// Shard returns GenericCRUD of shard holding key
+func (s Sharded[T]) Shard(key any) GenericCRUD[T] {
+	return s.crud.WithDB(s.resolver(key))
+}
+
+// For returns GenericCRUD of shard selected by shard key of v
+func (s Sharded[T]) For(v T) (GenericCRUD[T], error) {
+	sch, err := s.crud.schema()
+	if err != nil {
+		return s.crud, err
+	}
+	field := sch.LookUpField(s.key)
+	if field == nil {
+		return s.crud, fmt.Errorf("%s has no shard key %q", sch.Name, s.key)
+	}
+	key, zero := field.ValueOf(context.Background(), reflect.ValueOf(v))
+	if zero {
+		return s.crud, fmt.Errorf("%s: zero shard key %q", sch.Name, s.key)
+	}
+	return s.Shard(key), nil
+}
+
+// Create Model in its shard
+func (s Sharded[T]) Create(ctx context.Context, v T, omit ...string) (*T, error) {
+	c, err := s.For(v)
+	if err != nil {
+		return nil, err
+	}
+	return c.Create(ctx, v, omit...)
+}
+
+// GetByID get Model by primary key from its shard; v MUST have non-zero primary and shard keys
+func (s Sharded[T]) GetByID(ctx context.Context, v T) (*T, error) {
+	c, err := s.For(v)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetByID(ctx, v)
+}
+
+// Update Model in its shard
+func (s Sharded[T]) Update(ctx context.Context, v T, omit ...string) error {
+	c, err := s.For(v)
+	if err != nil {
+		return err
+	}
+	return c.Update(ctx, v, omit...)
+}
+
+// Delete Model from its shard
+func (s Sharded[T]) Delete(ctx context.Context, v T) error {
+	c, err := s.For(v)
+	if err != nil {
+		return err
+	}
+	return c.Delete(ctx, v)
+}
+
+// SmartQuery runs q on every shard concurrently and merges results ordered by q.OrderBy and primary key;
+// every shard returns up to Limit+Offset rows, Limit and Offset apply to merged rows. Merging compares values in Go,
+// so Collation is ignored, and orders by columns not of T, e.g. rank of Window, are errors
+func (s Sharded[T]) SmartQuery(ctx context.Context, q Query) ([]*T, error) {
+	sch, err := s.crud.schema()
+	if err != nil {
+		return nil, err
+	}
+	order, err := shardOrder(sch, q.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	shardQuery := q
+	shardQuery.OrderBy, shardQuery.Offset = order, 0
+	if q.Limit > 0 {
+		shardQuery.Limit = q.Limit + q.Offset
+	}
+	var (
+		wg      sync.WaitGroup
+		results = make([][]*T, len(s.shards))
+		errs    = make([]error, len(s.shards))
+	)
+	for i, db := range s.shards {
+		wg.Add(1)
+		go func(i int, db *gorm.DB) {
+			defer wg.Done()
+			results[i], errs[i] = s.crud.WithDB(db).SmartQuery(ctx, shardQuery)
+		}(i, db)
+	}
+	wg.Wait()
+	var res []*T
+	for i := range s.shards {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("shard %d: %w", i, errs[i])
+		}
+		res = append(res, results[i]...)
+	}
+	nullsFirst := s.crud.Dialect() != Postgres
+	sort.SliceStable(res, func(i, j int) bool {
+		a, b := reflect.ValueOf(res[i]).Elem(), reflect.ValueOf(res[j]).Elem()
+		for _, o := range order {
+			f := sch.LookUpField(o.Column)
+			x, _ := f.ValueOf(ctx, a)
+			y, _ := f.ValueOf(ctx, b)
+			if c := compareOrdered(o, x, y, nullsFirst); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	if q.Offset >= len(res) {
+		return nil, nil
+	}
+	res = res[q.Offset:]
+	if q.Limit > 0 && len(res) > q.Limit {
+		res = res[:q.Limit]
+	}
+	return res, nil
+}
+
+// shardOrder returns order validated for merging, with primary key appended unless ordered by it
+func shardOrder(s *schema.Schema, order []OrderClause) ([]OrderClause, error) {
+	if s.PrioritizedPrimaryField == nil {
+		return nil, fmt.Errorf("%s has no primary key", s.Name)
+	}
+	pk := s.PrioritizedPrimaryField.DBName
+	dir, hasPK := ASC, false
+	for i, o := range order {
+		if err := o.validate(); err != nil {
+			return nil, err
+		}
+		if f := s.LookUpField(o.Column); f == nil || f.DBName == "" {
+			return nil, fmt.Errorf("%w: can't merge shards ordered by %q", InvalidFilterError, o.Column)
+		}
+		hasPK = hasPK || o.Column == pk && o.Func == ""
+		if i == 0 || dir == DESC {
+			dir = o.direction()
+		}
+	}
+	if hasPK {
+		return order, nil
+	}
+	return append(append([]OrderClause(nil), order...), OrderClause{Column: pk, Direction: dir}), nil
+}
+
+// compareOrdered compares column values x and y by o: negative if x goes first; NULLs are placed by o, first
+// or last by default
+func compareOrdered(o OrderClause, x, y any, nullsFirst bool) int {
+	x, y = orderValue(o.Func, x), orderValue(o.Func, y)
+	if x == nil || y == nil {
+		switch {
+		case x == nil && y == nil:
+			return 0
+		case o.NullsFirst || nullsFirst && !o.NullsLast:
+			if x == nil {
+				return -1
+			}
+			return 1
+		case x == nil:
+			return 1
+		default:
+			return -1
+		}
+	}
+	c := compareValues(x, y)
+	if o.direction() == DESC {
+		c = -c
+	}
+	return c
+}
+
+// orderValue returns value of field v ordered by function fn: NULL if v is nil pointer or invalid driver.Valuer
+func orderValue(fn string, v any) any {
+	if valuer, ok := v.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nil
+		}
+		v, _ = valuer.Value()
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	v = rv.Interface()
+	switch strings.ToUpper(fn) {
+	case "LOWER":
+		return strings.ToLower(fmt.Sprint(v))
+	case "UPPER":
+		return strings.ToUpper(fmt.Sprint(v))
+	case "LENGTH":
+		return int64(len([]rune(fmt.Sprint(v))))
+	case "ABS":
+		if f, ok := number(rv); ok {
+			return math.Abs(f)
+		}
+	}
+	return v
+}
+
+// compareValues compares non-NULL values of the same column
+func compareValues(x, y any) int {
+	switch a := x.(type) {
+	case time.Time:
+		if b, ok := y.(time.Time); ok {
+			return compare(a.UnixNano(), b.UnixNano())
+		}
+	case bool:
+		if b, ok := y.(bool); ok {
+			return compare(boolInt(a), boolInt(b))
+		}
+	case []byte:
+		if b, ok := y.([]byte); ok {
+			return bytes.Compare(a, b)
+		}
+	case string:
+		if b, ok := y.(string); ok {
+			return strings.Compare(a, b)
+		}
+	}
+	a, b := reflect.ValueOf(x), reflect.ValueOf(y)
+	switch {
+	case a.CanInt() && b.CanInt():
+		return compare(a.Int(), b.Int())
+	case a.CanUint() && b.CanUint():
+		return compare(a.Uint(), b.Uint())
+	}
+	if a, ok := number(a); ok {
+		if b, ok := number(b); ok {
+			return compare(a, b)
+		}
+	}
+	return strings.Compare(fmt.Sprint(x), fmt.Sprint(y))
+}
+
+func compare[V int64 | uint64 | float64](a, b V) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// number returns numeric value of rv
+func number(rv reflect.Value) (float64, bool) {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
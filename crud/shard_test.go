@@ -0,0 +1,62 @@
+package crud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestSharded(t *testing.T) {
+	shards := []*gorm.DB{dryRunDB(t), dryRunDB(t)}
+	s := NewSharded[User](New[User](shards[0]), "name", func(key any) *gorm.DB {
+		return shards[len(key.(string))%2]
+	}, shards...)
+
+	c, err := s.For(User{Name: "a"})
+	require.NoError(t, err)
+	require.Same(t, shards[1], c.db)
+	c, err = s.For(User{Name: "ab"})
+	require.NoError(t, err)
+	require.Same(t, shards[0], c.db)
+	_, err = s.For(User{})
+	require.Error(t, err)
+
+	res, err := s.SmartQuery(context.TODO(), Query{})
+	require.NoError(t, err)
+	require.Empty(t, res)
+}
+
+func TestShardedSmartQueryMerge(t *testing.T) {
+	ctx := context.TODO()
+	shards := []*gorm.DB{benchDB(t, "shard_merge0"), benchDB(t, "shard_merge1")}
+	s := NewSharded[User](New[User](shards[0]), "name", func(key any) *gorm.DB {
+		return shards[len(key.(string))%2]
+	}, shards...)
+	for _, name := range []string{"bb", "d", "ff", "a", "cc", "e", "a"} {
+		_, err := s.Create(ctx, User{Name: name})
+		require.NoError(t, err)
+	}
+	names := func(q Query) []string {
+		res, err := s.SmartQuery(ctx, q)
+		require.NoError(t, err)
+		var names []string
+		for _, u := range res {
+			names = append(names, u.Name)
+		}
+		return names
+	}
+	require.Equal(t, []string{"a", "a", "bb", "cc", "d", "e", "ff"}, names(Query{OrderBy: []OrderClause{{Column: "name"}}}))
+	require.Equal(t, []string{"bb", "cc", "d"}, names(Query{OrderBy: []OrderClause{{Column: "name"}}, Limit: 3, Offset: 2}))
+	require.Equal(t, []string{"e", "d", "cc"}, names(Query{OrderBy: []OrderClause{{Column: "name", Direction: DESC}}, Limit: 3, Offset: 1}))
+	require.Equal(t, []string{"e", "a"}, names(Query{OrderBy: []OrderClause{{Column: "name", Func: "length"}}, Limit: 2, Offset: 2}))
+	require.Empty(t, names(Query{Limit: 3, Offset: 7}))
+
+	res, err := s.SmartQuery(ctx, Query{Equal: map[string]any{"name": "a"}})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Less(t, res[0].ID, res[1].ID)
+	_, err = s.SmartQuery(ctx, Query{OrderBy: []OrderClause{{Column: "window_rank"}}})
+	require.ErrorIs(t, err, InvalidFilterError)
+}
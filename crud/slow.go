@@ -0,0 +1,70 @@
+package crud
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// SlowQueryInfo describes statement exceeding Config.SlowQueryThreshold
+type SlowQueryInfo struct {
+	SQL      string
+	Rows     int64
+	Duration time.Duration
+	// Operation is SQL verb, e.g. SELECT or INSERT
+	Operation string
+	// Model is Go type name of T
+	Model string
+	Err   error
+}
+
+// WithSlowQueryThreshold returns copy of g calling fn for every statement running longer than d
+func (g GenericCRUD[T]) WithSlowQueryThreshold(d time.Duration, fn func(SlowQueryInfo)) GenericCRUD[T] {
+	g.cfg.SlowQueryThreshold, g.cfg.OnSlowQuery = d, fn
+	return g
+}
+
+// slowQueryLogger wraps logger to report slow statements
+type slowQueryLogger struct {
+	logger.Interface
+	threshold time.Duration
+	model     string
+	fn        func(SlowQueryInfo)
+}
+
+func (g GenericCRUD[T]) slowLogger(l logger.Interface) logger.Interface {
+	return slowQueryLogger{
+		Interface: l,
+		threshold: g.cfg.SlowQueryThreshold,
+		model:     reflect.TypeOf((*T)(nil)).Elem().Name(),
+		fn:        g.cfg.OnSlowQuery,
+	}
+}
+
+// LogMode keeps slow query reporting on logger with changed level
+func (l slowQueryLogger) LogMode(level logger.LogLevel) logger.Interface {
+	l.Interface = l.Interface.LogMode(level)
+	return l
+}
+
+// Trace implements logger.Interface
+func (l slowQueryLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	l.Interface.Trace(ctx, begin, fc, err)
+	elapsed := time.Since(begin)
+	if elapsed < l.threshold {
+		return
+	}
+	sql, rows := fc()
+	operation, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	l.fn(SlowQueryInfo{
+		SQL:       sql,
+		Rows:      rows,
+		Duration:  elapsed,
+		Operation: strings.ToUpper(operation),
+		Model:     l.model,
+		Err:       err,
+	})
+}
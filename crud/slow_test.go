@@ -0,0 +1,27 @@
+package crud
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowQuery(t *testing.T) {
+	var infos []SlowQueryInfo
+	g := New[User](dryRunDB(t)).WithSlowQueryThreshold(0, func(info SlowQueryInfo) {
+		infos = append(infos, info)
+	})
+	_, err := g.SmartQuery(context.TODO(), Query{Equal: map[string]any{"name": "slow"}})
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	require.Equal(t, "SELECT", infos[0].Operation)
+	require.Equal(t, "User", infos[0].Model)
+	require.Contains(t, infos[0].SQL, `FROM "users" WHERE name = 'slow'`)
+
+	g = g.WithSlowQueryThreshold(time.Hour, g.cfg.OnSlowQuery)
+	_, err = g.Create(context.TODO(), User{Name: "fast"})
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+}
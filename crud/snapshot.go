@@ -0,0 +1,227 @@
+package crud
+
+import (
+	"bufio"
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// SnapshotVersion is version of Snapshot format
+const SnapshotVersion = 1
+
+// RestoreMode configures handling of rows already present on Restore
+type RestoreMode uint8
+
+const (
+	// RestoreInsert fails on conflicting rows
+	RestoreInsert RestoreMode = iota
+	// RestoreSkipExisting keeps conflicting rows as they are
+	RestoreSkipExisting
+	// RestoreUpsert overwrites conflicting rows with snapshot
+	RestoreUpsert
+)
+
+// snapshotHeader is first line of snapshot
+type snapshotHeader struct {
+	Version int      `json:"version"`
+	Model   string   `json:"model"`
+	Table   string   `json:"table"`
+	Columns []string `json:"columns"`
+}
+
+/*
+Snapshot writes rows matching q, soft-deleted ones included, in primary key order as JSON Lines: header with model
+and columns, then one array of column values per row:
+
+	{"version":1,"model":"User","table":"users","columns":["id","created_at","updated_at","deleted_at","name","age"]}
+	[1,"2024-01-02T03:04:05.000000006Z","2024-01-02T03:04:05.000000006Z",null,"ann",30]
+
+Times are RFC 3339 in UTC, bytes are base64; OrderBy, Select and Preload of q are ignored. See Restore
+*/
+func (g GenericCRUD[T]) Snapshot(ctx context.Context, q Query, w io.Writer) error {
+	s, err := g.schema()
+	if err != nil {
+		return err
+	}
+	if s.PrioritizedPrimaryField == nil {
+		return fmt.Errorf("%s has no primary key", s.Name)
+	}
+	fields := snapshotFields(s)
+	header := snapshotHeader{Version: SnapshotVersion, Model: s.Name, Table: s.Table}
+	for _, f := range fields {
+		header.Columns = append(header.Columns, f.DBName)
+	}
+	q.Select, q.Preload = nil, nil
+	q.OrderBy = []OrderClause{{Column: s.PrioritizedPrimaryField.DBName, Direction: ASC}}
+	stmt, err := g.uncapped().smartStmt(ctx, q)
+	if err != nil {
+		return err
+	}
+	rows, err := stmt.Unscoped().Model(new(T)).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	if err = enc.Encode(header); err != nil {
+		return err
+	}
+	values := make([]any, len(fields))
+	for rows.Next() {
+		var v T
+		if err = stmt.ScanRows(rows, &v); err != nil {
+			return err
+		}
+		rv := reflect.ValueOf(&v).Elem()
+		for i, f := range fields {
+			if values[i], err = snapshotValue(ctx, f, rv); err != nil {
+				return fmt.Errorf("column %s: %w", f.DBName, err)
+			}
+		}
+		if err = enc.Encode(values); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// snapshotFields returns stored fields of s
+func snapshotFields(s *schema.Schema) []*schema.Field {
+	var res []*schema.Field
+	for _, f := range s.Fields {
+		if f.DBName != "" && f.Creatable {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+// snapshotValue returns JSON value of field f of rv
+func snapshotValue(ctx context.Context, f *schema.Field, rv reflect.Value) (any, error) {
+	v, _ := f.ValueOf(ctx, rv)
+	if valuer, ok := v.(driver.Valuer); ok {
+		var err error
+		if v, err = valuer.Value(); err != nil {
+			return nil, err
+		}
+	}
+	switch t := v.(type) {
+	case time.Time:
+		return t.UTC().Format(time.RFC3339Nano), nil
+	case *time.Time:
+		if t == nil {
+			return nil, nil
+		}
+		return t.UTC().Format(time.RFC3339Nano), nil
+	}
+	return v, nil
+}
+
+// Restore inserts rows of Snapshot of T within transaction, in batches of 500, keeping primary keys; hooks and
+// associations are skipped. mode handles rows already present. Returns number of rows read
+func (g GenericCRUD[T]) Restore(ctx context.Context, r io.Reader, mode RestoreMode) (int, error) {
+	s, err := g.schema()
+	if err != nil {
+		return 0, err
+	}
+	dec := json.NewDecoder(bufio.NewReader(r))
+	dec.UseNumber()
+	var header snapshotHeader
+	if err = dec.Decode(&header); err != nil {
+		return 0, fmt.Errorf("snapshot header: %w", err)
+	}
+	if header.Version != SnapshotVersion || header.Model != s.Name {
+		return 0, fmt.Errorf("snapshot of %s version %d can't be restored to %s", header.Model, header.Version, s.Name)
+	}
+	fields := make([]*schema.Field, len(header.Columns))
+	for i, c := range header.Columns {
+		if fields[i] = s.LookUpField(c); fields[i] == nil || fields[i].DBName == "" {
+			return 0, fmt.Errorf("%w: unknown column %q", InvalidFilterError, c)
+		}
+	}
+	var onConflict clause.Expression
+	switch mode {
+	case RestoreInsert:
+	case RestoreSkipExisting:
+		onConflict = clause.OnConflict{DoNothing: true}
+	case RestoreUpsert:
+		onConflict = copyConflict(s, nil, nil)
+	default:
+		return 0, fmt.Errorf("unknown restore mode %d", mode)
+	}
+	n := 0
+	err = g.RunInTransaction(ctx, func(ctx context.Context, tx GenericCRUD[T]) error {
+		const batchSize = 500
+		batch := make([]T, 0, batchSize)
+		for {
+			var values []any
+			if err := dec.Decode(&values); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return fmt.Errorf("snapshot row %d: %w", n+1, err)
+			}
+			n++
+			if len(values) != len(fields) {
+				return fmt.Errorf("snapshot row %d: expected %d values, got %d", n, len(fields), len(values))
+			}
+			var v T
+			rv := reflect.ValueOf(&v).Elem()
+			for i, f := range fields {
+				if err := restoreValue(ctx, f, rv, values[i]); err != nil {
+					return fmt.Errorf("snapshot row %d, column %s: %w", n, f.DBName, err)
+				}
+			}
+			if batch = append(batch, v); len(batch) == batchSize {
+				if err := insertCopies(tx.session(ctx), batch, onConflict); err != nil {
+					return err
+				}
+				batch = batch[:0]
+			}
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		return insertCopies(tx.session(ctx), batch, onConflict)
+	})
+	return n, err
+}
+
+// restoreValue sets field f of rv to JSON value v of snapshot
+func restoreValue(ctx context.Context, f *schema.Field, rv reflect.Value, v any) error {
+	switch value := v.(type) {
+	case nil:
+		return f.Set(ctx, rv, nil)
+	case json.Number:
+		v = value.String()
+	case string:
+		switch f.DataType {
+		case schema.Time:
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return err
+			}
+			v = t
+		case schema.Bytes:
+			b, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return err
+			}
+			v = b
+		}
+	}
+	return f.Set(ctx, rv, v)
+}
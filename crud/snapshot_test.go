@@ -0,0 +1,76 @@
+package crud
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.TODO()
+	src, dst := New[User](benchDB(t, "snapshot_src")), New[User](benchDB(t, "snapshot_dst"))
+	for _, u := range []User{{Name: "ann", Age: sql.NullInt16{Int16: 30, Valid: true}}, {Name: "bob"}, {Name: "eve"}} {
+		_, err := src.Create(ctx, u)
+		require.NoError(t, err)
+	}
+	require.NoError(t, src.Delete(ctx, User{Model: gorm.Model{ID: 3}}))
+
+	var buf bytes.Buffer
+	require.NoError(t, src.Snapshot(ctx, Query{}, &buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, `{"version":1,"model":"User","table":"users","columns":["id","created_at","updated_at","deleted_at","name","age"]}`, lines[0])
+	require.Contains(t, lines[1], `"ann",30]`)
+	require.Contains(t, lines[2], `,null,"bob",null]`)
+
+	n, err := dst.Restore(ctx, bytes.NewReader(buf.Bytes()), RestoreInsert)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	a, err := src.Digest(ctx, Query{})
+	require.NoError(t, err)
+	b, err := dst.Digest(ctx, Query{})
+	require.NoError(t, err)
+	require.Equal(t, a, b)
+	var count int64
+	require.NoError(t, dst.db.Unscoped().Model(&User{}).Where("deleted_at IS NOT NULL").Count(&count).Error)
+	require.EqualValues(t, 1, count)
+
+	_, err = dst.Restore(ctx, bytes.NewReader(buf.Bytes()), RestoreInsert)
+	require.Error(t, err)
+	require.NoError(t, dst.UpdateField(ctx, User{Model: gorm.Model{ID: 1}}, "name", "changed"))
+	_, err = dst.Restore(ctx, bytes.NewReader(buf.Bytes()), RestoreSkipExisting)
+	require.NoError(t, err)
+	got, err := dst.GetByID(ctx, User{Model: gorm.Model{ID: 1}})
+	require.NoError(t, err)
+	require.Equal(t, "changed", got.Name)
+	_, err = dst.Restore(ctx, bytes.NewReader(buf.Bytes()), RestoreUpsert)
+	require.NoError(t, err)
+	b, err = dst.Digest(ctx, Query{})
+	require.NoError(t, err)
+	require.Equal(t, a, b)
+
+	_, err = New[Pet](benchDB(t, "snapshot_pets")).Restore(ctx, bytes.NewReader(buf.Bytes()), RestoreInsert)
+	require.ErrorContains(t, err, "snapshot of User version 1 can't be restored to Pet")
+}
+
+func TestRestoreUpsertSQL(t *testing.T) {
+	ctx := context.TODO()
+	src := New[User](benchDB(t, "snapshot_upsert"))
+	_, err := src.Create(ctx, User{Name: "ann"})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, src.Snapshot(ctx, Query{}, &buf))
+
+	stmts, err := New[User](dryRunDB(t)).DryRun(ctx, func(ctx context.Context, g GenericCRUD[User]) error {
+		_, err := g.Restore(ctx, &buf, RestoreUpsert)
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, stmts, 1)
+	require.Contains(t, stmts[0].SQL, `ON CONFLICT ("id") DO UPDATE SET "created_at"="excluded"."created_at"`)
+}
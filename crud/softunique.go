@@ -0,0 +1,205 @@
+package crud
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type (
+	// SoftUnique is constraint making Columns unique among rows not soft-deleted, declared by crud:"soft_unique" tags
+	SoftUnique struct {
+		Name    string
+		Columns []string
+	}
+
+	// UniqueConflictError is returned when create or update violates SoftUnique constraint
+	UniqueConflictError struct {
+		Constraint string
+		Columns    []string
+		// Values are conflicting values, if checked before insert
+		Values []any
+		// Err is database error, nil if conflict is found by existence check
+		Err error
+	}
+)
+
+// Error implements error
+func (e UniqueConflictError) Error() string {
+	if e.Values == nil {
+		return fmt.Sprintf("unique constraint %s of %s violated", e.Constraint, strings.Join(e.Columns, ", "))
+	}
+	return fmt.Sprintf("unique constraint %s of %s violated by %v", e.Constraint, strings.Join(e.Columns, ", "), e.Values)
+}
+
+// Unwrap returns database error
+func (e UniqueConflictError) Unwrap() error {
+	return e.Err
+}
+
+// SoftUniques returns SoftUnique constraints of T: one per column tagged soft_unique and one per name
+// of columns tagged soft_unique:<name>, by order of first column
+func (g GenericCRUD[T]) SoftUniques() ([]SoftUnique, error) {
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	return softUniques(s), nil
+}
+
+func softUniques(s *schema.Schema) []SoftUnique {
+	var res []SoftUnique
+	named := make(map[string]int)
+	for _, f := range s.Fields {
+		name, ok := tagOption(f, TagSoftUnique)
+		if !ok || f.DBName == "" {
+			continue
+		}
+		if name == "" {
+			res = append(res, SoftUnique{Name: "uidx_" + s.Table + "_" + f.DBName, Columns: []string{f.DBName}})
+			continue
+		}
+		if i, ok := named[name]; ok {
+			res[i].Columns = append(res[i].Columns, f.DBName)
+			continue
+		}
+		named[name] = len(res)
+		res = append(res, SoftUnique{Name: name, Columns: []string{f.DBName}})
+	}
+	return res
+}
+
+// softUniqueIndexes returns unique indexes of SoftUnique constraints; they are partial if T is soft-deletable
+func (g GenericCRUD[T]) softUniqueIndexes(s *schema.Schema) []Index {
+	deleted := deletedColumn(s)
+	var res []Index
+	for _, c := range softUniques(s) {
+		idx := Index{Name: c.Name, Columns: c.Columns, Unique: true}
+		if deleted != "" {
+			idx.Where = deleted + " IS NULL"
+		}
+		res = append(res, idx)
+	}
+	return res
+}
+
+// deletedColumn returns soft-delete column of s, if any
+func deletedColumn(s *schema.Schema) string {
+	for _, f := range s.Fields {
+		if f.DBName != "" && f.FieldType == reflect.TypeOf(gorm.DeletedAt{}) {
+			return f.DBName
+		}
+	}
+	return ""
+}
+
+// partialIndexes reports whether d supports partial indexes; otherwise SoftUnique is checked before insert
+func partialIndexes(d Dialect) bool {
+	return d == Postgres || d == SQLite
+}
+
+const softUniqueKey = "crud:soft_unique"
+
+// softUnique of statement: constraints to translate errors of, and check run before insert if set
+type softUnique struct {
+	constraints []SoftUnique
+	check       func(db *gorm.DB) error
+}
+
+// withSoftUnique marks db with SoftUnique constraints of T for callbacks; check enables
+// existence check before insert
+func (g GenericCRUD[T]) withSoftUnique(db *gorm.DB, constraints []SoftUnique, check bool) *gorm.DB {
+	u := softUnique{constraints: constraints}
+	if check {
+		u.check = func(db *gorm.DB) error {
+			s := db.Statement
+			return eachModel(s.ReflectValue, func(v *T) error {
+				for _, c := range constraints {
+					if err := checkSoftUnique[T](db, c, reflect.ValueOf(v).Elem()); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+	}
+	return db.Set(softUniqueKey, u)
+}
+
+// checkSoftUnique returns UniqueConflictError if row not soft-deleted has values of c in rv; NULL values don't conflict
+func checkSoftUnique[T GORMModel](db *gorm.DB, c SoftUnique, rv reflect.Value) error {
+	s := db.Statement
+	values := make([]any, len(c.Columns))
+	conds := make([]clause.Expression, len(c.Columns))
+	for i, column := range c.Columns {
+		value, _ := s.Schema.FieldsByDBName[column].ValueOf(s.Context, rv)
+		if v := reflect.ValueOf(value); !v.IsValid() || v.Kind() == reflect.Pointer && v.IsNil() {
+			return nil
+		}
+		values[i] = value
+		conds[i] = clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: value}
+	}
+	var n int64
+	err := db.Session(&gorm.Session{NewDB: true}).Table(s.Table).Model(new(T)).Where(clause.And(conds...)).Limit(1).Count(&n).Error
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return UniqueConflictError{Constraint: c.Name, Columns: c.Columns, Values: values}
+	}
+	return nil
+}
+
+// registerSoftUnique adds callbacks to db: check before insert, error translation after insert and update.
+// See Setup
+func registerSoftUnique(db *gorm.DB) {
+	const name = "crud:soft_unique"
+	cb := db.Callback()
+	if cb.Create().Get(name) != nil {
+		return
+	}
+	_ = cb.Create().Before("gorm:create").Register(name, checkSoftUniques)
+	_ = cb.Create().After("gorm:create").Register(name+"_error", softUniqueError)
+	_ = cb.Update().After("gorm:update").Register(name+"_error", softUniqueError)
+}
+
+// checkSoftUniques runs check set by withSoftUnique
+func checkSoftUniques(db *gorm.DB) {
+	v, ok := db.Get(softUniqueKey)
+	if !ok || db.Error != nil || db.DryRun || v.(softUnique).check == nil {
+		return
+	}
+	if err := v.(softUnique).check(db); err != nil {
+		_ = db.AddError(err)
+	}
+}
+
+// softUniqueError replaces database error violating SoftUnique index with UniqueConflictError
+func softUniqueError(db *gorm.DB) {
+	v, ok := db.Get(softUniqueKey)
+	if !ok || db.Error == nil {
+		return
+	}
+	var conflict UniqueConflictError
+	if errors.As(db.Error, &conflict) {
+		return
+	}
+	msg := db.Error.Error()
+	for _, c := range v.(softUnique).constraints {
+		qualified := make([]string, len(c.Columns))
+		for i, column := range c.Columns {
+			qualified[i] = db.Statement.Table + "." + column
+		}
+		// Postgres quotes constraint name, MySQL names key, SQLite lists columns
+		if strings.Contains(msg, `"`+c.Name+`"`) || strings.Contains(msg, c.Name+"'") ||
+			strings.Contains(msg, "UNIQUE constraint failed: "+strings.Join(qualified, ", ")) {
+			db.Error = UniqueConflictError{Constraint: c.Name, Columns: c.Columns, Err: db.Error}
+			return
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+/*
+Spec is condition on T composed of typed predicates and And, Or and Not, an alternative to string-keyed maps of Query:
+
+	name, age := crud.Field[User, string]("name"), crud.Field[User, int]("age")
+	adults, err := users.QuerySpec(ctx, crud.And(name.Like("a%"), crud.Or(age.Gte(18), age.IsNull())))
+
+Value types are checked by compiler; columns, by Go field or column name, are checked against schema of T
+when spec is built, failing with InvalidFilterError
+*/
+type Spec[T GORMModel] interface {
+	// Expression returns condition on table of T with schema s
+	Expression(s *schema.Schema) (clause.Expression, error)
+	spec(T)
+}
+
+// SpecFunc is Spec built by func, e.g. custom predicate
+type SpecFunc[T GORMModel] func(s *schema.Schema) (clause.Expression, error)
+
+// Expression implements Spec
+func (f SpecFunc[T]) Expression(s *schema.Schema) (clause.Expression, error) {
+	return f(s)
+}
+
+func (SpecFunc[T]) spec(T) {}
+
+// And matches rows matching every spec; no specs match every row
+func And[T GORMModel](specs ...Spec[T]) Spec[T] {
+	return SpecFunc[T](func(s *schema.Schema) (clause.Expression, error) {
+		exprs, err := specExprs(s, specs)
+		if err != nil || len(exprs) == 0 {
+			return nil, err
+		}
+		return clause.And(exprs...), nil
+	})
+}
+
+// Or matches rows matching any of specs; no specs match no rows
+func Or[T GORMModel](specs ...Spec[T]) Spec[T] {
+	return SpecFunc[T](func(s *schema.Schema) (clause.Expression, error) {
+		exprs, err := specExprs(s, specs)
+		if err != nil {
+			return nil, err
+		}
+		if len(exprs) < len(specs) {
+			// some spec matches every row
+			return nil, nil
+		}
+		if len(exprs) == 0 {
+			return clause.Expr{SQL: "1 <> 1"}, nil
+		}
+		return clause.Or(exprs...), nil
+	})
+}
+
+// Not matches rows not matching spec
+func Not[T GORMModel](spec Spec[T]) Spec[T] {
+	return SpecFunc[T](func(s *schema.Schema) (clause.Expression, error) {
+		expr, err := spec.Expression(s)
+		if err != nil {
+			return nil, err
+		}
+		if expr == nil {
+			return clause.Expr{SQL: "1 <> 1"}, nil
+		}
+		return clause.Not(expr), nil
+	})
+}
+
+// specExprs builds specs, skipping ones matching every row
+func specExprs[T GORMModel](s *schema.Schema, specs []Spec[T]) ([]clause.Expression, error) {
+	exprs := make([]clause.Expression, 0, len(specs))
+	for _, spec := range specs {
+		expr, err := spec.Expression(s)
+		if err != nil {
+			return nil, err
+		}
+		if expr != nil {
+			exprs = append(exprs, expr)
+		}
+	}
+	return exprs, nil
+}
+
+// FieldOf builds predicates on column of T with values of type V; see Field
+type FieldOf[T GORMModel, V any] struct {
+	// Name is Go field or column name
+	Name string
+}
+
+// Field returns predicate builder of T's field or column name
+func Field[T GORMModel, V any](name string) FieldOf[T, V] {
+	return FieldOf[T, V]{Name: name}
+}
+
+// Eq matches column equal to v
+func (f FieldOf[T, V]) Eq(v V) Spec[T] {
+	return f.predicate(func(c clause.Column) clause.Expression { return clause.Eq{Column: c, Value: v} })
+}
+
+// Ne matches column not equal to v
+func (f FieldOf[T, V]) Ne(v V) Spec[T] {
+	return f.predicate(func(c clause.Column) clause.Expression { return clause.Neq{Column: c, Value: v} })
+}
+
+// Gt matches column greater than v
+func (f FieldOf[T, V]) Gt(v V) Spec[T] {
+	return f.predicate(func(c clause.Column) clause.Expression { return clause.Gt{Column: c, Value: v} })
+}
+
+// Gte matches column greater than or equal to v
+func (f FieldOf[T, V]) Gte(v V) Spec[T] {
+	return f.predicate(func(c clause.Column) clause.Expression { return clause.Gte{Column: c, Value: v} })
+}
+
+// Lt matches column less than v
+func (f FieldOf[T, V]) Lt(v V) Spec[T] {
+	return f.predicate(func(c clause.Column) clause.Expression { return clause.Lt{Column: c, Value: v} })
+}
+
+// Lte matches column less than or equal to v
+func (f FieldOf[T, V]) Lte(v V) Spec[T] {
+	return f.predicate(func(c clause.Column) clause.Expression { return clause.Lte{Column: c, Value: v} })
+}
+
+// Between matches column within from and to, inclusive
+func (f FieldOf[T, V]) Between(from, to V) Spec[T] {
+	return f.predicate(func(c clause.Column) clause.Expression {
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []any{c, from, to}}
+	})
+}
+
+// In matches column equal to any of values; no values match no rows
+func (f FieldOf[T, V]) In(values ...V) Spec[T] {
+	return f.predicate(func(c clause.Column) clause.Expression {
+		vs := make([]any, len(values))
+		for i, v := range values {
+			vs[i] = v
+		}
+		return clause.IN{Column: c, Values: vs}
+	})
+}
+
+// Like matches column by LIKE pattern; wildcards are not escaped
+func (f FieldOf[T, V]) Like(pattern string) Spec[T] {
+	return f.predicate(func(c clause.Column) clause.Expression { return clause.Like{Column: c, Value: pattern} })
+}
+
+// IsNull matches NULL column
+func (f FieldOf[T, V]) IsNull() Spec[T] {
+	return f.predicate(func(c clause.Column) clause.Expression { return clause.Eq{Column: c, Value: nil} })
+}
+
+// NotNull matches non-NULL column
+func (f FieldOf[T, V]) NotNull() Spec[T] {
+	return f.predicate(func(c clause.Column) clause.Expression { return clause.Neq{Column: c, Value: nil} })
+}
+
+// predicate returns Spec of expression on resolved column
+func (f FieldOf[T, V]) predicate(expr func(c clause.Column) clause.Expression) Spec[T] {
+	return SpecFunc[T](func(s *schema.Schema) (clause.Expression, error) {
+		field := s.LookUpField(f.Name)
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("%w: unknown column %q", InvalidFilterError, f.Name)
+		}
+		return expr(clause.Column{Table: clause.CurrentTable, Name: field.DBName}), nil
+	})
+}
+
+// QuerySpec returns rows matching spec, ordered by default order
+func (g GenericCRUD[T]) QuerySpec(ctx context.Context, spec Spec[T]) ([]*T, error) {
+	var res []*T
+	stmt, err := g.specStmt(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	if err = g.ordered(g.limit(stmt)).Omit(g.readOmits(ctx, g.cfg.Omit)...).Find(&res).Error; err == nil {
+		err = g.checkRows(len(res), 0)
+	}
+	return res, err
+}
+
+// CountSpec counts rows matching spec
+func (g GenericCRUD[T]) CountSpec(ctx context.Context, spec Spec[T]) (int64, error) {
+	var count int64
+	stmt, err := g.specStmt(ctx, spec)
+	if err != nil {
+		return 0, err
+	}
+	return count, stmt.Model(new(T)).Count(&count).Error
+}
+
+// specStmt returns session filtered by spec
+func (g GenericCRUD[T]) specStmt(ctx context.Context, spec Spec[T]) (*gorm.DB, error) {
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	expr, err := spec.Expression(s)
+	if err != nil {
+		return nil, err
+	}
+	stmt := g.session(ctx)
+	if expr != nil {
+		stmt = stmt.Where(clause.And(expr))
+	}
+	return stmt, nil
+}
@@ -0,0 +1,45 @@
+package crud
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec(t *testing.T) {
+	users := New[User](benchDB(t, "spec"))
+	ctx := context.TODO()
+	for i, name := range []string{"ann", "bob", "alice"} {
+		_, err := users.Create(ctx, User{Name: name, Age: sql.NullInt16{Int16: int16(20 + i*10), Valid: i > 0}})
+		require.NoError(t, err)
+	}
+	name, age := Field[User, string]("Name"), Field[User, int]("age")
+
+	res, err := users.QuerySpec(ctx, And(name.Like("a%"), Or(age.Gte(40), age.IsNull())))
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	res, err = users.QuerySpec(ctx, Not(Or(name.Eq("ann"), age.Between(25, 35))))
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, "alice", res[0].Name)
+	res, err = users.QuerySpec(ctx, Or[User]())
+	require.NoError(t, err)
+	require.Empty(t, res)
+	n, err := users.CountSpec(ctx, And(name.In("ann", "bob"), age.NotNull()))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+	n, err = users.CountSpec(ctx, And[User]())
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+
+	_, err = users.QuerySpec(ctx, Field[User, string]("email").Eq("a@b.c"))
+	require.ErrorIs(t, err, InvalidFilterError)
+
+	db := dryRunDB(t)
+	stmts := captureSQL(t, db)
+	_, err = New[User](db).QuerySpec(ctx, And(name.Ne("ann"), Not(Or(age.Lt(18), age.Gt(65)))))
+	require.NoError(t, err)
+	require.Contains(t, (*stmts)[0], `WHERE ("users"."name" <> $1 AND NOT ("users"."age" < $2 OR "users"."age" > $3)) AND "users"."deleted_at" IS NULL`)
+}
@@ -0,0 +1,84 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// StateConflictError is returned by StateMachine.Transition when row is not in from state anymore
+	StateConflictError = errors.New("state changed concurrently")
+)
+
+type (
+	// InvalidTransitionError is returned by StateMachine.Transition for transitions not declared
+	InvalidTransitionError[S comparable] struct {
+		Column   string
+		From, To S
+	}
+
+	// StateMachine restricts changes of status column to declared transitions, applying them with UpdateIf
+	StateMachine[T GORMModel, S comparable] struct {
+		crud        GenericCRUD[T]
+		column      string
+		transitions map[S]map[S]bool
+	}
+)
+
+// Error implements error
+func (e InvalidTransitionError[S]) Error() string {
+	return fmt.Sprintf("invalid %s transition %v -> %v", e.Column, e.From, e.To)
+}
+
+// NewStateMachine is a constructor; transitions maps state to states allowed next:
+//
+//	orders := crud.NewStateMachine(crud.New[Order](db), "status", map[Status][]Status{
+//		Pending: {Paid, Cancelled},
+//		Paid:    {Shipped},
+//	})
+func NewStateMachine[T GORMModel, S comparable](crud GenericCRUD[T], column string, transitions map[S][]S) *StateMachine[T, S] {
+	m := &StateMachine[T, S]{crud: crud, column: column, transitions: make(map[S]map[S]bool, len(transitions))}
+	for from, next := range transitions {
+		m.transitions[from] = make(map[S]bool, len(next))
+		for _, to := range next {
+			m.transitions[from][to] = true
+		}
+	}
+	return m
+}
+
+// Can reports whether transition is declared
+func (m *StateMachine[T, S]) Can(from, to S) bool {
+	return m.transitions[from][to]
+}
+
+// Next returns states allowed after from
+func (m *StateMachine[T, S]) Next(from S) []S {
+	res := make([]S, 0, len(m.transitions[from]))
+	for to := range m.transitions[from] {
+		res = append(res, to)
+	}
+	return res
+}
+
+// Transition moves v (by primary key) from state to state, applying extraUpdates in the same statement.
+// Returns InvalidTransitionError for undeclared transition and StateConflictError if v is not in from state
+func (m *StateMachine[T, S]) Transition(ctx context.Context, v T, from, to S, extraUpdates map[string]any) error {
+	if !m.Can(from, to) {
+		return InvalidTransitionError[S]{Column: m.column, From: from, To: to}
+	}
+	updates := make(map[string]any, len(extraUpdates)+1)
+	for k, value := range extraUpdates {
+		updates[k] = value
+	}
+	updates[m.column] = to
+	n, err := m.crud.UpdateIf(ctx, v, updates, Query{Equal: map[string]any{m.column: from}})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s is not %v", StateConflictError, m.column, from)
+	}
+	return nil
+}
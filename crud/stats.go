@@ -0,0 +1,110 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// RequestStats accumulates statements executed with context of ContextWithStats
+type RequestStats struct {
+	Queries int
+	// RowsRead are rows returned by SELECT, RowsWritten are rows affected by INSERT, UPDATE and DELETE
+	RowsRead    int64
+	RowsWritten int64
+	// Duration is total time of statements
+	Duration time.Duration
+	// Errors are failed statements; not found is not an error
+	Errors int
+}
+
+type (
+	statsKey struct{}
+
+	statsCollector struct {
+		mu    sync.Mutex
+		stats RequestStats
+		// queries count executions of single-row queries by SQL; see WithNPlusOneDetection
+		queries map[string]int
+	}
+)
+
+/*
+ContextWithStats returns ctx collecting RequestStats of GenericCRUD calls made with it or contexts derived from it,
+e.g. in HTTP middleware (see crudhttp.Stats):
+
+	ctx := crud.ContextWithStats(r.Context())
+	next.ServeHTTP(w, r.WithContext(ctx))
+	if stats, _ := crud.StatsFromContext(ctx); stats.Queries > 20 {
+		log.Printf("%s did %d queries in %s", r.URL.Path, stats.Queries, stats.Duration)
+	}
+
+Collecting is safe for concurrent use; ctx already collecting is returned as is, so outer collector counts all statements
+*/
+func ContextWithStats(ctx context.Context) context.Context {
+	if statsOf(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, statsKey{}, &statsCollector{})
+}
+
+// StatsFromContext returns statistics collected so far; false if ctx is not from ContextWithStats
+func StatsFromContext(ctx context.Context) (RequestStats, bool) {
+	c := statsOf(ctx)
+	if c == nil {
+		return RequestStats{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats, true
+}
+
+func statsOf(ctx context.Context) *statsCollector {
+	c, _ := ctx.Value(statsKey{}).(*statsCollector)
+	return c
+}
+
+// add counts statement
+func (c *statsCollector) add(sql string, rows int64, elapsed time.Duration, err error) {
+	operation, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Queries++
+	c.stats.Duration += elapsed
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.stats.Errors++
+	}
+	if rows <= 0 {
+		return
+	}
+	switch strings.ToUpper(operation) {
+	case "SELECT":
+		c.stats.RowsRead += rows
+	case "INSERT", "UPDATE", "DELETE":
+		c.stats.RowsWritten += rows
+	}
+}
+
+// statsLogger wraps logger to collect statistics of statements
+type statsLogger struct {
+	logger.Interface
+	collector *statsCollector
+}
+
+// LogMode keeps collecting on logger with changed level
+func (l statsLogger) LogMode(level logger.LogLevel) logger.Interface {
+	l.Interface = l.Interface.LogMode(level)
+	return l
+}
+
+// Trace implements logger.Interface
+func (l statsLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	l.Interface.Trace(ctx, begin, fc, err)
+	sql, rows := fc()
+	l.collector.add(sql, rows, time.Since(begin), err)
+}
@@ -0,0 +1,49 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestStatsFromContext(t *testing.T) {
+	g := New[User](benchDB(t, "stats"))
+	_, ok := StatsFromContext(context.TODO())
+	require.False(t, ok)
+
+	ctx := ContextWithStats(context.TODO())
+	require.Equal(t, ctx, ContextWithStats(ctx))
+	require.NoError(t, g.CreateMany(ctx, []User{{Name: "ann"}, {Name: "bob"}}, 10))
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := g.Query(ctx, User{})
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	_, err := g.GetByID(ctx, User{Model: gorm.Model{ID: 42}})
+	require.Error(t, err)
+	require.NoError(t, g.RunInTransaction(ctx, func(ctx context.Context, tx GenericCRUD[User]) error {
+		return tx.UpdateField(ctx, User{Model: gorm.Model{ID: 1}}, "name", "x")
+	}))
+
+	stats, ok := StatsFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, 6, stats.Queries)
+	require.EqualValues(t, 6, stats.RowsRead)
+	require.EqualValues(t, 3, stats.RowsWritten)
+	require.Zero(t, stats.Errors)
+	require.Positive(t, stats.Duration)
+
+	stats, _ = StatsFromContext(ctx)
+	statsOf(ctx).add("SELECT 1", 0, 0, errors.New("boom"))
+	after, _ := StatsFromContext(ctx)
+	require.Equal(t, stats.Errors+1, after.Errors)
+}
@@ -0,0 +1,28 @@
+package crud
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Subquery is a query of another model usable as IN condition value; see GenericCRUD.Subquery
+type Subquery struct {
+	build func(ctx context.Context) (*gorm.DB, error)
+}
+
+// Subquery returns subquery selecting column from rows of T matching q, e.g.
+//
+//	users.SmartQuery(ctx, Query{InQuery: map[string]Subquery{
+//		"id": orders.Subquery(Query{Gt: map[string]any{"total": 100}}, "user_id"),
+//	}})
+func (g GenericCRUD[T]) Subquery(q Query, column string) Subquery {
+	q.Select, q.Preload = []string{column}, nil
+	return Subquery{build: func(ctx context.Context) (*gorm.DB, error) {
+		stmt, err := g.uncapped().smartStmt(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return stmt.Model(new(T)), nil
+	}}
+}
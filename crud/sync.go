@@ -0,0 +1,133 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type (
+	// SyncOptions configures Sync
+	SyncOptions struct {
+		// BatchSize is number of rows per upsert; 500 if zero
+		BatchSize int
+		// ConflictColumns identify existing rows, primary key if empty
+		ConflictColumns []string
+		// UpdateColumns are updated on conflict; all columns if empty
+		UpdateColumns []string
+		// Progress is called after every written batch
+		Progress func(SyncReport)
+	}
+
+	// SyncReport counts rows read from source and written to destination
+	SyncReport struct {
+		Read    int
+		Written int
+		Batches int
+	}
+)
+
+/*
+Sync streams rows of src matching q to dst in primary key order, upserting them in batches, e.g. to migrate tenant
+between clusters:
+
+	report, err := crud.Sync(ctx, users.WithDB(oldCluster), users.WithDB(newCluster), crud.Query{
+		Equal: map[string]any{"tenant_id": 42},
+	}, crud.SyncOptions{Progress: func(r crud.SyncReport) { log.Printf("synced %d rows", r.Written) }})
+
+Rows are copied as stored: primary keys are kept, hooks and associations are skipped, and OrderBy, Select and
+Preload of q are ignored. Each batch is written in its own statement; on error report counts rows written so far.
+Sequences of dst are not advanced by explicit primary keys
+*/
+func Sync[T GORMModel](ctx context.Context, src, dst GenericCRUD[T], q Query, opts SyncOptions) (SyncReport, error) {
+	var report SyncReport
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	s, err := src.schema()
+	if err != nil {
+		return report, err
+	}
+	if s.PrioritizedPrimaryField == nil {
+		return report, fmt.Errorf("%s has no primary key", s.Name)
+	}
+	q.Select, q.Preload = nil, nil
+	q.OrderBy = []OrderClause{{Column: s.PrioritizedPrimaryField.DBName, Direction: ASC}}
+	stmt, err := src.uncapped().smartStmt(ctx, q)
+	if err != nil {
+		return report, err
+	}
+	rows, err := stmt.Model(new(T)).Rows()
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+	onConflict := copyConflict(s, opts.ConflictColumns, opts.UpdateColumns)
+	batch := make([]T, 0, opts.BatchSize)
+	write := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := insertCopies(dst.session(ctx), batch, onConflict); err != nil {
+			return fmt.Errorf("sync batch %d: %w", report.Batches+1, err)
+		}
+		report.Written += len(batch)
+		report.Batches++
+		batch = batch[:0]
+		if opts.Progress != nil {
+			opts.Progress(report)
+		}
+		return nil
+	}
+	for rows.Next() {
+		var v T
+		if err = stmt.ScanRows(rows, &v); err != nil {
+			return report, err
+		}
+		report.Read++
+		if batch = append(batch, v); len(batch) == opts.BatchSize {
+			if err = write(); err != nil {
+				return report, err
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return report, err
+	}
+	return report, write()
+}
+
+// copyConflict returns upsert of copied rows conflicting on columns, primary keys if empty: update columns, all but
+// primary keys if empty, are set to copied values; unlike UpdateAll, timestamps are copied too
+func copyConflict(s *schema.Schema, conflict, update []string) clause.OnConflict {
+	var res clause.OnConflict
+	for _, c := range conflict {
+		res.Columns = append(res.Columns, clause.Column{Name: c})
+	}
+	if len(conflict) == 0 {
+		for _, f := range s.PrimaryFields {
+			res.Columns = append(res.Columns, clause.Column{Name: f.DBName})
+		}
+	}
+	if len(update) == 0 {
+		for _, f := range s.Fields {
+			if f.DBName != "" && !f.PrimaryKey && f.Creatable {
+				update = append(update, f.DBName)
+			}
+		}
+	}
+	res.DoUpdates = clause.AssignmentColumns(update)
+	return res
+}
+
+// insertCopies inserts rows as stored, skipping hooks and associations
+func insertCopies[T any](db *gorm.DB, rows []T, onConflict clause.Expression) error {
+	stmt := db.Session(&gorm.Session{SkipHooks: true}).Omit(clause.Associations)
+	if onConflict != nil {
+		stmt = stmt.Clauses(onConflict)
+	}
+	return stmt.Create(&rows).Error
+}
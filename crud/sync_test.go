@@ -0,0 +1,58 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TestSync(t *testing.T) {
+	ctx := context.TODO()
+	src, dst := New[User](benchDB(t, "sync_src")), New[User](benchDB(t, "sync_dst"))
+	for i := 1; i <= 5; i++ {
+		_, err := src.Create(ctx, User{Name: fmt.Sprintf("user%d", i)})
+		require.NoError(t, err)
+	}
+	_, err := dst.Create(ctx, User{Model: gorm.Model{ID: 2}, Name: "stale"})
+	require.NoError(t, err)
+
+	var progress []SyncReport
+	report, err := Sync(ctx, src, dst, Query{NotEqual: map[string]any{"name": "user5"}}, SyncOptions{
+		BatchSize: 3,
+		Progress:  func(r SyncReport) { progress = append(progress, r) },
+	})
+	require.NoError(t, err)
+	require.Equal(t, SyncReport{Read: 4, Written: 4, Batches: 2}, report)
+	require.Equal(t, []SyncReport{{Read: 3, Written: 3, Batches: 1}, report}, progress)
+
+	got, err := dst.SmartQuery(ctx, Query{OrderBy: []OrderClause{{Column: "id"}}})
+	require.NoError(t, err)
+	require.Len(t, got, 4)
+	for i, u := range got {
+		require.EqualValues(t, i+1, u.ID)
+		require.Equal(t, fmt.Sprintf("user%d", i+1), u.Name)
+	}
+	a, err := src.Digest(ctx, Query{Lte: map[string]any{"id": 4}})
+	require.NoError(t, err)
+	b, err := dst.Digest(ctx, Query{})
+	require.NoError(t, err)
+	require.Equal(t, a, b)
+}
+
+func TestCopyConflict(t *testing.T) {
+	db := dryRunDB(t)
+	sql := captureSQL(t, db)
+	s, err := New[User](db).schema()
+	require.NoError(t, err)
+	for _, conflict := range []clause.OnConflict{copyConflict(s, nil, []string{"name"}), copyConflict(s, []string{"id"}, []string{"name"})} {
+		require.NoError(t, insertCopies(db, []User{{Model: gorm.Model{ID: 1}, Name: "ann"}}, conflict))
+	}
+	require.Len(t, *sql, 2)
+	for _, got := range *sql {
+		require.Contains(t, got, `ON CONFLICT ("id") DO UPDATE SET "name"="excluded"."name"`)
+	}
+}
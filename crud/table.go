@@ -0,0 +1,61 @@
+package crud
+
+import (
+	"context"
+	"strings"
+)
+
+// TableResolver returns table name for operation on v; v is zero for operations without model value
+// (SmartQuery, QueryMap etc.). Empty name means default table of T
+type TableResolver[T GORMModel] func(ctx context.Context, v T) string
+
+// WithTableResolver returns copy of g routing operations to tables returned by resolver,
+// e.g. partitions or date-suffixed tables
+func (g GenericCRUD[T]) WithTableResolver(resolver TableResolver[T]) GenericCRUD[T] {
+	g.table = resolver
+	return g
+}
+
+// WithTable returns copy of g pinned to table, optionally schema-qualified, e.g. "billing.invoices" or a view
+func (g GenericCRUD[T]) WithTable(table string) GenericCRUD[T] {
+	return g.WithTableResolver(func(context.Context, T) string {
+		return table
+	})
+}
+
+// WithSchema returns copy of g qualifying tables with Postgres schema, e.g. "billing";
+// names already qualified by resolver are kept
+func (g GenericCRUD[T]) WithSchema(schema string) GenericCRUD[T] {
+	resolver := g.table
+	return g.WithTableResolver(func(ctx context.Context, v T) string {
+		var table string
+		if resolver != nil {
+			table = resolver(ctx, v)
+		}
+		if table == "" {
+			s, err := g.schema()
+			if err != nil {
+				return ""
+			}
+			table = s.Table
+		}
+		if strings.Contains(table, ".") {
+			return table
+		}
+		return schema + "." + table
+	})
+}
+
+// tableName returns table of operation on v: resolved by TableResolver or default table of T
+func (g GenericCRUD[T]) tableName(ctx context.Context, v T) (string, error) {
+	if g.table != nil {
+		if t := g.table(ctx, v); t != "" {
+			return t, nil
+		}
+	}
+	s, err := g.schema()
+	if err != nil {
+		return "", err
+	}
+	return s.Table, nil
+}
@@ -0,0 +1,59 @@
+package crud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type monthKey struct{}
+
+func TestTableResolver(t *testing.T) {
+	db := dryRunDB(t)
+	sql := captureSQL(t, db)
+	g := New[User](db).WithTableResolver(func(ctx context.Context, v User) string {
+		if m, ok := ctx.Value(monthKey{}).(string); ok {
+			return "users_" + m
+		}
+		return ""
+	})
+	ctx := context.WithValue(context.TODO(), monthKey{}, "2024_05")
+	_, err := g.Create(ctx, User{Name: "test"})
+	require.NoError(t, err)
+	require.Contains(t, (*sql)[0], `INSERT INTO "users_2024_05"`)
+
+	_, err = g.SmartQuery(ctx, Query{Equal: map[string]any{"name": "test"}})
+	require.NoError(t, err)
+	require.Contains(t, (*sql)[1], `FROM "users_2024_05" WHERE name = $1 AND "users_2024_05"."deleted_at" IS NULL`)
+
+	_, err = g.SmartQuery(context.TODO(), Query{})
+	require.NoError(t, err)
+	require.Contains(t, (*sql)[2], `FROM "users"`)
+}
+
+func TestWithTable(t *testing.T) {
+	db := dryRunDB(t)
+	sql := captureSQL(t, db)
+	_, err := New[User](db).WithTable("billing.invoices").SmartQuery(context.TODO(), Query{Equal: map[string]any{"name": "x"}})
+	require.NoError(t, err)
+	require.Contains(t, (*sql)[0], `FROM "billing"."invoices" WHERE name = $1 AND "invoices"."deleted_at" IS NULL`)
+
+	g := New[User](db).WithSchema("app")
+	require.NoError(t, g.UpdateField(context.TODO(), User{Model: gorm.Model{ID: 1}}, "name", "x"))
+	require.Contains(t, (*sql)[1], `UPDATE "app"."users" SET "name"=$1`)
+
+	_, err = g.WithTable("archive.users").SmartQuery(context.TODO(), Query{})
+	require.NoError(t, err)
+	require.Contains(t, (*sql)[2], `FROM "archive"."users"`)
+}
+
+func TestReadOnly(t *testing.T) {
+	db := dryRunDB(t)
+	sql := captureSQL(t, db)
+	r := NewReadOnly[User](db).WithTable("active_users")
+	_, err := r.SmartQuery(context.TODO(), Query{Limit: 1})
+	require.NoError(t, err)
+	require.Contains(t, (*sql)[0], `FROM "active_users"`)
+}
@@ -0,0 +1,139 @@
+package crud
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// QueryTags returns tags of caller in ctx appended to every statement as SQL comment; characters other than
+// letters, digits and "._:-" are replaced with "_", e.g.
+// {"service": "checkout", "endpoint": "CreateOrder"} makes /* endpoint=CreateOrder service=checkout */
+type QueryTags func(ctx context.Context) map[string]string
+
+// WithQueryTags returns copy of g tagging statements with comment built by tags,
+// so pg_stat_activity and slow query logs show call sites
+func (g GenericCRUD[T]) WithQueryTags(tags QueryTags) GenericCRUD[T] {
+	g.cfg.QueryTags = tags
+	return g
+}
+
+// commentSafe replaces characters outside of [A-Za-z0-9._:-] with "_", so tag values can't end comment
+func commentSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("._:-", r):
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// queryComment formats tags as SQL comment; empty if there are no tags
+func queryComment(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(" /*")
+	for _, k := range keys {
+		b.WriteByte(' ')
+		b.WriteString(commentSafe(k))
+		b.WriteByte('=')
+		b.WriteString(commentSafe(tags[k]))
+	}
+	b.WriteString(" */")
+	return b.String()
+}
+
+// withQueryTags routes statements of db through connection wrapper appending comment of ctx tags
+func (g GenericCRUD[T]) withQueryTags(ctx context.Context, db *gorm.DB) *gorm.DB {
+	comment := queryComment(g.cfg.QueryTags(ctx))
+	if comment == "" {
+		return db
+	}
+	if tx, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		db.Statement.ConnPool = &taggedTx{taggedPool{db.Statement.ConnPool, comment}, tx}
+	} else {
+		db.Statement.ConnPool = &taggedPool{db.Statement.ConnPool, comment}
+	}
+	return db
+}
+
+// taggedPool appends comment to statements
+type taggedPool struct {
+	pool    gorm.ConnPool
+	comment string
+}
+
+func (p *taggedPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.pool.PrepareContext(ctx, query+p.comment)
+}
+
+func (p *taggedPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return p.pool.ExecContext(ctx, query+p.comment, args...)
+}
+
+func (p *taggedPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return p.pool.QueryContext(ctx, query+p.comment, args...)
+}
+
+func (p *taggedPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return p.pool.QueryRowContext(ctx, query+p.comment, args...)
+}
+
+// BeginTx starts transaction tagging its statements too
+func (p *taggedPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	var (
+		tx  gorm.ConnPool
+		err error
+	)
+	switch beginner := p.pool.(type) {
+	case gorm.TxBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	case gorm.ConnPoolBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	default:
+		return nil, gorm.ErrInvalidTransaction
+	}
+	if err != nil {
+		return nil, err
+	}
+	if committer, ok := tx.(gorm.TxCommitter); ok {
+		return &taggedTx{taggedPool{tx, p.comment}, committer}, nil
+	}
+	return &taggedPool{tx, p.comment}, nil
+}
+
+// GetDBConn returns underlying *sql.DB
+func (p *taggedPool) GetDBConn() (*sql.DB, error) {
+	if db, ok := p.pool.(*sql.DB); ok {
+		return db, nil
+	}
+	if c, ok := p.pool.(gorm.GetDBConnector); ok {
+		return c.GetDBConn()
+	}
+	return nil, gorm.ErrInvalidDB
+}
+
+// taggedTx is taggedPool of transaction
+type taggedTx struct {
+	taggedPool
+	tx gorm.TxCommitter
+}
+
+func (t *taggedTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *taggedTx) Rollback() error {
+	return t.tx.Rollback()
+}
@@ -0,0 +1,123 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// TreeCycleError is returned by Tree.MoveSubtree moving node under itself or its descendant
+	TreeCycleError = errors.New("move would create a cycle")
+)
+
+// Tree queries adjacency-list hierarchy of T, where parent column references primary key; NULL parent is root
+type Tree[T GORMModel] struct {
+	crud   GenericCRUD[T]
+	parent string
+}
+
+// NewTree is a constructor
+func NewTree[T GORMModel](crud GenericCRUD[T], parentColumn string) *Tree[T] {
+	return &Tree[T]{crud: crud, parent: parentColumn}
+}
+
+// Roots returns nodes without parent
+func (t *Tree[T]) Roots(ctx context.Context) ([]*T, error) {
+	var res []*T
+	err := t.crud.session(ctx).Omit(t.crud.readOmits(ctx, t.crud.cfg.Omit)...).Where(t.parent + " IS NULL").Find(&res).Error
+	return res, err
+}
+
+// Children returns direct children of node with primary key id
+func (t *Tree[T]) Children(ctx context.Context, id any) ([]*T, error) {
+	return t.crud.SmartQuery(ctx, Query{Equal: map[string]any{t.parent: id}})
+}
+
+// Descendants returns nodes below id up to maxDepth levels (unlimited if <= 0), breadth first
+func (t *Tree[T]) Descendants(ctx context.Context, id any, maxDepth int) ([]*T, error) {
+	// anchor: children of id; step: children of previous level
+	return t.recursive(ctx, "t."+t.parent+" = ?", "c."+t.parent+" = tree.%s", id, maxDepth)
+}
+
+// Ancestors returns nodes above id, nearest first
+func (t *Tree[T]) Ancestors(ctx context.Context, id any) ([]*T, error) {
+	s, err := t.crud.schema()
+	if err != nil {
+		return nil, err
+	}
+	var zero T
+	table, err := t.crud.tableName(ctx, zero)
+	if err != nil {
+		return nil, err
+	}
+	pk := s.PrioritizedPrimaryField.DBName
+	anchor := fmt.Sprintf("t.%s = (SELECT %s FROM %s WHERE %s = ?)", pk, t.parent, table, pk)
+	return t.recursive(ctx, anchor, "c.%s = tree."+t.parent, id, 0)
+}
+
+// recursive runs recursive CTE; step format has primary key column placeholder
+func (t *Tree[T]) recursive(ctx context.Context, anchor, step string, id any, maxDepth int) ([]*T, error) {
+	s, err := t.crud.schema()
+	if err != nil {
+		return nil, err
+	}
+	var zero T
+	table, err := t.crud.tableName(ctx, zero)
+	if err != nil {
+		return nil, err
+	}
+	pk := s.PrioritizedPrimaryField.DBName
+	step = fmt.Sprintf(step, pk)
+	anchorWhere, stepWhere := []string{anchor}, []string{step}
+	if f := softDeleteField(s); f != nil {
+		anchorWhere = append(anchorWhere, "t."+f.DBName+" IS NULL")
+		stepWhere = append(stepWhere, "c."+f.DBName+" IS NULL")
+	}
+	vars := []any{id}
+	if maxDepth > 0 {
+		stepWhere = append(stepWhere, "tree.tree_depth < ?")
+		vars = append(vars, maxDepth)
+	}
+	// only columns read in ctx are selected from tree, as Raw ignores omits
+	sql := fmt.Sprintf(`WITH RECURSIVE tree AS (
+SELECT t.*, 1 AS tree_depth FROM %[1]s t WHERE %[2]s
+UNION ALL
+SELECT c.*, tree.tree_depth + 1 FROM %[1]s c JOIN tree ON %[3]s
+) SELECT %[5]s FROM tree ORDER BY tree_depth, %[4]s`, table, strings.Join(anchorWhere, " AND "), strings.Join(stepWhere, " AND "), pk,
+		strings.Join(t.crud.readColumns(ctx, s), ", "))
+	var res []*T
+	err = t.crud.session(ctx).Raw(sql, vars...).Scan(&res).Error
+	return res, err
+}
+
+// MoveSubtree makes newParent (nil for root) parent of node id, moving its descendants with it
+func (t *Tree[T]) MoveSubtree(ctx context.Context, id, newParent any) error {
+	v, err := t.crud.FromID(id)
+	if err != nil {
+		return err
+	}
+	return t.crud.RunInTransaction(ctx, func(ctx context.Context, tx GenericCRUD[T]) error {
+		if newParent != nil {
+			p, err := tx.FromID(newParent)
+			if err != nil {
+				return err
+			}
+			if fmt.Sprint(p.PrimaryKey()) == fmt.Sprint(v.PrimaryKey()) {
+				return TreeCycleError
+			}
+			tree := &Tree[T]{crud: tx, parent: t.parent}
+			descendants, err := tree.Descendants(ctx, id, 0)
+			if err != nil {
+				return err
+			}
+			for _, d := range descendants {
+				if fmt.Sprint((*d).PrimaryKey()) == fmt.Sprint(p.PrimaryKey()) {
+					return TreeCycleError
+				}
+			}
+		}
+		return tx.UpdateField(ctx, v, t.parent, newParent)
+	})
+}
@@ -0,0 +1,35 @@
+package crud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTreeReadOmits(t *testing.T) {
+	ctx := context.TODO()
+	db := benchDB(t, "tree_omits")
+	require.NoError(t, db.AutoMigrate(&Category{}))
+	categories := New[Category](db)
+	root, err := categories.Create(ctx, Category{Name: "root", Path: "/root"})
+	require.NoError(t, err)
+	child, err := categories.Create(ctx, Category{Name: "child", Path: "/root/child", ParentID: &root.ID})
+	require.NoError(t, err)
+
+	hidden := NewTree(NewWithConfig[Category](db, Config{Omit: []string{"path"}}).
+		WithFieldPolicy(func(ctx context.Context) []string { return []string{"name"} }), "parent_id")
+	descendants, err := hidden.Descendants(ctx, root.ID, 0)
+	require.NoError(t, err)
+	ancestors, err := hidden.Ancestors(ctx, child.ID)
+	require.NoError(t, err)
+	for _, res := range [][]*Category{descendants, ancestors} {
+		require.Len(t, res, 1)
+		require.NotZero(t, res[0].ID)
+		require.Empty(t, res[0].Name)
+		require.Empty(t, res[0].Path)
+	}
+	res, err := NewTree(categories, "parent_id").Descendants(ctx, root.ID, 0)
+	require.NoError(t, err)
+	require.Equal(t, "child", res[0].Name)
+}
@@ -0,0 +1,91 @@
+package crud
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// NotInTransactionError is returned by savepoint operations outside of transaction
+	NotInTransactionError = errors.New("not in transaction")
+)
+
+type txKey struct{}
+
+// ContextWithTx returns ctx carrying transaction tx; GenericCRUD methods called with it use tx
+func ContextWithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext returns transaction stored by ContextWithTx
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok
+}
+
+/*
+Transaction runs fn with ctx carrying transaction, committing if fn returns nil.
+If ctx already carries transaction, savepoint is used instead, so calls nest safely.
+All GenericCRUD methods called with ctx share the transaction:
+
+	err := crud.Transaction(ctx, db, func(ctx context.Context) error {
+		if _, err := users.Create(ctx, user); err != nil {
+			return err
+		}
+		_, err := orders.Create(ctx, order)
+		return err
+	})
+*/
+func Transaction(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	Setup(db)
+	if tx, ok := TxFromContext(ctx); ok {
+		db = tx
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(ContextWithTx(ctx, tx))
+	})
+}
+
+// RunInTransaction runs fn with tx bound to transaction, committing if fn returns nil.
+// When g or ctx is already bound to transaction, savepoint is used instead, so calls nest safely
+func (g GenericCRUD[T]) RunInTransaction(ctx context.Context, fn func(ctx context.Context, tx GenericCRUD[T]) error) error {
+	if g.cfg.DryRun != nil {
+		return fn(ctx, g)
+	}
+	return Transaction(ctx, g.conn(ctx), func(ctx context.Context) error {
+		tx, _ := TxFromContext(ctx)
+		return fn(ctx, g.WithDB(tx))
+	})
+}
+
+// conn returns transaction from ctx or g's db
+func (g GenericCRUD[T]) conn(ctx context.Context) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return g.db
+}
+
+// InTransaction reports whether g is bound to transaction
+func (g GenericCRUD[T]) InTransaction() bool {
+	_, ok := g.db.Statement.ConnPool.(gorm.TxCommitter)
+	return ok
+}
+
+// Savepoint creates savepoint name in current transaction
+func (g GenericCRUD[T]) Savepoint(name string) error {
+	if !g.InTransaction() {
+		return NotInTransactionError
+	}
+	return g.db.SavePoint(name).Error
+}
+
+// RollbackTo rolls current transaction back to savepoint name
+func (g GenericCRUD[T]) RollbackTo(name string) error {
+	if !g.InTransaction() {
+		return NotInTransactionError
+	}
+	return g.db.RollbackTo(name).Error
+}
@@ -0,0 +1,100 @@
+package crud
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// UnitOfWork collects pending writes across GenericCRUD instances and applies them atomically on Flush
+type UnitOfWork struct {
+	mu  sync.Mutex
+	db  *gorm.DB
+	ops []uowOp
+}
+
+type uowOp struct {
+	apply func(ctx context.Context) error
+	// undo restores tracked value after failed flush
+	undo func()
+}
+
+// NewUnitOfWork is a constructor
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// StageCreate adds creation of *v; primary key and defaults are set on *v by Flush
+func StageCreate[T GORMModel](u *UnitOfWork, c GenericCRUD[T], v *T, omit ...string) {
+	orig := *v
+	u.add(uowOp{
+		apply: func(ctx context.Context) error {
+			res, err := c.Create(ctx, *v, omit...)
+			if err == nil {
+				*v = *res
+			}
+			return err
+		},
+		undo: func() { *v = orig },
+	})
+}
+
+// StageUpdate adds update of v; filter by primary key if non-zero
+func StageUpdate[T GORMModel](u *UnitOfWork, c GenericCRUD[T], v T, omit ...string) {
+	u.add(uowOp{apply: func(ctx context.Context) error {
+		return c.Update(ctx, v, omit...)
+	}})
+}
+
+// StageDelete adds deletion of v; filter by primary key if non-zero
+func StageDelete[T GORMModel](u *UnitOfWork, c GenericCRUD[T], v T) {
+	u.add(uowOp{apply: func(ctx context.Context) error {
+		return c.Delete(ctx, v)
+	}})
+}
+
+// Len returns number of pending operations
+func (u *UnitOfWork) Len() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return len(u.ops)
+}
+
+// Flush applies pending operations in order within single transaction.
+// On error transaction is rolled back, tracked values are restored and operations stay pending
+func (u *UnitOfWork) Flush(ctx context.Context) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	err := Transaction(ctx, u.db, func(ctx context.Context) error {
+		for _, op := range u.ops {
+			if err := op.apply(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		for _, op := range u.ops {
+			if op.undo != nil {
+				op.undo()
+			}
+		}
+		return err
+	}
+	u.ops = nil
+	return nil
+}
+
+// Discard drops pending operations
+func (u *UnitOfWork) Discard() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.ops = nil
+}
+
+func (u *UnitOfWork) add(op uowOp) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.ops = append(u.ops, op)
+}
@@ -0,0 +1,184 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultPageSize is used by ParseQuery when page is set without page_size
+	DefaultPageSize = 20
+	// MaxPageSize is the largest page_size accepted by ParseQuery
+	MaxPageSize = 1000
+)
+
+var (
+	// InvalidFilterError is returned when a filter can't be parsed or references disallowed column
+	InvalidFilterError = errors.New("invalid filter")
+)
+
+/*
+ParseQuery converts URL query string into Query.
+Filters are column__operator=value; operator is one of eq (default), ne, like, ilike, nlike,
+gt, gte, lt, lte, in (comma-separated values) and between (from,to).
+Reserved parameters are order_by (comma-separated columns, "-" prefix for DESC), page and page_size.
+Only allowed columns may be filtered or sorted; if allowed is empty, columns tagged
+crud:"filterable" and crud:"sortable" are, or all columns of T if none is tagged.
+Example:
+
+	?name__like=foo&age__gte=18&order_by=-created_at&page=2
+*/
+func (g GenericCRUD[T]) ParseQuery(values url.Values, allowed ...string) (Query, error) {
+	var q Query
+	columns, err := g.allowedColumns(allowed, TagFilterable)
+	if err != nil {
+		return q, err
+	}
+	sortable, err := g.allowedColumns(allowed, TagSortable)
+	if err != nil {
+		return q, err
+	}
+	column := func(name string) (string, error) {
+		if _, ok := columns[name]; !ok {
+			return "", fmt.Errorf("%w: unknown column %q", InvalidFilterError, name)
+		}
+		return name, nil
+	}
+	sortColumn := func(name string) (string, error) {
+		if _, ok := sortable[name]; !ok {
+			return "", fmt.Errorf("%w: unknown sort column %q", InvalidFilterError, name)
+		}
+		return name, nil
+	}
+	page, size := 0, 0
+	for key, vs := range values {
+		if len(vs) == 0 {
+			continue
+		}
+		value := vs[len(vs)-1]
+		switch key {
+		case "order_by":
+			for _, c := range strings.Split(value, ",") {
+				dir := ASC
+				if strings.HasPrefix(c, "-") {
+					dir, c = DESC, c[1:]
+				}
+				if c, err = sortColumn(c); err != nil {
+					return q, err
+				}
+				q.OrderBy = append(q.OrderBy, OrderClause{Column: c, Direction: dir})
+			}
+			continue
+		case "page":
+			if page, err = strconv.Atoi(value); err != nil || page < 1 {
+				return q, fmt.Errorf("%w: page %q", InvalidFilterError, value)
+			}
+			continue
+		case "page_size":
+			if size, err = strconv.Atoi(value); err != nil || size < 1 || size > MaxPageSize {
+				return q, fmt.Errorf("%w: page_size %q", InvalidFilterError, value)
+			}
+			continue
+		}
+		name, op, _ := strings.Cut(key, "__")
+		if name, err = column(name); err != nil {
+			return q, err
+		}
+		if err = q.AddFilter(name, op, value); err != nil {
+			return q, err
+		}
+	}
+	if page > 0 || size > 0 {
+		if size == 0 {
+			size = DefaultPageSize
+		}
+		if page == 0 {
+			page = 1
+		}
+		q.Limit, q.Offset = size, (page-1)*size
+	}
+	return q, nil
+}
+
+// AddFilter adds condition on column by operator name as accepted by ParseQuery
+func (q *Query) AddFilter(column, op, value string) error {
+	switch op {
+	case "", "eq":
+		set(&q.Equal, column, any(value))
+	case "ne":
+		set(&q.NotEqual, column, any(value))
+	case "like":
+		set(&q.Like, column, value)
+	case "ilike":
+		set(&q.ILike, column, value)
+	case "nlike":
+		set(&q.NotLike, column, value)
+	case "gt":
+		set(&q.Gt, column, any(value))
+	case "gte":
+		set(&q.Gte, column, any(value))
+	case "lt":
+		set(&q.Lt, column, any(value))
+	case "lte":
+		set(&q.Lte, column, any(value))
+	case "in":
+		var in []any
+		for _, v := range strings.Split(value, ",") {
+			in = append(in, v)
+		}
+		set(&q.In, column, in)
+	case "between":
+		from, to, ok := strings.Cut(value, ",")
+		if !ok {
+			return fmt.Errorf("%w: between requires from,to: %q", InvalidFilterError, value)
+		}
+		set(&q.Between, column, Between{From: from, To: to})
+	default:
+		return fmt.Errorf("%w: unknown operator %q", InvalidFilterError, op)
+	}
+	return nil
+}
+
+// allowedColumns returns set of allowed columns, defaulting to columns of T tagged with option
+// (TagFilterable or TagSortable) or all columns if none is tagged or option is empty. Defaults for filtering and
+// sorting never include omit_read columns and columns hidden by FieldPolicy from context without caller,
+// so hidden values can't be probed with filters
+func (g GenericCRUD[T]) allowedColumns(allowed []string, option string) (map[string]struct{}, error) {
+	defaults := len(allowed) == 0
+	if defaults && option != "" {
+		tagged, err := g.taggedColumns(option)
+		if err != nil {
+			return nil, err
+		}
+		allowed = tagged
+	}
+	if len(allowed) == 0 {
+		s, err := g.schema()
+		if err != nil {
+			return nil, err
+		}
+		allowed = s.DBNames
+	}
+	columns := make(map[string]struct{}, len(allowed))
+	for _, c := range allowed {
+		columns[c] = struct{}{}
+	}
+	if defaults && option != "" {
+		for _, c := range g.readOmits(context.Background()) {
+			delete(columns, c)
+		}
+	}
+	return columns, nil
+}
+
+// set initializes map if needed and sets m[k] = v
+func set[K comparable, V any](m *map[K]V, k K, v V) {
+	if *m == nil {
+		*m = make(map[K]V)
+	}
+	(*m)[k] = v
+}
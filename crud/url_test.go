@@ -0,0 +1,31 @@
+package crud
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	g := New[User](dryRunDB(t))
+	values, err := url.ParseQuery("name__like=foo&age__gte=18&id__in=1,2&order_by=-created_at,id&page=2&page_size=10")
+	require.NoError(t, err)
+	q, err := g.ParseQuery(values)
+	require.NoError(t, err)
+	require.Equal(t, Query{
+		Like:    map[string]string{"name": "foo"},
+		Gte:     map[string]any{"age": "18"},
+		In:      map[string][]any{"id": {"1", "2"}},
+		OrderBy: []OrderClause{{Column: "created_at", Direction: DESC}, {Column: "id", Direction: ASC}},
+		Limit:   10,
+		Offset:  10,
+	}, q)
+
+	for _, s := range []string{"password=x", "name__regex=x", "order_by=secret", "page=0", "age__between=1"} {
+		values, err = url.ParseQuery(s)
+		require.NoError(t, err)
+		_, err = g.ParseQuery(values, "name", "age")
+		require.ErrorIs(t, err, InvalidFilterError, s)
+	}
+}
@@ -0,0 +1,171 @@
+package crud
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/nullc4t/gorm-cruder/migrations"
+)
+
+// ChangeOp is operation of ChangeEvent
+type ChangeOp string
+
+const (
+	ChangeInsert ChangeOp = "INSERT"
+	ChangeUpdate ChangeOp = "UPDATE"
+	ChangeDelete ChangeOp = "DELETE"
+)
+
+// ChangeEvent is change of row of T; Row is current row, soft-deleted included, or only primary key for deleted
+// and since removed rows
+type ChangeEvent[T GORMModel] struct {
+	Op  ChangeOp
+	Row T
+}
+
+// changePayload is notification sent by trigger of WatchMigration
+type changePayload struct {
+	Op ChangeOp `json:"op"`
+	ID any      `json:"id"`
+}
+
+// watchNames returns trigger, function and channel names and quoted table of T
+func (g GenericCRUD[T]) watchNames(ctx context.Context) (name, table string, err error) {
+	var v T
+	if table, err = g.tableName(ctx, v); err != nil {
+		return "", "", err
+	}
+	return "crud_" + strings.ReplaceAll(table, ".", "_"), pgx.Identifier(strings.Split(table, ".")).Sanitize(), nil
+}
+
+/*
+WatchMigration returns migration installing Postgres trigger notifying Watch of every insert, update and delete of
+table of T, including writes bypassing GenericCRUD; add it to migrations runner:
+
+	m, _ := users.WatchMigration(ctx, 20240101)
+	_ = runner.Add(m)
+
+Notifications carry primary key only, so rows of any size are supported
+*/
+func (g GenericCRUD[T]) WatchMigration(ctx context.Context, version uint64) (migrations.Migration, error) {
+	s, err := g.schema()
+	if err != nil {
+		return migrations.Migration{}, err
+	}
+	if s.PrioritizedPrimaryField == nil {
+		return migrations.Migration{}, fmt.Errorf("%s has no primary key", s.Name)
+	}
+	name, table, err := g.watchNames(ctx)
+	if err != nil {
+		return migrations.Migration{}, err
+	}
+	ident := pgx.Identifier{name}.Sanitize()
+	pk := pgx.Identifier{s.PrioritizedPrimaryField.DBName}.Sanitize()
+	return migrations.Migration{
+		Version: version,
+		Name:    "watch_" + name,
+		Up: fmt.Sprintf(`CREATE OR REPLACE FUNCTION %[1]s() RETURNS trigger AS $$
+DECLARE r record;
+BEGIN
+	IF TG_OP = 'DELETE' THEN r := OLD; ELSE r := NEW; END IF;
+	PERFORM pg_notify('%[2]s', json_build_object('op', TG_OP, 'id', r.%[3]s)::text);
+	RETURN NULL;
+END $$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS %[1]s ON %[4]s;
+CREATE TRIGGER %[1]s AFTER INSERT OR UPDATE OR DELETE ON %[4]s FOR EACH ROW EXECUTE PROCEDURE %[1]s();`,
+			ident, strings.ReplaceAll(name, "'", "''"), pk, table),
+		Down: fmt.Sprintf("DROP TRIGGER IF EXISTS %[1]s ON %[2]s;\nDROP FUNCTION IF EXISTS %[1]s();", ident, table),
+	}, nil
+}
+
+// InstallWatch runs up script of WatchMigration, e.g. in tests or without migrations runner
+func (g GenericCRUD[T]) InstallWatch(ctx context.Context) error {
+	m, err := g.WatchMigration(ctx, 0)
+	if err != nil {
+		return err
+	}
+	return g.session(ctx).Exec(m.Up).Error
+}
+
+/*
+Watch calls fn with every change of table of T notified by trigger of WatchMigration until ctx is done, returning
+ctx.Err(). It holds dedicated Postgres connection; changed rows are loaded with separate queries, so Row reflects
+state at loading time. Notifications sent while Watch is not running are lost; see ChangeFeed for polling
+*/
+func (g GenericCRUD[T]) Watch(ctx context.Context, fn func(ChangeEvent[T])) error {
+	if g.Dialect() != Postgres {
+		return fmt.Errorf("watch is not supported for %q", g.Dialect())
+	}
+	name, _, err := g.watchNames(ctx)
+	if err != nil {
+		return err
+	}
+	sqlDB, ok := g.db.Statement.ConnPool.(*sql.DB)
+	if !ok {
+		return errors.New("watch requires connection pool, not transaction")
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("watch requires pgx driver")
+		}
+		if _, err := c.Conn().Exec(ctx, "LISTEN "+pgx.Identifier{name}.Sanitize()); err != nil {
+			return err
+		}
+		for {
+			n, err := c.Conn().WaitForNotification(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return err
+			}
+			e, err := g.changeEvent(ctx, []byte(n.Payload))
+			if err != nil {
+				return err
+			}
+			fn(e)
+		}
+	})
+}
+
+// changeEvent decodes notification payload and loads changed row
+func (g GenericCRUD[T]) changeEvent(ctx context.Context, payload []byte) (ChangeEvent[T], error) {
+	var p changePayload
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&p); err != nil {
+		return ChangeEvent[T]{}, fmt.Errorf("change notification: %w", err)
+	}
+	if n, ok := p.ID.(json.Number); ok {
+		p.ID = n.String()
+	}
+	v, err := g.FromID(p.ID)
+	if err != nil {
+		return ChangeEvent[T]{}, err
+	}
+	e := ChangeEvent[T]{Op: p.Op, Row: v}
+	if p.Op == ChangeDelete {
+		return e, nil
+	}
+	var rows []T
+	if err = g.sessionOf(ctx, v).Unscoped().Omit(g.readOmits(ctx)...).Limit(1).Find(&rows, v.PrimaryKey()).Error; err != nil {
+		return e, err
+	}
+	if len(rows) > 0 {
+		e.Row = rows[0]
+	}
+	return e, nil
+}
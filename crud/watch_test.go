@@ -0,0 +1,42 @@
+package crud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestWatchMigration(t *testing.T) {
+	ctx := context.TODO()
+	m, err := New[User](dryRunDB(t)).WithSchema("tenant").WatchMigration(ctx, 7)
+	require.NoError(t, err)
+	require.EqualValues(t, 7, m.Version)
+	require.Equal(t, "watch_crud_tenant_users", m.Name)
+	require.Contains(t, m.Up, `CREATE OR REPLACE FUNCTION "crud_tenant_users"() RETURNS trigger AS $$`)
+	require.Contains(t, m.Up, `PERFORM pg_notify('crud_tenant_users', json_build_object('op', TG_OP, 'id', r."id")::text);`)
+	require.Contains(t, m.Up, `CREATE TRIGGER "crud_tenant_users" AFTER INSERT OR UPDATE OR DELETE ON "tenant"."users" FOR EACH ROW`)
+	require.Equal(t, "DROP TRIGGER IF EXISTS \"crud_tenant_users\" ON \"tenant\".\"users\";\nDROP FUNCTION IF EXISTS \"crud_tenant_users\"();", m.Down)
+}
+
+func TestChangeEvent(t *testing.T) {
+	ctx := context.TODO()
+	g := New[User](benchDB(t, "watch"))
+	u, err := g.Create(ctx, User{Name: "ann"})
+	require.NoError(t, err)
+	require.NoError(t, g.Delete(ctx, *u))
+
+	e, err := g.changeEvent(ctx, []byte(`{"op":"UPDATE","id":1}`))
+	require.NoError(t, err)
+	require.Equal(t, ChangeUpdate, e.Op)
+	require.Equal(t, "ann", e.Row.Name)
+	require.True(t, e.Row.DeletedAt.Valid)
+	e, err = g.changeEvent(ctx, []byte(`{"op":"DELETE","id":2}`))
+	require.NoError(t, err)
+	require.Equal(t, ChangeEvent[User]{Op: ChangeDelete, Row: User{Model: gorm.Model{ID: 2}}}, e)
+	_, err = g.changeEvent(ctx, []byte(`{"op":`))
+	require.Error(t, err)
+
+	require.ErrorContains(t, g.Watch(ctx, func(ChangeEvent[User]) {}), `watch is not supported for "sqlite"`)
+}
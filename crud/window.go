@@ -0,0 +1,137 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type (
+	// Window is a ranking window function over partitions of rows
+	Window struct {
+		// Func is ROW_NUMBER (default), RANK or DENSE_RANK
+		Func        string        `json:"func,omitempty"`
+		PartitionBy []string      `json:"partition_by,omitempty"`
+		OrderBy     []OrderClause `json:"order_by,omitempty"`
+		// Max keeps only rows ranked <= Max within partition; 0 keeps all
+		Max int `json:"max,omitempty"`
+		// Alias of rank column; "window_rank" if empty
+		Alias string `json:"alias,omitempty"`
+	}
+
+	// OrderClause is a single ordering item; zero Direction is ASC
+	OrderClause struct {
+		Column    string  `json:"column"`
+		Direction OrderBy `json:"direction,omitempty"`
+		// NullsFirst and NullsLast place NULL values before or after others in both directions;
+		// by default Postgres sorts NULLs last in ASC order, MySQL and SQLite first
+		NullsFirst bool `json:"nulls_first,omitempty"`
+		NullsLast  bool `json:"nulls_last,omitempty"`
+		// Func orders by function of column: LOWER, UPPER, LENGTH or ABS, e.g. LOWER for case-insensitive sorting
+		Func string `json:"func,omitempty"`
+	}
+)
+
+var (
+	windowFuncs = map[string]bool{"ROW_NUMBER": true, "RANK": true, "DENSE_RANK": true}
+	// identifier matches rank column aliases safe to quote
+	identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// String returns SQL of clause
+func (o OrderClause) String() string {
+	res := o.Column
+	if o.Func != "" {
+		res = strings.ToUpper(o.Func) + "(" + res + ")"
+	}
+	if o.Direction != 0 {
+		res += " " + o.Direction.String()
+	}
+	if o.NullsFirst {
+		res += " NULLS FIRST"
+	} else if o.NullsLast {
+		res += " NULLS LAST"
+	}
+	return res
+}
+
+// direction returns Direction defaulting to ASC
+func (o OrderClause) direction() OrderBy {
+	if o.Direction == DESC {
+		return DESC
+	}
+	return ASC
+}
+
+// alias returns name of rank column
+func (w Window) alias() string {
+	if w.Alias == "" {
+		return "window_rank"
+	}
+	return w.Alias
+}
+
+// function returns window function, validating w
+func (w Window) function() (string, error) {
+	fn := strings.ToUpper(w.Func)
+	if fn == "" {
+		fn = "ROW_NUMBER"
+	}
+	if !windowFuncs[fn] {
+		return "", fmt.Errorf("%w: unknown window function %q", InvalidFilterError, w.Func)
+	}
+	if !identifier.MatchString(w.alias()) {
+		return "", fmt.Errorf("%w: invalid window alias %q", InvalidFilterError, w.Alias)
+	}
+	for _, o := range w.OrderBy {
+		if err := o.validate(); err != nil {
+			return "", err
+		}
+	}
+	return fn, nil
+}
+
+// window wraps filtered stmt into subquery with rank column and filters it by w.Max
+func (g GenericCRUD[T]) window(ctx context.Context, stmt *gorm.DB, w Window) (*gorm.DB, error) {
+	fn, err := w.function()
+	if err != nil {
+		return nil, err
+	}
+	alias := w.alias()
+	var (
+		over []string
+		vars []any
+	)
+	if len(w.PartitionBy) > 0 {
+		partition := make([]string, len(w.PartitionBy))
+		for i, c := range w.PartitionBy {
+			partition[i] = "?"
+			vars = append(vars, clause.Column{Name: c})
+		}
+		over = append(over, "PARTITION BY "+strings.Join(partition, ", "))
+	}
+	if len(w.OrderBy) > 0 {
+		order := make([]string, len(w.OrderBy))
+		for i, o := range w.OrderBy {
+			order[i] = "?"
+			vars = append(vars, g.Dialect().order(o, ""))
+		}
+		over = append(over, "ORDER BY "+strings.Join(order, ", "))
+	}
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	vars = append(vars, clause.Column{Name: alias})
+	inner := stmt.Model(new(T)).Select("*, "+fn+"() OVER ("+strings.Join(over, " ")+") AS ?", vars...)
+	// alias subquery as table so soft-delete and qualified columns keep working
+	outer := g.session(ctx).Table("(?) AS "+g.db.Statement.Quote(s.Table), inner)
+	if w.Max > 0 {
+		outer = outer.Where(clause.Lte{Column: clause.Column{Name: alias}, Value: w.Max})
+	}
+	return outer, nil
+}
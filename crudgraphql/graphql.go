@@ -0,0 +1,212 @@
+/*
+Package crudgraphql removes per-model GraphQL resolver boilerplate over crud.GenericCRUD:
+batch loaders for dataloaders and translation of filter inputs to crud.Query.
+
+Loaders match batch functions of dataloader libraries, e.g. with vikstrous/dataloadgen:
+
+	loader := dataloadgen.NewLoader(crudgraphql.LoadByIDs[User, uint](users))
+
+Filter, Order and Page mirror common input types of schema:
+
+	input UserFilter { name: StringFilter, age: IntFilter, and: [UserFilter!], or: [UserFilter!] }
+	input StringFilter { eq: String, ne: String, in: [String!], contains: String, startsWith: String }
+
+so resolvers bind arguments and call List:
+
+	func (r *queryResolver) Users(ctx context.Context, filter crudgraphql.Filter, order []crudgraphql.Order, page *crudgraphql.Page) ([]*User, error) {
+		return crudgraphql.List(ctx, r.users, filter, order, page, "name", "age")
+	}
+*/
+package crudgraphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nullc4t/gorm-cruder/crud"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// LoadByIDs returns batch function loading models by primary keys; results are aligned with keys,
+// missing keys get gorm.ErrRecordNotFound
+func LoadByIDs[T crud.GORMModel, K comparable](c crud.GenericCRUD[T]) func(ctx context.Context, keys []K) ([]*T, []error) {
+	return func(ctx context.Context, keys []K) ([]*T, []error) {
+		res := make([]*T, len(keys))
+		errs := make([]error, len(keys))
+		fail := func(err error) ([]*T, []error) {
+			for i := range errs {
+				errs[i] = err
+			}
+			return res, errs
+		}
+		ids := make([]any, len(keys))
+		for i, k := range keys {
+			ids[i] = k
+		}
+		found, err := c.GetByIDsOrdered(ctx, ids)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fail(err)
+		}
+		for i, v := range found {
+			if v != nil {
+				res[i] = v
+			} else {
+				errs[i] = fmt.Errorf("%w: %v", gorm.ErrRecordNotFound, keys[i])
+			}
+		}
+		return res, errs
+	}
+}
+
+type (
+	// Filter maps GraphQL field names (camelCase is converted to column names) to conditions;
+	// And and Or combine nested filters
+	Filter struct {
+		Fields map[string]FieldFilter
+		And    []Filter
+		Or     []Filter
+	}
+
+	// FieldFilter is condition on one field; all set operators must hold
+	FieldFilter struct {
+		Eq         any     `json:"eq,omitempty"`
+		Ne         any     `json:"ne,omitempty"`
+		In         []any   `json:"in,omitempty"`
+		Gt         any     `json:"gt,omitempty"`
+		Gte        any     `json:"gte,omitempty"`
+		Lt         any     `json:"lt,omitempty"`
+		Lte        any     `json:"lte,omitempty"`
+		Contains   *string `json:"contains,omitempty"`
+		StartsWith *string `json:"startsWith,omitempty"`
+		EndsWith   *string `json:"endsWith,omitempty"`
+		// Insensitive makes Contains, StartsWith and EndsWith case-insensitive
+		Insensitive bool `json:"insensitive,omitempty"`
+	}
+
+	// Order is sort input; Direction is ASC (default) or DESC
+	Order struct {
+		Field     string `json:"field"`
+		Direction string `json:"direction,omitempty"`
+	}
+
+	// Page is offset pagination input
+	Page struct {
+		First  int `json:"first,omitempty"`
+		Offset int `json:"offset,omitempty"`
+	}
+)
+
+// UnsupportedFilterError is returned for filters Query can not express
+var UnsupportedFilterError = errors.New("unsupported filter")
+
+var naming = schema.NamingStrategy{}
+
+// Column converts GraphQL field name to column name, e.g. createdAt to created_at
+func Column(field string) string {
+	return naming.ColumnName("", field)
+}
+
+// Query translates filter, order and page to crud.Query. Or is not supported by Query and is rejected
+func Query(filter Filter, order []Order, page *Page) (crud.Query, error) {
+	var q crud.Query
+	if err := apply(&q, filter); err != nil {
+		return q, err
+	}
+	for _, o := range order {
+		var dir crud.OrderBy
+		switch strings.ToUpper(o.Direction) {
+		case "", "ASC":
+			dir = crud.ASC
+		case "DESC":
+			dir = crud.DESC
+		default:
+			return q, fmt.Errorf("%w: direction %s", UnsupportedFilterError, o.Direction)
+		}
+		q.OrderBy = append(q.OrderBy, crud.OrderClause{Column: Column(o.Field), Direction: dir})
+	}
+	if page != nil {
+		q.Limit, q.Offset = page.First, page.Offset
+	}
+	return q, nil
+}
+
+// List translates arguments with Query, validates columns against allowed (all columns if empty) and runs SmartQuery
+func List[T crud.GORMModel](ctx context.Context, c crud.GenericCRUD[T], filter Filter, order []Order, page *Page, allowed ...string) ([]*T, error) {
+	q, err := Query(filter, order, page)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.ValidateQuery(q, allowed...); err != nil {
+		return nil, err
+	}
+	return c.SmartQuery(ctx, q)
+}
+
+// apply adds conditions of f to q; conditions on same column and operator must not repeat
+func apply(q *crud.Query, f Filter) error {
+	if len(f.Or) > 0 {
+		return fmt.Errorf("%w: or", UnsupportedFilterError)
+	}
+	for field, ff := range f.Fields {
+		column := Column(field)
+		set := func(name string, m *map[string]any, v any) error {
+			if v == nil {
+				return nil
+			}
+			if *m == nil {
+				*m = map[string]any{}
+			}
+			if _, ok := (*m)[column]; ok {
+				return fmt.Errorf("%w: repeated %s on %s", UnsupportedFilterError, name, field)
+			}
+			(*m)[column] = v
+			return nil
+		}
+		for _, op := range []struct {
+			name string
+			m    *map[string]any
+			v    any
+		}{
+			{"eq", &q.Equal, ff.Eq},
+			{"ne", &q.NotEqual, ff.Ne},
+			{"gt", &q.Gt, ff.Gt},
+			{"gte", &q.Gte, ff.Gte},
+			{"lt", &q.Lt, ff.Lt},
+			{"lte", &q.Lte, ff.Lte},
+		} {
+			if err := set(op.name, op.m, op.v); err != nil {
+				return err
+			}
+		}
+		if ff.In != nil {
+			if q.In == nil {
+				q.In = map[string][]any{}
+			}
+			if _, ok := q.In[column]; ok {
+				return fmt.Errorf("%w: repeated in on %s", UnsupportedFilterError, field)
+			}
+			q.In[column] = ff.In
+		}
+		for mode, v := range map[crud.PatternMode]*string{crud.Contains: ff.Contains, crud.Prefix: ff.StartsWith, crud.Suffix: ff.EndsWith} {
+			if v == nil {
+				continue
+			}
+			if q.Pattern == nil {
+				q.Pattern = map[string]crud.Pattern{}
+			}
+			if _, ok := q.Pattern[column]; ok {
+				return fmt.Errorf("%w: more than one of contains, startsWith and endsWith on %s", UnsupportedFilterError, field)
+			}
+			q.Pattern[column] = crud.Pattern{Value: *v, Mode: mode, Insensitive: ff.Insensitive}
+		}
+	}
+	for _, and := range f.And {
+		if err := apply(q, and); err != nil {
+			return err
+		}
+	}
+	return nil
+}
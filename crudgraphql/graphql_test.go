@@ -0,0 +1,70 @@
+package crudgraphql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/nullc4t/gorm-cruder/crud"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type user struct {
+	ID        uint `gorm:"primarykey"`
+	FirstName string
+	Age       int
+}
+
+func (u user) PrimaryKey() any {
+	return u.ID
+}
+
+func TestQuery(t *testing.T) {
+	prefix := "Jo"
+	q, err := Query(Filter{
+		Fields: map[string]FieldFilter{"firstName": {StartsWith: &prefix, Insensitive: true}},
+		And:    []Filter{{Fields: map[string]FieldFilter{"age": {Gte: 18, Lt: 65}}}},
+	}, []Order{{Field: "age", Direction: "desc"}}, &Page{First: 10})
+	require.NoError(t, err)
+	require.Equal(t, crud.Query{
+		Pattern: map[string]crud.Pattern{"first_name": {Value: "Jo", Mode: crud.Prefix, Insensitive: true}},
+		Gte:     map[string]any{"age": 18},
+		Lt:      map[string]any{"age": 65},
+		OrderBy: []crud.OrderClause{{Column: "age", Direction: crud.DESC}},
+		Limit:   10,
+	}, q)
+
+	_, err = Query(Filter{
+		Fields: map[string]FieldFilter{"age": {Eq: 1}},
+		And:    []Filter{{Fields: map[string]FieldFilter{"age": {Eq: 2}}}},
+	}, nil, nil)
+	require.ErrorIs(t, err, UnsupportedFilterError)
+	_, err = Query(Filter{Or: []Filter{{}}}, nil, nil)
+	require.ErrorIs(t, err, UnsupportedFilterError)
+}
+
+func TestLoadByIDs(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:crudgraphql?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&user{}))
+	users := crud.New[user](db)
+	for _, name := range []string{"a", "b"} {
+		_, err = users.Create(context.TODO(), user{FirstName: name})
+		require.NoError(t, err)
+	}
+
+	res, errs := LoadByIDs[user, uint](users)(context.TODO(), []uint{2, 3, 1})
+	require.Equal(t, "b", res[0].FirstName)
+	require.Nil(t, res[1])
+	require.True(t, errors.Is(errs[1], gorm.ErrRecordNotFound))
+	require.Equal(t, "a", res[2].FirstName)
+	require.NoError(t, errs[2])
+
+	list, err := List(context.TODO(), users, Filter{Fields: map[string]FieldFilter{"firstName": {Eq: "a"}}}, nil, nil, "first_name")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	_, err = List(context.TODO(), users, Filter{Fields: map[string]FieldFilter{"age": {Eq: 1}}}, nil, nil, "first_name")
+	require.ErrorIs(t, err, crud.InvalidFilterError)
+}
@@ -0,0 +1,192 @@
+/*
+Package crudgrpc adapts crud.GenericCRUD to standard gRPC CRUD services (AIP-131..135):
+Get, List with page tokens, Create, Update with FieldMask and Delete.
+
+Service works with proto messages M through conversion functions, so generated servers only forward calls:
+
+	type userServer struct {
+		pb.UnimplementedUserServiceServer
+		svc *crudgrpc.Service[User, *pb.User, uint32]
+	}
+
+	func (s userServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+		users, next, err := s.svc.List(ctx, req)
+		if err != nil {
+			return nil, status.Error(codes.Code(crudgrpc.CodeOf(err)), err.Error())
+		}
+		return &pb.ListUsersResponse{Users: users, NextPageToken: next}, nil
+	}
+
+crud.Model has uint32 ID matching proto uint32 id fields.
+*/
+package crudgrpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nullc4t/gorm-cruder/crud"
+	"gorm.io/gorm"
+)
+
+type (
+	// IDRequest is implemented by Get and Delete request messages with id field of type K
+	IDRequest[K any] interface {
+		GetId() K
+	}
+
+	// Service implements CRUD methods of gRPC service for model T and message M
+	Service[T crud.GORMModel, M any, K any] struct {
+		CRUD crud.GenericCRUD[T]
+		// ToProto and FromProto convert between model and message
+		ToProto   func(*T) M
+		FromProto func(M) T
+		// Allowed columns for filtering and sorting of List; all columns if empty
+		Allowed []string
+	}
+)
+
+// New is a constructor
+func New[T crud.GORMModel, M any, K any](c crud.GenericCRUD[T], toProto func(*T) M, fromProto func(M) T, allowed ...string) *Service[T, M, K] {
+	return &Service[T, M, K]{CRUD: c, ToProto: toProto, FromProto: fromProto, Allowed: allowed}
+}
+
+// Get message by id
+func (s *Service[T, M, K]) Get(ctx context.Context, req IDRequest[K]) (M, error) {
+	var zero M
+	v, err := s.CRUD.FromID(req.GetId())
+	if err != nil {
+		return zero, invalidArgument{err}
+	}
+	res, err := s.CRUD.GetByID(ctx, v)
+	if err != nil {
+		return zero, err
+	}
+	return s.ToProto(res), nil
+}
+
+// List messages matching request filter; returns page and next page token, empty on last page
+func (s *Service[T, M, K]) List(ctx context.Context, req crud.ListRequest) ([]M, string, error) {
+	q, err := s.CRUD.ListQuery(req, s.Allowed...)
+	if err != nil {
+		return nil, "", err
+	}
+	found, err := s.CRUD.SmartQuery(ctx, q)
+	if err != nil {
+		return nil, "", err
+	}
+	res := make([]M, len(found))
+	for i, v := range found {
+		res[i] = s.ToProto(v)
+	}
+	return res, crud.NextPageToken(q, len(found)), nil
+}
+
+// Create message; id and timestamps of m are ignored. Returns created message with generated fields
+func (s *Service[T, M, K]) Create(ctx context.Context, m M) (M, error) {
+	var zero M
+	v, err := s.CRUD.Reset(s.FromProto(m))
+	if err != nil {
+		return zero, invalidArgument{err}
+	}
+	res, err := s.CRUD.Create(ctx, v)
+	if err != nil {
+		return zero, err
+	}
+	return s.ToProto(res), nil
+}
+
+// Update fields of m listed in mask (all non-zero fields if mask is empty) in transaction; m MUST have id set.
+// Returns updated message
+func (s *Service[T, M, K]) Update(ctx context.Context, m M, mask crud.FieldMask) (M, error) {
+	var zero M
+	v := s.FromProto(m)
+	if mask == nil {
+		mask = emptyMask{}
+	}
+	var res *T
+	err := s.CRUD.RunInTransaction(ctx, func(ctx context.Context, tx crud.GenericCRUD[T]) error {
+		if _, err := tx.GetByID(ctx, v); err != nil {
+			return err
+		}
+		if err := tx.UpdateMask(ctx, v, mask); err != nil {
+			return err
+		}
+		var err error
+		res, err = tx.GetByID(ctx, v)
+		return err
+	})
+	if err != nil {
+		return zero, err
+	}
+	return s.ToProto(res), nil
+}
+
+// Delete message by id
+func (s *Service[T, M, K]) Delete(ctx context.Context, req IDRequest[K]) error {
+	v, err := s.CRUD.FromID(req.GetId())
+	if err != nil {
+		return invalidArgument{err}
+	}
+	if _, err = s.CRUD.GetByID(ctx, v); err != nil {
+		return err
+	}
+	return s.CRUD.Delete(ctx, v)
+}
+
+// emptyMask replaces nil mask
+type emptyMask struct{}
+
+func (emptyMask) GetPaths() []string { return nil }
+
+// Code is gRPC status code; values match google.golang.org/grpc/codes
+type Code uint32
+
+const (
+	OK                 Code = 0
+	Canceled           Code = 1
+	InvalidArgument    Code = 3
+	DeadlineExceeded   Code = 4
+	NotFound           Code = 5
+	AlreadyExists      Code = 6
+	PermissionDenied   Code = 7
+	FailedPrecondition Code = 9
+	Internal           Code = 13
+	Unavailable        Code = 14
+)
+
+// CodeOf maps errors returned by Service and crud.GenericCRUD to gRPC status codes
+func CodeOf(err error) Code {
+	var (
+		ia     invalidArgument
+		enum   crud.InvalidEnumValueError
+		unique crud.UniqueConflictError
+	)
+	switch {
+	case err == nil:
+		return OK
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return NotFound
+	case errors.As(err, &unique):
+		return AlreadyExists
+	case errors.Is(err, crud.ForbiddenFieldError):
+		return PermissionDenied
+	case errors.Is(err, crud.MultipleResultsError), errors.Is(err, crud.StateConflictError), errors.Is(err, crud.TooManyRowsError):
+		return FailedPrecondition
+	case errors.Is(err, crud.InvalidFilterError), errors.As(err, &ia), errors.As(err, &enum):
+		return InvalidArgument
+	case errors.Is(err, crud.CircuitOpenError):
+		return Unavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	default:
+		return Internal
+	}
+}
+
+// invalidArgument wraps client errors
+type invalidArgument struct{ error }
+
+func (e invalidArgument) Unwrap() error { return e.error }
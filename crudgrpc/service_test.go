@@ -0,0 +1,97 @@
+package crudgrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/nullc4t/gorm-cruder/crud"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type (
+	user struct {
+		crud.Model
+		Name string
+		Age  int
+	}
+
+	// pbUser, getRequest, listRequest and mask mimic generated messages
+	pbUser struct {
+		Id   uint32
+		Name string
+		Age  int32
+	}
+
+	getRequest struct{ Id uint32 }
+
+	listRequest struct {
+		Filter, OrderBy, PageToken string
+		PageSize                   int32
+	}
+
+	mask []string
+)
+
+func (r getRequest) GetId() uint32         { return r.Id }
+func (r listRequest) GetFilter() string    { return r.Filter }
+func (r listRequest) GetOrderBy() string   { return r.OrderBy }
+func (r listRequest) GetPageSize() int32   { return r.PageSize }
+func (r listRequest) GetPageToken() string { return r.PageToken }
+func (m mask) GetPaths() []string          { return m }
+
+func TestService(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:crudgrpc?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&user{}))
+	svc := New[user, *pbUser, uint32](crud.New[user](db),
+		func(u *user) *pbUser { return &pbUser{Id: u.ID, Name: u.Name, Age: int32(u.Age)} },
+		func(m *pbUser) user { return user{Model: crud.Model{ID: m.Id}, Name: m.Name, Age: int(m.Age)} },
+	)
+	ctx := context.TODO()
+
+	for i := 1; i <= 3; i++ {
+		// client id is ignored
+		created, err := svc.Create(ctx, &pbUser{Id: 100, Name: fmt.Sprint("user", i), Age: int32(20 + i)})
+		require.NoError(t, err)
+		require.EqualValues(t, i, created.Id)
+	}
+
+	page, next, err := svc.List(ctx, listRequest{Filter: "age > 21", OrderBy: "age desc", PageSize: 1})
+	require.NoError(t, err)
+	require.Equal(t, "user3", page[0].Name)
+	page, next, err = svc.List(ctx, listRequest{Filter: "age > 21", OrderBy: "age desc", PageSize: 1, PageToken: next})
+	require.NoError(t, err)
+	require.Equal(t, "user2", page[0].Name)
+	require.NotEmpty(t, next)
+
+	updated, err := svc.Update(ctx, &pbUser{Id: 1, Name: "renamed", Age: 99}, mask{"name"})
+	require.NoError(t, err)
+	require.Equal(t, &pbUser{Id: 1, Name: "renamed", Age: 21}, updated)
+	_, err = svc.Update(ctx, &pbUser{Id: 100, Name: "missing"}, mask{"name"})
+	require.Equal(t, NotFound, CodeOf(err))
+
+	require.NoError(t, svc.Delete(ctx, getRequest{Id: 1}))
+	_, err = svc.Get(ctx, getRequest{Id: 1})
+	require.Equal(t, NotFound, CodeOf(err))
+	_, _, err = svc.List(ctx, listRequest{Filter: "unknown = 1"})
+	require.Equal(t, InvalidArgument, CodeOf(err))
+	require.Equal(t, OK, CodeOf(nil))
+}
+
+func TestCodeOf(t *testing.T) {
+	for err, code := range map[error]Code{
+		fmt.Errorf("%w: age", crud.ForbiddenFieldError):                         PermissionDenied,
+		fmt.Errorf("create: %w", crud.UniqueConflictError{Constraint: "email"}): AlreadyExists,
+		crud.TooManyRowsError:                          FailedPrecondition,
+		crud.StateConflictError:                        FailedPrecondition,
+		fmt.Errorf("query: %w", crud.CircuitOpenError): Unavailable,
+		context.DeadlineExceeded:                       DeadlineExceeded,
+		errors.New("boom"):                             Internal,
+	} {
+		require.Equal(t, code, CodeOf(err), err.Error())
+	}
+}
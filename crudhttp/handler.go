@@ -0,0 +1,304 @@
+// Package crudhttp exposes crud.GenericCRUD as REST handlers with JSON bodies
+package crudhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nullc4t/gorm-cruder/crud"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Handler implements REST semantics for model T, with paths relative to Prefix:
+//
+//	GET    /      List with crud.GenericCRUD.ParseQuery filtering; sets X-Total-Count
+//	POST   /      Create
+//	GET    /{id}  Get
+//	PUT    /{id}  Update non-zero fields
+//	PATCH  /{id}  Update non-zero fields
+//	DELETE /{id}  Delete
+//
+// Primary key and timestamps of bodies are ignored: they are generated on Create and taken from path on Update.
+// Bodies are limited to MaxBodyBytes and their associations are ignored unless Associations is set
+type Handler[T crud.GORMModel] struct {
+	CRUD crud.GenericCRUD[T]
+	// Allowed columns for filtering and sorting; all columns if empty
+	Allowed []string
+	// Prefix is path handler is mounted at, e.g. "/users"; empty if prefix is stripped, e.g. with http.StripPrefix
+	Prefix string
+	// ID extracts primary key from request; path segment after Prefix by default.
+	// Use it to plug router params, e.g. chi.URLParam(r, "id")
+	ID func(r *http.Request) string
+	// MaxBodyBytes limits size of request bodies; DefaultMaxBodyBytes if zero
+	MaxBodyBytes int64
+	// Associations enables writing associations nested in bodies; they are omitted by default
+	Associations bool
+}
+
+// DefaultMaxBodyBytes is default Handler.MaxBodyBytes
+var DefaultMaxBodyBytes int64 = 1 << 20
+
+// New is a constructor
+func New[T crud.GORMModel](c crud.GenericCRUD[T], allowed ...string) *Handler[T] {
+	return &Handler[T]{CRUD: c, Allowed: allowed}
+}
+
+// ServeHTTP routes request by method and presence of id; paths other than Prefix and Prefix/{id} are not found
+func (h *Handler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.id(r)
+	if !ok {
+		JSON(w, http.StatusNotFound, map[string]string{"error": "unknown path " + r.URL.Path})
+		return
+	}
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		h.List(w, r)
+	case id == "" && r.Method == http.MethodPost:
+		h.Create(w, r)
+	case id != "" && r.Method == http.MethodGet:
+		h.Get(w, r)
+	case id != "" && (r.Method == http.MethodPut || r.Method == http.MethodPatch):
+		h.Update(w, r)
+	case id != "" && r.Method == http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// List models
+func (h *Handler[T]) List(w http.ResponseWriter, r *http.Request) {
+	q, err := h.CRUD.ParseQuery(r.URL.Query(), h.Allowed...)
+	if err != nil {
+		Error(w, err)
+		return
+	}
+	total, err := h.CRUD.Count(r.Context(), q)
+	if err != nil {
+		Error(w, err)
+		return
+	}
+	res, err := h.CRUD.SmartQuery(r.Context(), q)
+	if err != nil {
+		Error(w, err)
+		return
+	}
+	if res == nil {
+		res = []*T{}
+	}
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	JSON(w, http.StatusOK, res)
+}
+
+// Get model by id
+func (h *Handler[T]) Get(w http.ResponseWriter, r *http.Request) {
+	id, _ := h.id(r)
+	v, err := h.CRUD.FromID(id)
+	if err != nil {
+		Error(w, badRequest{err})
+		return
+	}
+	res, err := h.CRUD.GetByID(r.Context(), v)
+	if err != nil {
+		Error(w, err)
+		return
+	}
+	JSON(w, http.StatusOK, res)
+}
+
+// Create model from JSON body
+func (h *Handler[T]) Create(w http.ResponseWriter, r *http.Request) {
+	v, err := h.decode(w, r)
+	if err != nil {
+		Error(w, badRequest{err})
+		return
+	}
+	if v, err = h.CRUD.Reset(v); err != nil {
+		Error(w, badRequest{err})
+		return
+	}
+	res, err := h.CRUD.Create(r.Context(), v, h.omit()...)
+	if err != nil {
+		Error(w, err)
+		return
+	}
+	JSON(w, http.StatusCreated, res)
+}
+
+// Update model by id from JSON body; responds with updated model
+func (h *Handler[T]) Update(w http.ResponseWriter, r *http.Request) {
+	pk, _ := h.id(r)
+	id, err := h.CRUD.FromID(pk)
+	if err != nil {
+		Error(w, badRequest{err})
+		return
+	}
+	if _, err = h.CRUD.GetByID(r.Context(), id); err != nil {
+		Error(w, err)
+		return
+	}
+	v, err := h.decode(w, r)
+	if err != nil {
+		Error(w, badRequest{err})
+		return
+	}
+	// primary key from URL wins over body
+	if v, err = h.CRUD.Reset(v); err == nil {
+		v, err = withID(h.CRUD, v, pk)
+	}
+	if err != nil {
+		Error(w, badRequest{err})
+		return
+	}
+	if err = h.CRUD.Update(r.Context(), v, h.omit()...); err != nil {
+		Error(w, err)
+		return
+	}
+	res, err := h.CRUD.GetByID(r.Context(), id)
+	if err != nil {
+		Error(w, err)
+		return
+	}
+	JSON(w, http.StatusOK, res)
+}
+
+// Delete model by id
+func (h *Handler[T]) Delete(w http.ResponseWriter, r *http.Request) {
+	id, _ := h.id(r)
+	v, err := h.CRUD.FromID(id)
+	if err != nil {
+		Error(w, badRequest{err})
+		return
+	}
+	if _, err = h.CRUD.GetByID(r.Context(), v); err != nil {
+		Error(w, err)
+		return
+	}
+	if err = h.CRUD.Delete(r.Context(), v); err != nil {
+		Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// JSON writes v with status code
+func JSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// Error writes JSON error with status code derived from err; server errors have generic message of status,
+// so database errors don't reach clients
+func Error(w http.ResponseWriter, err error) {
+	code := Status(err)
+	msg := err.Error()
+	if code >= http.StatusInternalServerError {
+		msg = http.StatusText(code)
+	}
+	JSON(w, code, map[string]string{"error": msg})
+}
+
+// Status maps errors returned by crud.GenericCRUD to HTTP status codes
+func Status(err error) int {
+	var (
+		br     badRequest
+		enum   crud.InvalidEnumValueError
+		unique crud.UniqueConflictError
+		large  *http.MaxBytesError
+	)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, crud.MultipleResultsError), errors.Is(err, crud.StateConflictError), errors.As(err, &unique):
+		return http.StatusConflict
+	case errors.Is(err, crud.ForbiddenFieldError):
+		return http.StatusForbidden
+	case errors.Is(err, crud.TooManyRowsError):
+		return http.StatusUnprocessableEntity
+	case errors.As(err, &large):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, crud.InvalidFilterError), errors.As(err, &br), errors.As(err, &enum):
+		return http.StatusBadRequest
+	case errors.Is(err, crud.CircuitOpenError):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// badRequest wraps client errors
+type badRequest struct{ error }
+
+func (e badRequest) Unwrap() error { return e.error }
+
+// decode reads JSON body of at most MaxBodyBytes
+func (h *Handler[T]) decode(w http.ResponseWriter, r *http.Request) (T, error) {
+	var v T
+	limit := h.MaxBodyBytes
+	if limit <= 0 {
+		limit = DefaultMaxBodyBytes
+	}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit)).Decode(&v)
+	return v, err
+}
+
+// omit returns columns omitted from writes of bodies
+func (h *Handler[T]) omit() []string {
+	if h.Associations {
+		return nil
+	}
+	return []string{clause.Associations}
+}
+
+// id returns primary key of request using ID if set; ok is false if path is not Prefix or Prefix/{id}
+func (h *Handler[T]) id(r *http.Request) (string, bool) {
+	if h.ID != nil {
+		return h.ID(r), true
+	}
+	return relativeID(r.URL.Path, h.Prefix)
+}
+
+// relativeID returns segment of path after prefix, empty for prefix itself
+func relativeID(path, prefix string) (string, bool) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if !strings.HasPrefix(path, prefix) {
+		return "", false
+	}
+	rel := path[len(prefix):]
+	if rel != "" && rel[0] != '/' {
+		return "", false
+	}
+	rel = strings.Trim(rel, "/")
+	if strings.Contains(rel, "/") {
+		return "", false
+	}
+	return rel, true
+}
+
+// withID sets primary key of v to id
+func withID[T crud.GORMModel](c crud.GenericCRUD[T], v T, id string) (T, error) {
+	pk, err := c.FromID(id)
+	if err != nil {
+		return v, err
+	}
+	return c.SetID(v, pk.PrimaryKey())
+}
+
+// Stats wraps next collecting crud.RequestStats of GenericCRUD calls made with request context and passes them to
+// report after next returns, e.g. to log requests doing too many queries
+func Stats(next http.Handler, report func(r *http.Request, stats crud.RequestStats)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(crud.ContextWithStats(r.Context()))
+		next.ServeHTTP(w, r)
+		stats, _ := crud.StatsFromContext(r.Context())
+		report(r, stats)
+	})
+}
@@ -0,0 +1,164 @@
+package crudhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/nullc4t/gorm-cruder/crud"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestStatus(t *testing.T) {
+	for err, code := range map[error]int{
+		gorm.ErrRecordNotFound:                                                  http.StatusNotFound,
+		fmt.Errorf("db error: %w", crud.MultipleResultsError):                   http.StatusConflict,
+		fmt.Errorf("%w: x", crud.InvalidFilterError):                            http.StatusBadRequest,
+		badRequest{errors.New("bad json")}:                                      http.StatusBadRequest,
+		fmt.Errorf("%w: age", crud.ForbiddenFieldError):                         http.StatusForbidden,
+		fmt.Errorf("create: %w", crud.UniqueConflictError{Constraint: "email"}): http.StatusConflict,
+		crud.StateConflictError:                                                 http.StatusConflict,
+		crud.TooManyRowsError:                                                   http.StatusUnprocessableEntity,
+		fmt.Errorf("query: %w", crud.CircuitOpenError):                          http.StatusServiceUnavailable,
+		fmt.Errorf("query: %w", context.DeadlineExceeded):                       http.StatusGatewayTimeout,
+		errors.New("boom"):                                                      http.StatusInternalServerError,
+	} {
+		require.Equal(t, code, Status(err), err.Error())
+	}
+}
+
+func TestError(t *testing.T) {
+	w := httptest.NewRecorder()
+	Error(w, errors.New(`pq: relation "users" does not exist`))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.JSONEq(t, `{"error":"Internal Server Error"}`, w.Body.String())
+	w = httptest.NewRecorder()
+	Error(w, fmt.Errorf("%w: age", crud.ForbiddenFieldError))
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.JSONEq(t, `{"error":"forbidden field: age"}`, w.Body.String())
+}
+
+func TestRelativeID(t *testing.T) {
+	for _, c := range []struct {
+		path, prefix, id string
+		ok               bool
+	}{
+		{"/", "", "", true},
+		{"", "", "", true},
+		{"/42", "", "42", true},
+		{"/42/", "", "42", true},
+		{"/users", "/users", "", true},
+		{"/users/", "/users", "", true},
+		{"/users/42", "/users/", "42", true},
+		{"/users/42/", "/users", "42", true},
+		{"/users/42/pets", "/users", "", false},
+		{"/usersx", "/users", "", false},
+		{"/pets/42", "/users", "", false},
+	} {
+		id, ok := relativeID(c.path, c.prefix)
+		require.Equal(t, c.id, id, c.path)
+		require.Equal(t, c.ok, ok, c.path)
+	}
+}
+
+type (
+	user struct {
+		crud.Model
+		Name string
+		Pets []pet
+	}
+
+	pet struct {
+		ID     uint
+		UserID uint
+		Name   string
+	}
+)
+
+func (u user) PrimaryKey() any {
+	return u.ID
+}
+
+func TestHandler(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:crudhttp?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&user{}, &pet{}))
+	h := New(crud.New[user](db))
+	h.Prefix = "/users"
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) user {
+		var u user
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &u), w.Body.String())
+		return u
+	}
+
+	w := serve(http.MethodPost, "/users", `{"ID":42,"Name":"ann","CreatedAt":"2001-01-01T00:00:00Z"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	created := decode(w)
+	require.EqualValues(t, 1, created.ID)
+	require.Equal(t, "ann", created.Name)
+	require.WithinDuration(t, time.Now(), created.CreatedAt, time.Minute)
+
+	w = serve(http.MethodGet, "/users/", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "1", w.Header().Get("X-Total-Count"))
+	w = serve(http.MethodGet, "/users/1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "ann", decode(w).Name)
+
+	w = serve(http.MethodPut, "/users/1", `{"ID":42,"Name":"bob","CreatedAt":"2001-01-01T00:00:00Z"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	updated := decode(w)
+	require.EqualValues(t, 1, updated.ID)
+	require.Equal(t, "bob", updated.Name)
+	require.True(t, created.CreatedAt.Equal(updated.CreatedAt))
+	require.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/users/42", "").Code)
+
+	require.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPost, "/users/1", "{}").Code)
+	require.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodDelete, "/users", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/users/1/pets", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/pets/1", "").Code)
+	require.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/users", "{").Code)
+
+	require.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/users/1", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/users/1", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/users/1", "").Code)
+
+	pets := func() (n int64) {
+		require.NoError(t, db.Model(&pet{}).Count(&n).Error)
+		return n
+	}
+	require.Equal(t, http.StatusCreated, serve(http.MethodPost, "/users", `{"Name":"cat","Pets":[{"Name":"tom"}]}`).Code)
+	require.Zero(t, pets())
+	h.Associations = true
+	require.Equal(t, http.StatusCreated, serve(http.MethodPost, "/users", `{"Name":"cat","Pets":[{"Name":"tom"}]}`).Code)
+	require.EqualValues(t, 1, pets())
+
+	h.MaxBodyBytes = 16
+	require.Equal(t, http.StatusRequestEntityTooLarge, serve(http.MethodPost, "/users", `{"Name":"a very long name"}`).Code)
+}
+
+func TestStats(t *testing.T) {
+	var got []int
+	h := Stats(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := crud.StatsFromContext(r.Context())
+		require.True(t, ok)
+	}), func(r *http.Request, stats crud.RequestStats) {
+		got = append(got, stats.Queries)
+	})
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, []int{0}, got)
+}
@@ -0,0 +1,251 @@
+/*
+Package crudopenapi generates OpenAPI 3 components for models of crud.GenericCRUD and query parameters
+understood by crud.GenericCRUD.ParseQuery, so REST handlers (e.g. crudhttp) can ship accurate API docs:
+
+	spec := crudopenapi.New()
+	_ = crudopenapi.Add(spec, users, "name", "age")
+	doc := map[string]any{
+		"openapi":    "3.0.3",
+		"components": spec.Components(),
+		"paths": map[string]any{
+			"/users": map[string]any{"get": map[string]any{"parameters": spec.Parameters("User")}},
+		},
+	}
+*/
+package crudopenapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nullc4t/gorm-cruder/crud"
+)
+
+type (
+	// Schema is OpenAPI schema object
+	Schema struct {
+		Ref         string             `json:"$ref,omitempty"`
+		Type        string             `json:"type,omitempty"`
+		Format      string             `json:"format,omitempty"`
+		Description string             `json:"description,omitempty"`
+		Nullable    bool               `json:"nullable,omitempty"`
+		ReadOnly    bool               `json:"readOnly,omitempty"`
+		Minimum     *float64           `json:"minimum,omitempty"`
+		Maximum     *float64           `json:"maximum,omitempty"`
+		Properties  map[string]*Schema `json:"properties,omitempty"`
+		Required    []string           `json:"required,omitempty"`
+	}
+
+	// Parameter is OpenAPI parameter object
+	Parameter struct {
+		Name        string  `json:"name"`
+		In          string  `json:"in"`
+		Description string  `json:"description,omitempty"`
+		Required    bool    `json:"required,omitempty"`
+		Schema      *Schema `json:"schema"`
+	}
+
+	// Components is OpenAPI components object
+	Components struct {
+		Schemas    map[string]*Schema   `json:"schemas"`
+		Parameters map[string]Parameter `json:"parameters,omitempty"`
+	}
+
+	// Spec collects schemas and list parameters of models
+	Spec struct {
+		mu     sync.RWMutex
+		models map[string]*Schema
+		params map[string][]Parameter
+	}
+)
+
+// New is a constructor
+func New() *Spec {
+	return &Spec{models: make(map[string]*Schema), params: make(map[string][]Parameter)}
+}
+
+// Register adds T registered in registry; see Add
+func Register[T crud.GORMModel](s *Spec, registry *crud.Registry, allowed ...string) error {
+	c, ok := crud.Get[T](registry)
+	if !ok {
+		return fmt.Errorf("crudopenapi: %T is not registered", *new(T))
+	}
+	return Add(s, c, allowed...)
+}
+
+// Add schema of T, named by Go type, and its list parameters; allowed columns are filterable and sortable
+// as in crud.GenericCRUD.ParseQuery. Adding T again replaces it
+func Add[T crud.GORMModel](s *Spec, c crud.GenericCRUD[T], allowed ...string) error {
+	ms, err := c.Schema()
+	if err != nil {
+		return err
+	}
+	schema, params := modelSchema(ms, reflect.TypeOf((*T)(nil)).Elem()), listParameters(ms, allowed)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.models[ms.Name] = schema
+	s.params[ms.Name] = params
+	return nil
+}
+
+// Ref returns schema referencing component of model
+func Ref(model string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + model}
+}
+
+// Components returns model schemas and shared pagination parameters
+func (s *Spec) Components() Components {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := Components{Schemas: make(map[string]*Schema, len(s.models)), Parameters: map[string]Parameter{}}
+	for name, schema := range s.models {
+		res.Schemas[name] = schema
+	}
+	for _, p := range pageParameters() {
+		res.Parameters[p.Name] = p
+	}
+	return res
+}
+
+// Parameters returns query parameters of listing model: filters per column and operator, order_by, page and page_size
+func (s *Spec) Parameters(model string) []Parameter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Parameter(nil), s.params[model]...)
+}
+
+// modelSchema returns object schema of model; properties are named as encoding/json names fields of t
+func modelSchema(ms crud.ModelSchema, t reflect.Type) *Schema {
+	res := &Schema{Type: "object", Properties: make(map[string]*Schema, len(ms.Columns))}
+	for _, c := range ms.Columns {
+		name, ok := jsonName(t, c.Field)
+		if !ok {
+			continue
+		}
+		p := columnSchema(c)
+		p.ReadOnly = c.PrimaryKey
+		res.Properties[name] = p
+		if c.NotNull && !c.PrimaryKey {
+			res.Required = append(res.Required, name)
+		}
+	}
+	sort.Strings(res.Required)
+	return res
+}
+
+// columnSchema returns schema of column values
+func columnSchema(c crud.ColumnSchema) *Schema {
+	res := &Schema{Nullable: strings.HasPrefix(c.GoType, "*") || strings.HasPrefix(c.GoType, "sql.Null") || c.GoType == "gorm.DeletedAt"}
+	switch c.DataType {
+	case "bool":
+		res.Type = "boolean"
+	case "int", "uint":
+		res.Type, res.Format = "integer", "int64"
+		if strings.TrimPrefix(c.GoType, "*") == "int32" {
+			res.Format = "int32"
+		}
+	case "float":
+		res.Type = "number"
+	case "string":
+		res.Type = "string"
+	case "time":
+		res.Type, res.Format = "string", "date-time"
+	case "bytes":
+		res.Type, res.Format = "string", "byte"
+	}
+	return res
+}
+
+// jsonName returns name of field in JSON encoding of t; false if field is not encoded
+func jsonName(t reflect.Type, field string) (string, bool) {
+	f, ok := t.FieldByName(field)
+	if !ok {
+		return field, true
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return field, true
+}
+
+// operators of ParseQuery; like operators are documented for string columns only
+var (
+	operators     = []string{"ne", "gt", "gte", "lt", "lte"}
+	textOperators = []string{"like", "ilike", "nlike"}
+)
+
+// listParameters returns parameters of ParseQuery for model
+func listParameters(ms crud.ModelSchema, allowed []string) []Parameter {
+	var res []Parameter
+	for _, c := range columns(ms, allowed, func(c crud.ColumnSchema) bool { return c.Filterable }) {
+		value := columnSchema(c)
+		value.Nullable = false
+		res = append(res, Parameter{Name: c.Name, In: "query", Description: c.Name + " equals value", Schema: value})
+		for _, op := range operators {
+			res = append(res, Parameter{Name: c.Name + "__" + op, In: "query", Schema: value})
+		}
+		if c.DataType == "string" {
+			for _, op := range textOperators {
+				res = append(res, Parameter{Name: c.Name + "__" + op, In: "query", Description: "substring match", Schema: value})
+			}
+		}
+		res = append(res,
+			Parameter{Name: c.Name + "__in", In: "query", Description: "comma-separated values", Schema: &Schema{Type: "string"}},
+			Parameter{Name: c.Name + "__between", In: "query", Description: "from,to", Schema: &Schema{Type: "string"}},
+		)
+	}
+	var sortable []string
+	for _, c := range columns(ms, allowed, func(c crud.ColumnSchema) bool { return c.Sortable }) {
+		sortable = append(sortable, c.Name)
+	}
+	res = append(res, Parameter{
+		Name:        "order_by",
+		In:          "query",
+		Description: "comma-separated columns, \"-\" prefix for descending; one of " + strings.Join(sortable, ", "),
+		Schema:      &Schema{Type: "string"},
+	})
+	return append(res, pageParameters()...)
+}
+
+// columns returns allowed columns, defaulting to tagged ones or all columns if none is tagged
+func columns(ms crud.ModelSchema, allowed []string, tagged func(crud.ColumnSchema) bool) []crud.ColumnSchema {
+	var res []crud.ColumnSchema
+	if len(allowed) > 0 {
+		for _, name := range allowed {
+			if c, ok := ms.Column(name); ok {
+				res = append(res, c)
+			}
+		}
+		return res
+	}
+	for _, c := range ms.Columns {
+		if tagged(c) {
+			res = append(res, c)
+		}
+	}
+	if len(res) == 0 {
+		return ms.Columns
+	}
+	return res
+}
+
+// pageParameters returns pagination parameters of ParseQuery
+func pageParameters() []Parameter {
+	one, maxSize := 1.0, float64(crud.MaxPageSize)
+	return []Parameter{
+		{Name: "page", In: "query", Description: "page number, starting at 1", Schema: &Schema{Type: "integer", Minimum: &one}},
+		{
+			Name:        "page_size",
+			In:          "query",
+			Description: fmt.Sprintf("page size, %d by default", crud.DefaultPageSize),
+			Schema:      &Schema{Type: "integer", Minimum: &one, Maximum: &maxSize},
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package crudopenapi
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nullc4t/gorm-cruder/crud"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type user struct {
+	crud.Model
+	Name     string `json:"name" crud:"filterable,sortable" gorm:"not null"`
+	Age      *int   `json:"age,omitempty" crud:"filterable"`
+	Password string `json:"-"`
+	Born     time.Time
+}
+
+func (u user) PrimaryKey() any {
+	return u.ID
+}
+
+func TestSpec(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	registry := crud.NewRegistry(db, crud.Config{})
+	crud.Register[user](registry)
+	spec := New()
+	require.NoError(t, Register[user](spec, registry))
+
+	c := spec.Components()
+	s := c.Schemas["user"]
+	require.NotNil(t, s)
+	require.Equal(t, &Schema{Type: "integer", Format: "int64", ReadOnly: true}, s.Properties["ID"])
+	require.Equal(t, &Schema{Type: "string"}, s.Properties["name"])
+	require.Equal(t, &Schema{Type: "integer", Format: "int64", Nullable: true}, s.Properties["age"])
+	require.Equal(t, &Schema{Type: "string", Format: "date-time"}, s.Properties["Born"])
+	require.Equal(t, &Schema{Type: "string", Format: "date-time", Nullable: true}, s.Properties["DeletedAt"])
+	require.NotContains(t, s.Properties, "Password")
+	require.Equal(t, []string{"name"}, s.Required)
+	require.Contains(t, c.Parameters, "page_size")
+
+	var names []string
+	for _, p := range spec.Parameters("user") {
+		names = append(names, p.Name)
+	}
+	require.Equal(t, []string{
+		"name", "name__ne", "name__gt", "name__gte", "name__lt", "name__lte", "name__like", "name__ilike", "name__nlike", "name__in", "name__between",
+		"age", "age__ne", "age__gt", "age__gte", "age__lt", "age__lte", "age__in", "age__between",
+		"order_by", "page", "page_size",
+	}, names)
+	require.Contains(t, spec.Parameters("user")[19].Description, "one of name")
+
+	_, err = json.Marshal(c)
+	require.NoError(t, err)
+	require.Equal(t, &Schema{Ref: "#/components/schemas/user"}, Ref("user"))
+	require.Error(t, Register[struct{ user }](spec, registry))
+}
@@ -0,0 +1,224 @@
+package crudtest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nullc4t/gorm-cruder/crud"
+)
+
+// Fields override generated values by Go field or column name; func(n int) any values are called with sequence
+// number of built instance, starting at 1, e.g. Fields{"email": func(n int) any { return fmt.Sprintf("u%d@x.io", n) }}
+type Fields map[string]any
+
+/*
+Factory builds random valid instances of T from crud.GenericCRUD.Schema and persists them with Create:
+
+	users := crudtest.NewFactory(crud.New[User](db).WithEnum("role", "admin", "member"), 1)
+	admin := users.Create(t, crudtest.Fields{"role": "admin"})
+	members := users.With(crudtest.Fields{"role": "member"}).CreateMany(t, 10)
+
+Strings are random letters within `size` of column, enum columns take registered values, numbers are small positive
+ones and times are within 30 days before crud.Config.Clock. Primary keys, timestamps and nullable columns are left zero
+unless column is not null or overridden; override foreign keys to reference existing rows. Factory is deterministic for seed and not safe for concurrent use
+*/
+type Factory[T crud.GORMModel] struct {
+	g      crud.GenericCRUD[T]
+	rand   *rand.Rand
+	fields Fields
+	seq    *int
+}
+
+// NewFactory is a constructor
+func NewFactory[T crud.GORMModel](g crud.GenericCRUD[T], seed int64) *Factory[T] {
+	return &Factory[T]{g: g, rand: rand.New(rand.NewSource(seed)), seq: new(int)}
+}
+
+// With returns copy of f applying fields to every instance before overrides of Build; sequence is shared with f
+func (f *Factory[T]) With(fields Fields) *Factory[T] {
+	res := *f
+	res.fields = make(Fields, len(f.fields)+len(fields))
+	for k, v := range f.fields {
+		res.fields[k] = v
+	}
+	for k, v := range fields {
+		res.fields[k] = v
+	}
+	return &res
+}
+
+// Build returns new instance without persisting it; fails t on unknown override
+func (f *Factory[T]) Build(t testing.TB, overrides ...Fields) T {
+	t.Helper()
+	v, err := f.build(overrides)
+	if err != nil {
+		t.Fatalf("crudtest: %v", err)
+	}
+	return v
+}
+
+// Create builds instance and persists it with crud.GenericCRUD.Create; fails t on error
+func (f *Factory[T]) Create(t testing.TB, overrides ...Fields) T {
+	t.Helper()
+	v, err := f.build(overrides)
+	if err != nil {
+		t.Fatalf("crudtest: %v", err)
+	}
+	res, err := f.g.Create(context.Background(), v)
+	if err != nil {
+		t.Fatalf("crudtest: create %T: %v", v, err)
+	}
+	return *res
+}
+
+// CreateMany creates n instances with the same overrides
+func (f *Factory[T]) CreateMany(t testing.TB, n int, overrides ...Fields) []T {
+	t.Helper()
+	res := make([]T, n)
+	for i := range res {
+		res[i] = f.Create(t, overrides...)
+	}
+	return res
+}
+
+func (f *Factory[T]) build(overrides []Fields) (T, error) {
+	var v T
+	s, err := f.g.Schema()
+	if err != nil {
+		return v, err
+	}
+	*f.seq++
+	rv := reflect.ValueOf(&v).Elem()
+	for _, c := range s.Columns {
+		fv := rv.FieldByName(c.Field)
+		if !fv.IsValid() || !fv.CanSet() || c.PrimaryKey || !c.NotNull && (c.DataType == "time" || nullable(c)) {
+			continue
+		}
+		value := f.value(c)
+		if value == nil {
+			continue
+		}
+		if err = assign(fv, value); err != nil {
+			return v, fmt.Errorf("column %s: %w", c.Name, err)
+		}
+	}
+	for _, fields := range append([]Fields{f.fields}, overrides...) {
+		for name, value := range fields {
+			c, ok := column(s, name)
+			if !ok {
+				return v, fmt.Errorf("%s has no field %s", s.Name, name)
+			}
+			fv := rv.FieldByName(c.Field)
+			if !fv.IsValid() || !fv.CanSet() {
+				return v, fmt.Errorf("field %s of %s can't be set", name, s.Name)
+			}
+			if fn, ok := value.(func(int) any); ok {
+				value = fn(*f.seq)
+			}
+			if err = assign(fv, value); err != nil {
+				return v, fmt.Errorf("field %s: %w", name, err)
+			}
+		}
+	}
+	return v, nil
+}
+
+// value returns random value of column, nil for unsupported types
+func (f *Factory[T]) value(c crud.ColumnSchema) any {
+	if values := f.g.Enum(c.Name); len(values) > 0 {
+		return values[f.rand.Intn(len(values))]
+	}
+	switch c.DataType {
+	case "string":
+		n := 10
+		if c.Size > 0 && c.Size < n {
+			n = c.Size
+		}
+		return f.letters(n)
+	case "int", "uint":
+		return int64(1 + f.rand.Intn(100))
+	case "float":
+		return float64(f.rand.Intn(10000)) / 100
+	case "bool":
+		return f.rand.Intn(2) == 1
+	case "time":
+		now := time.Now()
+		if clock := f.g.Config().Clock; clock != nil {
+			now = clock.Now()
+		}
+		return now.UTC().Truncate(time.Second).Add(-time.Duration(f.rand.Intn(30*24)) * time.Hour)
+	case "bytes":
+		b := make([]byte, 8)
+		f.rand.Read(b)
+		return b
+	}
+	return nil
+}
+
+func (f *Factory[T]) letters(n int) string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz"
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteByte(alphabet[f.rand.Intn(len(alphabet))])
+	}
+	return b.String()
+}
+
+// nullable reports whether Go type of column can hold NULL
+func nullable(c crud.ColumnSchema) bool {
+	return strings.HasPrefix(c.GoType, "*") || strings.HasPrefix(c.GoType, "sql.Null") || c.GoType == "gorm.DeletedAt"
+}
+
+// column looks up column by Go field or column name
+func column(s crud.ModelSchema, name string) (crud.ColumnSchema, bool) {
+	for _, c := range s.Columns {
+		if c.Field == name || c.Name == name {
+			return c, true
+		}
+	}
+	return crud.ColumnSchema{}, false
+}
+
+// assign sets fv to value, converting it or scanning it into sql.Scanner and allocating pointers
+func assign(fv reflect.Value, value any) error {
+	if value == nil {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+	if scanner, ok := fv.Addr().Interface().(sql.Scanner); ok {
+		if _, isValue := value.(sql.Scanner); !isValue {
+			return scanner.Scan(value)
+		}
+	}
+	if fv.Kind() == reflect.Pointer {
+		rv := reflect.ValueOf(value)
+		if rv.Type().AssignableTo(fv.Type()) {
+			fv.Set(rv)
+			return nil
+		}
+		elem := reflect.New(fv.Type().Elem())
+		if err := assign(elem.Elem(), value); err != nil {
+			return err
+		}
+		fv.Set(elem)
+		return nil
+	}
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.Type().AssignableTo(fv.Type()):
+		fv.Set(rv)
+	case fv.Kind() == reflect.String:
+		fv.SetString(fmt.Sprint(value))
+	case rv.CanConvert(fv.Type()) && rv.Kind() != reflect.String:
+		fv.Set(rv.Convert(fv.Type()))
+	default:
+		return fmt.Errorf("can't assign %T to %s", value, fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package crudtest
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/nullc4t/gorm-cruder/crud"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type item struct {
+	crud.Model
+	Code    string `gorm:"size:4;not null"`
+	Kind    string
+	Price   float64
+	Qty     int16
+	Active  bool
+	Note    *string
+	Score   sql.NullInt32 `gorm:"not null"`
+	Expires time.Time     `gorm:"not null"`
+	OwnerID *uint
+}
+
+func (i item) PrimaryKey() any {
+	return i.ID
+}
+
+func TestFactory(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:factory?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&item{}))
+	clock := crud.FixedClock(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	items := crud.New[item](db).WithEnum("kind", "book", "disc").WithClock(clock)
+
+	built := NewFactory(items, 1).Build(t)
+	require.Equal(t, built, NewFactory(items, 1).Build(t))
+	require.Zero(t, built.ID)
+	require.Len(t, built.Code, 4)
+	require.Contains(t, []string{"book", "disc"}, built.Kind)
+	require.Positive(t, built.Qty)
+	require.Nil(t, built.Note)
+	require.Nil(t, built.OwnerID)
+	require.True(t, built.Score.Valid)
+	require.True(t, built.Expires.Before(clock.Now()) || built.Expires.Equal(clock.Now()))
+	require.True(t, built.CreatedAt.IsZero())
+
+	f := NewFactory(items, 2).With(Fields{"Kind": "disc", "code": func(n int) any { return fmt.Sprintf("c%d", n) }})
+	note := "fragile"
+	v := f.Create(t, Fields{"note": note, "owner_id": 7, "score": 3})
+	require.NotZero(t, v.ID)
+	require.Equal(t, "disc", v.Kind)
+	require.Equal(t, "c1", v.Code)
+	require.Equal(t, &note, v.Note)
+	require.EqualValues(t, 7, *v.OwnerID)
+	require.Equal(t, sql.NullInt32{Int32: 3, Valid: true}, v.Score)
+	require.Equal(t, clock.Now(), v.CreatedAt)
+
+	many := f.CreateMany(t, 3)
+	require.Equal(t, "c4", many[2].Code)
+	require.Nil(t, f.Build(t, Fields{"note": nil}).Note)
+	var count int64
+	require.NoError(t, db.Model(&item{}).Count(&count).Error)
+	require.EqualValues(t, 4, count)
+
+	_, err = f.build([]Fields{{"missing": 1}})
+	require.EqualError(t, err, "item has no field missing")
+	_, err = f.build([]Fields{{"qty": "many"}})
+	require.EqualError(t, err, "field qty: can't assign string to int16")
+}
+
+type (
+	address struct {
+		City string
+	}
+
+	customer struct {
+		crud.Model
+		Name    string
+		Address address `gorm:"embedded;embeddedPrefix:addr_"`
+	}
+)
+
+func (c customer) PrimaryKey() any {
+	return c.ID
+}
+
+func TestFactoryUnsettableOverride(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:factory_embedded?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	f := NewFactory(crud.New[customer](db), 1)
+	_, err = f.build([]Fields{{"addr_city": "Oslo"}})
+	require.ErrorContains(t, err, "field addr_city of customer can't be set")
+	v, err := f.build([]Fields{{"name": "ann"}})
+	require.NoError(t, err)
+	require.Equal(t, "ann", v.Name)
+}
@@ -0,0 +1,123 @@
+/*
+Package crudtest helps downstream tests lock down SQL generated by crud.GenericCRUD.
+
+Golden captures statements of fn with crud.GenericCRUD.DryRun and compares their SQL (vars are not part
+of query shape) with testdata/<name>.sql; run tests with CRUDTEST_UPDATE=1 or -update to rewrite files:
+
+	func TestListActive(t *testing.T) {
+		stmts := crudtest.Golden(t, users, "list_active", func(ctx context.Context, users crud.GenericCRUD[User]) error {
+			_, err := users.SmartQuery(ctx, crud.Query{Equal: map[string]any{"active": true}})
+			return err
+		})
+		crudtest.AssertNoFullScan(t, stmts)
+	}
+
+Factory builds random model instances from schema of crud.GenericCRUD for test fixtures.
+*/
+package crudtest
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/nullc4t/gorm-cruder/crud"
+)
+
+var update = flag.Bool("update", false, "rewrite crudtest golden files")
+
+// GoldenDir is directory of golden files, relative to package under test
+var GoldenDir = "testdata"
+
+// Capture returns statements fn would execute with g; fails t on error of fn
+func Capture[T crud.GORMModel](t testing.TB, g crud.GenericCRUD[T], fn func(ctx context.Context, g crud.GenericCRUD[T]) error) []crud.Statement {
+	t.Helper()
+	stmts, err := g.DryRun(context.Background(), fn)
+	if err != nil {
+		t.Fatalf("crudtest: %v", err)
+	}
+	return stmts
+}
+
+// Golden captures statements of fn and compares them with golden file name; see AssertGolden
+func Golden[T crud.GORMModel](t testing.TB, g crud.GenericCRUD[T], name string, fn func(ctx context.Context, g crud.GenericCRUD[T]) error) []crud.Statement {
+	t.Helper()
+	stmts := Capture(t, g, fn)
+	AssertGolden(t, name, stmts)
+	return stmts
+}
+
+// AssertGolden compares SQL of stmts, one per line, with GoldenDir/name.sql; file is written instead
+// if CRUDTEST_UPDATE is set or tests run with -update
+func AssertGolden(t testing.TB, name string, stmts []crud.Statement) {
+	t.Helper()
+	path := filepath.Join(GoldenDir, name+".sql")
+	got := format(stmts)
+	if *update || os.Getenv("CRUDTEST_UPDATE") != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("crudtest: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("crudtest: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("crudtest: %v (run with CRUDTEST_UPDATE=1 to create golden file)", err)
+	}
+	if string(want) != got {
+		t.Errorf("crudtest: SQL differs from %s (run with CRUDTEST_UPDATE=1 to update)\nwant:\n%s\ngot:\n%s", path, want, got)
+	}
+}
+
+func format(stmts []crud.Statement) string {
+	var b strings.Builder
+	for _, s := range stmts {
+		b.WriteString(s.SQL)
+		b.WriteString(";\n")
+	}
+	return b.String()
+}
+
+var (
+	// softDelete matches soft-delete condition added by gorm, which does not narrow the scan
+	softDelete = regexp.MustCompile("(?i)(AND )?[`\"]?\\w+[`\"]?\\.[`\"]?deleted_at[`\"]? IS NULL( AND)?")
+	scanned    = regexp.MustCompile(`(?i)^\s*(SELECT|UPDATE|DELETE)\b`)
+	// filtered matches WHERE followed by a condition rather than next clause
+	filtered = regexp.MustCompile(`(?i)\bWHERE\s+(\S+)`)
+	limited  = regexp.MustCompile(`(?i)\bLIMIT\b`)
+	clauses  = map[string]bool{"GROUP": true, "ORDER": true, "LIMIT": true, "OFFSET": true, "RETURNING": true}
+)
+
+// FullScan reports whether s reads or writes whole table: SELECT, UPDATE or DELETE without conditions
+// besides soft delete; SELECT with LIMIT is not reported
+func FullScan(s crud.Statement) bool {
+	if !scanned.MatchString(s.SQL) {
+		return false
+	}
+	sql := softDelete.ReplaceAllString(s.SQL, "")
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(sql)), "SELECT") && limited.MatchString(sql) {
+		return false
+	}
+	for _, m := range filtered.FindAllStringSubmatch(sql, -1) {
+		if !clauses[strings.ToUpper(m[1])] {
+			return false
+		}
+	}
+	return true
+}
+
+// AssertNoFullScan fails t for every statement of stmts scanning whole table; see FullScan
+func AssertNoFullScan(t testing.TB, stmts []crud.Statement) {
+	t.Helper()
+	for _, s := range stmts {
+		if FullScan(s) {
+			t.Errorf("crudtest: full table scan: %s", s.SQL)
+		}
+	}
+}
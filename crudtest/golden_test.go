@@ -0,0 +1,76 @@
+package crudtest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/nullc4t/gorm-cruder/crud"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type user struct {
+	crud.Model
+	Name string
+}
+
+func (u user) PrimaryKey() any {
+	return u.ID
+}
+
+// recorder records failures instead of failing test
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, format)
+}
+
+func TestGolden(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:golden?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	users := crud.New[user](db)
+	GoldenDir = t.TempDir()
+	list := func(ctx context.Context, users crud.GenericCRUD[user]) error {
+		_, err := users.SmartQuery(ctx, crud.Query{Equal: map[string]any{"name": "alice"}})
+		return err
+	}
+
+	t.Setenv("CRUDTEST_UPDATE", "1")
+	Golden(t, users, "list", list)
+	data, err := os.ReadFile(filepath.Join(GoldenDir, "list.sql"))
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM `users` WHERE name = ? AND `users`.`deleted_at` IS NULL;\n", string(data))
+
+	t.Setenv("CRUDTEST_UPDATE", "")
+	stmts := Golden(t, users, "list", list)
+	AssertNoFullScan(t, stmts)
+
+	r := &recorder{TB: t}
+	Golden(r, users, "list", func(ctx context.Context, users crud.GenericCRUD[user]) error {
+		_, err := users.SmartQuery(ctx, crud.Query{})
+		return err
+	})
+	require.Len(t, r.errors, 1)
+}
+
+func TestFullScan(t *testing.T) {
+	for sql, full := range map[string]bool{
+		`SELECT * FROM "users" WHERE "users"."deleted_at" IS NULL`:                    true,
+		`SELECT * FROM "users" WHERE "users"."deleted_at" IS NULL ORDER BY "id" ASC`:  true,
+		`SELECT * FROM "users" WHERE "users"."deleted_at" IS NULL LIMIT 10`:           false,
+		`SELECT * FROM "users" WHERE "name" = $1 AND "users"."deleted_at" IS NULL`:    false,
+		"UPDATE `users` SET `name`=? WHERE `users`.`deleted_at` IS NULL AND `id` = ?": false,
+		`DELETE FROM "users"`:                      true,
+		`INSERT INTO "users" ("name") VALUES ($1)`: false,
+		`SELECT count(*) FROM "users" WHERE "users"."deleted_at" IS NULL GROUP BY "name"`: true,
+	} {
+		require.Equal(t, full, FullScan(crud.Statement{SQL: sql}), sql)
+	}
+}
@@ -0,0 +1,320 @@
+/*
+Package eventstore is an append-only event store with projections on top of *gorm.DB.
+
+Events of type E are JSON encoded into events table, ordered per stream by version and globally by position.
+Append locks the row of event_sequence table, so appends are serialized and positions become visible in order:
+
+	store := eventstore.New[OrderEvent](db)
+	version, err := store.Append(ctx, "order-1", eventstore.NoStream, OrderEvent{Placed: &Placed{Total: 100}})
+
+Projections materialize read models, usually with GenericCRUD; handler runs in transaction with checkpoint
+update, holding lock of checkpoint row, so GenericCRUD methods called with its ctx are applied together with it
+and concurrent runners of projection don't handle the same event twice:
+
+	runner := eventstore.NewProjection(store, "order_totals", func(ctx context.Context, r eventstore.Record[OrderEvent]) error {
+		_, err := totals.Create(ctx, Total{OrderID: r.StreamID, Total: r.Data.Placed.Total})
+		return err
+	})
+	go runner.RunEvery(ctx, time.Second, log.Println)
+*/
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/nullc4t/gorm-cruder/crud"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Expected stream versions for Append
+const (
+	// AnyVersion disables concurrency check
+	AnyVersion = -1
+	// NoStream expects stream to not exist
+	NoStream = 0
+)
+
+var (
+	// ConcurrencyError is returned by Append when stream version differs from expected
+	ConcurrencyError = errors.New("unexpected stream version")
+)
+
+type (
+	// Event is row of events table
+	Event struct {
+		// Position is global order of events
+		Position  uint64 `gorm:"primarykey"`
+		StreamID  string `gorm:"size:255;uniqueIndex:idx_events_stream_version"`
+		Version   int    `gorm:"uniqueIndex:idx_events_stream_version"`
+		Type      string `gorm:"size:255"`
+		Data      string
+		CreatedAt time.Time
+	}
+
+	// Record is decoded event
+	Record[E any] struct {
+		Position  uint64
+		StreamID  string
+		Version   int
+		Type      string
+		Data      E
+		CreatedAt time.Time
+	}
+
+	// Typer is implemented by events providing their type name; Go type name is used otherwise
+	Typer interface {
+		EventType() string
+	}
+
+	// Store of events of type E
+	Store[E any] struct {
+		db *gorm.DB
+	}
+
+	checkpoint struct {
+		Name     string `gorm:"primarykey;size:255"`
+		Position uint64
+	}
+
+	// sequence is the single row holding last event position
+	sequence struct {
+		ID       uint `gorm:"primarykey"`
+		Position uint64
+	}
+)
+
+func (checkpoint) TableName() string {
+	return "projection_checkpoints"
+}
+
+func (sequence) TableName() string {
+	return "event_sequence"
+}
+
+// New is a constructor
+func New[E any](db *gorm.DB) *Store[E] {
+	return &Store[E]{db: db}
+}
+
+// Migrate creates events, event sequence and projection checkpoint tables
+func (s *Store[E]) Migrate(ctx context.Context) error {
+	db := s.db.WithContext(ctx)
+	if err := db.AutoMigrate(&Event{}, &sequence{}, &checkpoint{}); err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var last uint64
+		if err := tx.Model(&Event{}).Select("COALESCE(MAX(position), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&sequence{ID: 1, Position: last}).Error
+	})
+}
+
+// Append events to stream if its current version is expected (or expected is AnyVersion); returns new stream version.
+// Runs in transaction of ctx, if any
+func (s *Store[E]) Append(ctx context.Context, streamID string, expected int, events ...E) (int, error) {
+	var version int
+	err := crud.Transaction(ctx, s.db, func(ctx context.Context) error {
+		tx, _ := crud.TxFromContext(ctx)
+		var seq sequence
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(&seq).Error; err != nil {
+			return err
+		}
+		if seq.ID == 0 {
+			return errors.New("event sequence not found; run Migrate")
+		}
+		err := tx.Model(&Event{}).Where("stream_id = ?", streamID).
+			Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+		if err != nil {
+			return err
+		}
+		if expected != AnyVersion && version != expected {
+			return fmt.Errorf("%w: stream %s is at version %d, expected %d", ConcurrencyError, streamID, version, expected)
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		rows := make([]Event, len(events))
+		for i, e := range events {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return fmt.Errorf("event %d: %w", i, err)
+			}
+			version++
+			seq.Position++
+			rows[i] = Event{Position: seq.Position, StreamID: streamID, Version: version, Type: eventType(e), Data: string(data)}
+		}
+		if err = tx.Create(&rows).Error; err != nil {
+			if isUniqueViolation(err) {
+				return fmt.Errorf("%w: stream %s was appended concurrently", ConcurrencyError, streamID)
+			}
+			return err
+		}
+		return tx.Model(&seq).Update("position", seq.Position).Error
+	})
+	return version, err
+}
+
+// ReadStream returns events of stream with version greater than after, oldest first
+func (s *Store[E]) ReadStream(ctx context.Context, streamID string, after int) ([]Record[E], error) {
+	var events []Event
+	err := s.conn(ctx).Where("stream_id = ? AND version > ?", streamID, after).Order("version").Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return decode[E](events)
+}
+
+// ReadAll returns up to limit events of all streams with position greater than after, in global order
+func (s *Store[E]) ReadAll(ctx context.Context, after uint64, limit int) ([]Record[E], error) {
+	var events []Event
+	err := s.conn(ctx).Where("position > ?", after).Order("position").Limit(limit).Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return decode[E](events)
+}
+
+// conn returns transaction from ctx or store's db
+func (s *Store[E]) conn(ctx context.Context) *gorm.DB {
+	if tx, ok := crud.TxFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	return s.db.WithContext(ctx)
+}
+
+func decode[E any](events []Event) ([]Record[E], error) {
+	res := make([]Record[E], len(events))
+	for i, e := range events {
+		res[i] = Record[E]{
+			Position:  e.Position,
+			StreamID:  e.StreamID,
+			Version:   e.Version,
+			Type:      e.Type,
+			CreatedAt: e.CreatedAt,
+		}
+		if err := json.Unmarshal([]byte(e.Data), &res[i].Data); err != nil {
+			return nil, fmt.Errorf("event %d: %w", e.Position, err)
+		}
+	}
+	return res, nil
+}
+
+func eventType(e any) string {
+	if t, ok := e.(Typer); ok {
+		return t.EventType()
+	}
+	return reflect.TypeOf(e).Name()
+}
+
+// isUniqueViolation reports whether err is unique constraint violation of supported dialects
+func isUniqueViolation(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unique") || strings.Contains(msg, "duplicate")
+}
+
+type (
+	// Handler applies event to projection
+	Handler[E any] func(ctx context.Context, r Record[E]) error
+
+	// Projection feeds events of Store to Handler in global order, tracking position in projection_checkpoints
+	Projection[E any] struct {
+		store   *Store[E]
+		name    string
+		handler Handler[E]
+		// BatchSize is number of events read at once; 100 if zero
+		BatchSize int
+	}
+)
+
+// NewProjection is a constructor; name identifies checkpoint
+func NewProjection[E any](store *Store[E], name string, handler Handler[E]) *Projection[E] {
+	return &Projection[E]{store: store, name: name, handler: handler}
+}
+
+// Position returns position of last event handled
+func (p *Projection[E]) Position(ctx context.Context) (uint64, error) {
+	var c checkpoint
+	err := p.store.conn(ctx).Where("name = ?", p.name).Limit(1).Find(&c).Error
+	return c.Position, err
+}
+
+// Run handles all events after checkpoint; each event is handled in transaction with checkpoint update, holding
+// lock of checkpoint row; events handled by concurrent runners meanwhile are skipped. Returns number of events handled
+func (p *Projection[E]) Run(ctx context.Context) (int, error) {
+	batch := p.BatchSize
+	if batch <= 0 {
+		batch = 100
+	}
+	handled := 0
+	for {
+		position, err := p.Position(ctx)
+		if err != nil {
+			return handled, err
+		}
+		records, err := p.store.ReadAll(ctx, position, batch)
+		if err != nil {
+			return handled, err
+		}
+		for _, r := range records {
+			skipped := false
+			err = crud.Transaction(ctx, p.store.db, func(ctx context.Context) error {
+				tx, _ := crud.TxFromContext(ctx)
+				c, err := p.lock(tx)
+				if err != nil {
+					return err
+				}
+				if skipped = c.Position >= r.Position; skipped {
+					return nil
+				}
+				if err := p.handler(ctx, r); err != nil {
+					return err
+				}
+				return tx.Model(&c).Update("position", r.Position).Error
+			})
+			if err != nil {
+				return handled, fmt.Errorf("projection %s, event %d: %w", p.name, r.Position, err)
+			}
+			if !skipped {
+				handled++
+			}
+		}
+		if len(records) < batch {
+			return handled, nil
+		}
+	}
+}
+
+// lock returns checkpoint row of p locked for update in tx, creating it if missing
+func (p *Projection[E]) lock(tx *gorm.DB) (checkpoint, error) {
+	c := checkpoint{Name: p.name}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&c).Error; err != nil {
+		return c, err
+	}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", p.name).Take(&c).Error
+	return c, err
+}
+
+// RunEvery runs Run every interval until ctx is done; errors are passed to onError if not nil
+func (p *Projection[E]) RunEvery(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := p.Run(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
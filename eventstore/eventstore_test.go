@@ -0,0 +1,119 @@
+package eventstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/nullc4t/gorm-cruder/crud"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type orderEvent struct {
+	Placed    int  `json:"placed,omitempty"`
+	Cancelled bool `json:"cancelled,omitempty"`
+}
+
+func (e orderEvent) EventType() string {
+	if e.Cancelled {
+		return "cancelled"
+	}
+	return "placed"
+}
+
+type total struct {
+	ID      uint `gorm:"primarykey"`
+	OrderID string
+	Total   int
+}
+
+func (t total) PrimaryKey() any {
+	return t.ID
+}
+
+func TestStore(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:eventstore?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&total{}))
+	store := New[orderEvent](db)
+	require.NoError(t, store.Migrate(context.TODO()))
+
+	version, err := store.Append(context.TODO(), "order-1", NoStream, orderEvent{Placed: 100}, orderEvent{Placed: 50})
+	require.NoError(t, err)
+	require.Equal(t, 2, version)
+	_, err = store.Append(context.TODO(), "order-1", 1, orderEvent{Cancelled: true})
+	require.ErrorIs(t, err, ConcurrencyError)
+	_, err = store.Append(context.TODO(), "order-2", NoStream, orderEvent{Placed: 10})
+	require.NoError(t, err)
+	version, err = store.Append(context.TODO(), "order-1", AnyVersion, orderEvent{Cancelled: true})
+	require.NoError(t, err)
+	require.Equal(t, 3, version)
+
+	records, err := store.ReadStream(context.TODO(), "order-1", 1)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, orderEvent{Placed: 50}, records[0].Data)
+	require.Equal(t, "cancelled", records[1].Type)
+	require.Equal(t, 3, records[1].Version)
+
+	totals := crud.New[total](db)
+	fail := false
+	projection := NewProjection(store, "totals", func(ctx context.Context, r Record[orderEvent]) error {
+		if fail {
+			return errors.New("fail")
+		}
+		v, err := totals.QueryOne(ctx, total{OrderID: r.StreamID})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			v, err = totals.Create(ctx, total{OrderID: r.StreamID})
+		}
+		if err != nil {
+			return err
+		}
+		if r.Data.Cancelled {
+			return totals.UpdateField(ctx, *v, "total", 0)
+		}
+		return totals.UpdateField(ctx, *v, "total", v.Total+r.Data.Placed)
+	})
+	projection.BatchSize = 2
+	n, err := projection.Run(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	res, err := totals.SmartQuery(context.TODO(), crud.Query{OrderBy: []crud.OrderClause{{Column: "order_id", Direction: crud.ASC}}})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, 0, res[0].Total)
+	require.Equal(t, 10, res[1].Total)
+
+	_, err = store.Append(context.TODO(), "order-2", 1, orderEvent{Placed: 5})
+	require.NoError(t, err)
+	fail = true
+	_, err = projection.Run(context.TODO())
+	require.Error(t, err)
+	position, err := projection.Position(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), position)
+	fail = false
+	n, err = projection.Run(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	require.NoError(t, store.Migrate(context.TODO()))
+	_, err = store.Append(context.TODO(), "order-3", NoStream, orderEvent{Placed: 1})
+	require.NoError(t, err)
+	records, err = store.ReadAll(context.TODO(), 5, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, uint64(6), records[0].Position)
+	n, err = NewProjection(store, "totals", projection.handler).Run(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	n, err = projection.Run(context.TODO())
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	require.NoError(t, db.Migrator().DropTable(&sequence{}))
+	_, err = store.Append(context.TODO(), "order-3", AnyVersion, orderEvent{Placed: 1})
+	require.Error(t, err)
+}
@@ -0,0 +1,192 @@
+/*
+Package fixtures loads YAML/JSON fixtures into tables through crud.GenericCRUD.
+
+Fixture file maps model name to labeled rows; string values "$<model>.<label>"
+are replaced with primary key of referenced fixture, which is loaded first:
+
+	users:
+	  alice:
+	    name: Alice
+	orders:
+	  first:
+	    user_id: $users.alice
+	    total: 10
+*/
+package fixtures
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/nullc4t/gorm-cruder/crud"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+type (
+	// Rows are fixtures of single model by label
+	Rows map[string]map[string]any
+
+	// Loader inserts fixtures of registered models
+	Loader struct {
+		inserters map[string]inserter
+		rows      map[string]Rows
+		ids       map[string]any
+		loading   map[string]bool
+	}
+
+	inserter func(ctx context.Context, row map[string]any) (id any, err error)
+)
+
+var (
+	// UnknownReferenceError is returned when fixture references missing model or label
+	UnknownReferenceError = errors.New("unknown fixture reference")
+	// CycleError is returned when fixtures reference each other
+	CycleError = errors.New("fixture reference cycle")
+)
+
+// New is a constructor
+func New() *Loader {
+	return &Loader{
+		inserters: make(map[string]inserter),
+		rows:      make(map[string]Rows),
+		ids:       make(map[string]any),
+		loading:   make(map[string]bool),
+	}
+}
+
+// Register model under name used in fixture files
+func Register[T crud.GORMModel](l *Loader, name string, c crud.GenericCRUD[T]) {
+	l.inserters[name] = func(ctx context.Context, row map[string]any) (any, error) {
+		v, err := c.FromMap(row)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.Create(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		return (*res).PrimaryKey(), nil
+	}
+}
+
+// LoadFiles loads fixture files matching patterns in fsys; .json files are JSON, others YAML
+func (l *Loader) LoadFiles(ctx context.Context, fsys fs.FS, patterns ...string) error {
+	data := make(map[string]Rows)
+	for _, pattern := range patterns {
+		names, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			b, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return err
+			}
+			var file map[string]Rows
+			if path.Ext(name) == ".json" {
+				err = json.Unmarshal(b, &file)
+			} else {
+				err = yaml.Unmarshal(b, &file)
+			}
+			if err != nil {
+				return fmt.Errorf("fixture %s: %w", name, err)
+			}
+			for model, rows := range file {
+				if data[model] == nil {
+					data[model] = make(Rows)
+				}
+				for label, row := range rows {
+					data[model][label] = row
+				}
+			}
+		}
+	}
+	return l.Load(ctx, data)
+}
+
+// Load inserts fixtures by model name and label
+func (l *Loader) Load(ctx context.Context, data map[string]Rows) error {
+	for model, rows := range data {
+		if _, ok := l.inserters[model]; !ok {
+			return fmt.Errorf("%w: model %q is not registered", UnknownReferenceError, model)
+		}
+		if l.rows[model] == nil {
+			l.rows[model] = make(Rows)
+		}
+		for label, row := range rows {
+			l.rows[model][label] = row
+		}
+	}
+	for model, rows := range data {
+		for label := range rows {
+			if _, err := l.load(ctx, model+"."+label); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ID returns primary key of loaded fixture referenced as "<model>.<label>"
+func (l *Loader) ID(ref string) (any, bool) {
+	id, ok := l.ids[ref]
+	return id, ok
+}
+
+// load inserts fixture ref after its references and returns its primary key
+func (l *Loader) load(ctx context.Context, ref string) (any, error) {
+	if id, ok := l.ids[ref]; ok {
+		return id, nil
+	}
+	if l.loading[ref] {
+		return nil, fmt.Errorf("%w: %s", CycleError, ref)
+	}
+	model, label, _ := strings.Cut(ref, ".")
+	row, ok := l.rows[model][label]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", UnknownReferenceError, ref)
+	}
+	l.loading[ref] = true
+	defer delete(l.loading, ref)
+	resolved := make(map[string]any, len(row))
+	for k, v := range row {
+		if s, ok := v.(string); ok && strings.HasPrefix(s, "$") {
+			id, err := l.load(ctx, s[1:])
+			if err != nil {
+				return nil, err
+			}
+			v = id
+		}
+		resolved[k] = v
+	}
+	id, err := l.inserters[model](ctx, resolved)
+	if err != nil {
+		return nil, fmt.Errorf("fixture %s: %w", ref, err)
+	}
+	l.ids[ref] = id
+	return id, nil
+}
+
+// Truncate removes all rows from tables; on Postgres identities are reset and dependent tables truncated
+func Truncate(ctx context.Context, db *gorm.DB, tables ...string) error {
+	db = db.WithContext(ctx)
+	if crud.DialectOf(db) == crud.Postgres {
+		quoted := make([]string, len(tables))
+		for i, t := range tables {
+			quoted[i] = db.Statement.Quote(t)
+		}
+		return db.Exec("TRUNCATE " + strings.Join(quoted, ", ") + " RESTART IDENTITY CASCADE").Error
+	}
+	for _, t := range tables {
+		if err := db.Exec("DELETE FROM " + db.Statement.Quote(t)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package fixtures
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	var inserted []string
+	l := New()
+	fake := func(model string) inserter {
+		return func(_ context.Context, row map[string]any) (any, error) {
+			inserted = append(inserted, model)
+			if model == "orders" {
+				require.Equal(t, 1, row["user_id"])
+			}
+			return len(inserted), nil
+		}
+	}
+	l.inserters["users"], l.inserters["orders"] = fake("users"), fake("orders")
+
+	require.NoError(t, l.LoadFiles(context.TODO(), fstest.MapFS{
+		"orders.yml": {Data: []byte("orders:\n  first:\n    user_id: $users.alice\n")},
+		"users.json": {Data: []byte(`{"users": {"alice": {"name": "Alice"}}}`)},
+	}, "*.yml", "*.json"))
+	require.Equal(t, []string{"users", "orders"}, inserted)
+	id, ok := l.ID("orders.first")
+	require.True(t, ok)
+	require.Equal(t, 2, id)
+
+	err := l.Load(context.TODO(), map[string]Rows{"orders": {"second": {"user_id": "$users.bob"}}})
+	require.ErrorIs(t, err, UnknownReferenceError)
+	err = l.Load(context.TODO(), map[string]Rows{"users": {
+		"a": {"friend": "$users.b"},
+		"b": {"friend": "$users.a"},
+	}})
+	require.ErrorIs(t, err, CycleError)
+}
@@ -0,0 +1,267 @@
+/*
+Package migrations is a lightweight versioned migrations runner on top of *gorm.DB.
+
+Migrations are numbered SQL files, usually embedded:
+
+	//go:embed sql/*.sql
+	var files embed.FS
+
+	sql/0001_create_users.up.sql
+	sql/0001_create_users.down.sql
+
+Applied versions are recorded in schema_migrations table. Up and Down hold a session lock while running, so
+concurrent runners, e.g. replicas starting at once, apply each migration once: pg_advisory_lock on Postgres and
+GET_LOCK on MySQL. Other databases have no such lock; there a concurrent runner fails on the primary key of
+schema_migrations instead of recording migration twice.
+*/
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type (
+	// Migration is a pair of up/down SQL scripts
+	Migration struct {
+		Version  uint64
+		Name     string
+		Up, Down string
+	}
+
+	// Status of a migration
+	Status struct {
+		Migration
+		Applied   bool
+		AppliedAt time.Time
+	}
+
+	// Runner applies migrations
+	Runner struct {
+		db         *gorm.DB
+		migrations []Migration
+	}
+
+	schemaMigration struct {
+		Version   uint64 `gorm:"primarykey;autoIncrement:false"`
+		Name      string
+		AppliedAt time.Time
+	}
+)
+
+var (
+	// NoDownError is returned by Runner.Down when migration has no down script
+	NoDownError = errors.New("migration has no down script")
+)
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// New is a constructor; reads <version>_<name>.(up|down).sql files from dir of fsys
+func New(db *gorm.DB, fsys fs.FS, dir string) (*Runner, error) {
+	migrations, err := Load(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{db: db, migrations: migrations}, nil
+}
+
+// Load reads migrations from dir of fsys sorted by version
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[uint64]*Migration)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		base := strings.TrimSuffix(name, ".sql")
+		base, direction, ok := cutLast(base, ".")
+		if !ok || direction != "up" && direction != "down" {
+			return nil, fmt.Errorf("migration %s: expected .up.sql or .down.sql suffix", name)
+		}
+		v, title, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: bad version: %w", name, err)
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: title}
+			byVersion[version] = m
+		} else if m.Name != title {
+			return nil, fmt.Errorf("migration %d: conflicting names %q and %q", version, m.Name, title)
+		}
+		if direction == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+	res := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d: missing up script", m.Version)
+		}
+		res = append(res, *m)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+	return res, nil
+}
+
+// Add registers migrations generated in code, e.g. by crud.GenericCRUD.WatchMigration, besides ones of files
+func (r *Runner) Add(migrations ...Migration) error {
+	for _, m := range migrations {
+		if m.Up == "" {
+			return fmt.Errorf("migration %d: missing up script", m.Version)
+		}
+		for _, existing := range r.migrations {
+			if existing.Version == m.Version {
+				return fmt.Errorf("migration %d: conflicting names %q and %q", m.Version, existing.Name, m.Name)
+			}
+		}
+		r.migrations = append(r.migrations, m)
+	}
+	sort.Slice(r.migrations, func(i, j int) bool { return r.migrations[i].Version < r.migrations[j].Version })
+	return nil
+}
+
+// Status returns all known migrations with applied state
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	applied, err := r.applied(r.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Status, len(r.migrations))
+	for i, m := range r.migrations {
+		res[i].Migration = m
+		if a, ok := applied[m.Version]; ok {
+			res[i].Applied, res[i].AppliedAt = true, a.AppliedAt
+		}
+	}
+	return res, nil
+}
+
+// Up applies all pending migrations in version order, each in its own transaction
+func (r *Runner) Up(ctx context.Context) error {
+	return r.locked(ctx, func(db *gorm.DB) error {
+		applied, err := r.applied(db)
+		if err != nil {
+			return err
+		}
+		for _, m := range r.migrations {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			err = db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(m.Up).Error; err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d %s up: %w", m.Version, m.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Down reverts steps latest applied migrations
+func (r *Runner) Down(ctx context.Context, steps int) error {
+	return r.locked(ctx, func(db *gorm.DB) error {
+		applied, err := r.applied(db)
+		if err != nil {
+			return err
+		}
+		for i := len(r.migrations) - 1; i >= 0 && steps > 0; i-- {
+			m := r.migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			if m.Down == "" {
+				return fmt.Errorf("migration %d %s: %w", m.Version, m.Name, NoDownError)
+			}
+			err = db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(m.Down).Error; err != nil {
+					return err
+				}
+				return tx.Delete(&schemaMigration{Version: m.Version}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d %s down: %w", m.Version, m.Name, err)
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// lockID identifies advisory lock of schema_migrations
+const lockID = 8317245019
+
+// locked calls fc with db bound to single connection holding migrations lock if database supports it
+func (r *Runner) locked(ctx context.Context, fc func(db *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		lock, unlock := lockSQL(conn.Dialector.Name())
+		if lock != "" {
+			if err := conn.Exec(lock, lockID).Error; err != nil {
+				return fmt.Errorf("lock migrations: %w", err)
+			}
+			// release even if ctx is done, lock would outlive the call on pooled connection otherwise
+			defer conn.WithContext(context.Background()).Exec(unlock, lockID)
+		}
+		return fc(conn)
+	})
+}
+
+// lockSQL returns statements acquiring and releasing session lock, empty for unsupported dialect
+func lockSQL(dialect string) (lock, unlock string) {
+	switch dialect {
+	case "postgres":
+		return "SELECT pg_advisory_lock(?)", "SELECT pg_advisory_unlock(?)"
+	case "mysql":
+		return "SELECT GET_LOCK(CONCAT('schema_migrations_', ?), -1)", "SELECT RELEASE_LOCK(CONCAT('schema_migrations_', ?))"
+	}
+	return "", ""
+}
+
+// applied ensures schema_migrations table exists and returns applied migrations by version
+func (r *Runner) applied(db *gorm.DB) (map[uint64]schemaMigration, error) {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, err
+	}
+	var rows []schemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	res := make(map[uint64]schemaMigration, len(rows))
+	for _, row := range rows {
+		res[row.Version] = row
+	}
+	return res, nil
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
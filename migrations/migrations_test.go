@@ -0,0 +1,98 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0002_add_age.up.sql":        {Data: []byte("ALTER TABLE users ADD age int;")},
+		"sql/0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id serial);")},
+		"sql/0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"sql/README.md":                  {Data: []byte("ignored")},
+	}
+	migrations, err := Load(fsys, "sql")
+	require.NoError(t, err)
+	require.Equal(t, []Migration{
+		{Version: 1, Name: "create_users", Up: "CREATE TABLE users (id serial);", Down: "DROP TABLE users;"},
+		{Version: 2, Name: "add_age", Up: "ALTER TABLE users ADD age int;"},
+	}, migrations)
+
+	for _, bad := range []fstest.MapFS{
+		{"sql/x_create.up.sql": {}},
+		{"sql/0001_create.sql": {}},
+		{"sql/0001_create.down.sql": {Data: []byte("DROP TABLE users;")}},
+	} {
+		_, err = Load(bad, "sql")
+		require.Error(t, err)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	r := &Runner{migrations: []Migration{{Version: 1, Name: "create_users", Up: "CREATE TABLE users (id serial);"}}}
+	require.NoError(t, r.Add(Migration{Version: 3, Name: "c", Up: "SELECT 3"}, Migration{Version: 2, Name: "b", Up: "SELECT 2"}))
+	versions := make([]uint64, len(r.migrations))
+	for i, m := range r.migrations {
+		versions[i] = m.Version
+	}
+	require.Equal(t, []uint64{1, 2, 3}, versions)
+	require.Error(t, r.Add(Migration{Version: 2, Name: "again", Up: "SELECT 2"}))
+	require.Error(t, r.Add(Migration{Version: 4, Name: "empty"}))
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.TODO()
+	db, err := gorm.Open(sqlite.Open("file:migrations?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	r, err := New(db, fstest.MapFS{
+		"sql/0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id integer primary key, name text);")},
+		"sql/0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"sql/0002_add_age.up.sql":        {Data: []byte("ALTER TABLE users ADD age integer;")},
+		"sql/0002_add_age.down.sql":      {Data: []byte("ALTER TABLE users DROP COLUMN age;")},
+	}, "sql")
+	require.NoError(t, err)
+	require.NoError(t, r.Add(Migration{Version: 3, Name: "seed", Up: "INSERT INTO users (name, age) VALUES ('ann', 30);"}))
+	applied := func() []bool {
+		status, err := r.Status(ctx)
+		require.NoError(t, err)
+		res := make([]bool, len(status))
+		for i, s := range status {
+			res[i] = s.Applied
+		}
+		return res
+	}
+	require.Equal(t, []bool{false, false, false}, applied())
+
+	require.NoError(t, r.Up(ctx))
+	require.NoError(t, r.Up(ctx))
+	require.Equal(t, []bool{true, true, true}, applied())
+	var count int64
+	require.NoError(t, db.Table("users").Where("age = ?", 30).Count(&count).Error)
+	require.EqualValues(t, 1, count)
+
+	require.ErrorIs(t, r.Down(ctx, 1), NoDownError)
+	require.NoError(t, db.Delete(&schemaMigration{Version: 3}).Error)
+	require.NoError(t, r.Down(ctx, 1))
+	require.Equal(t, []bool{true, false, false}, applied())
+	require.False(t, db.Migrator().HasColumn("users", "age"))
+	require.NoError(t, r.Down(ctx, 5))
+	require.Equal(t, []bool{false, false, false}, applied())
+	require.False(t, db.Migrator().HasTable("users"))
+
+	bad := &Runner{db: db, migrations: []Migration{
+		{Version: 1, Name: "create_users", Up: "CREATE TABLE users (id integer primary key);"},
+		{Version: 2, Name: "broken", Up: "ALTER TABLE nope ADD x integer;"},
+	}}
+	require.ErrorContains(t, bad.Up(ctx), "migration 2 broken up")
+	status, err := bad.Status(ctx)
+	require.NoError(t, err)
+	require.True(t, status[0].Applied)
+	require.False(t, status[1].Applied)
+}